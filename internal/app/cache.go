@@ -0,0 +1,154 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCacheMiss is returned by Cache.Get when a key is not cached
+var ErrCacheMiss = errors.New("cache: miss")
+
+// Cache stores byte values by key with a time to live. Services use it
+// instead of a RedisClient so the backend is chosen by cache.driver.
+type Cache interface {
+	// Get returns the cached value, or ErrCacheMiss
+	Get(key string) ([]byte, error)
+	// Set stores a value; a ttl of zero uses cache.default_ttl
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes keys
+	Delete(keys ...string) error
+	// DeleteByPrefix removes every key starting with prefix
+	DeleteByPrefix(prefix string) error
+	// GetOrLoad returns the cached value, or calls load and caches its result
+	GetOrLoad(key string, ttl time.Duration, load func() ([]byte, error)) ([]byte, error)
+	// Stats returns the counters of the cache
+	Stats() CacheStats
+}
+
+// CacheStats holds the counters of a cache
+type CacheStats struct {
+	Backend   string       `json:"backend"`
+	Hits      int64        `json:"hits"`
+	Misses    int64        `json:"misses"`
+	Evictions int64        `json:"evictions"`
+	Errors    int64        `json:"errors"`
+	Entries   int64        `json:"entries,omitempty"` // in-memory only
+	Bytes     int64        `json:"bytes,omitempty"`   // in-memory only
+	Tiers     []CacheStats `json:"tiers,omitempty"`   // two_tier only
+}
+
+// CacheFailure describes a failed cache operation. It is the payload of
+// the system.cache_error event.
+type CacheFailure struct {
+	Backend   string `json:"backend"`
+	Operation string `json:"operation"`
+	Key       string `json:"key,omitempty"`
+	Error     string `json:"error"`
+}
+
+// AppCache is the global cache
+var AppCache Cache
+
+var (
+	cacheErrorMutex sync.RWMutex
+	cacheErrorHooks []func(CacheFailure)
+)
+
+// InitCache initializes the cache selected in the configuration. A Redis
+// backed cache falls back to memory when Redis is unavailable.
+func InitCache() {
+	cfg := GetConfig().Cache
+	cache, err := NewCache(cfg, Redis)
+	if errors.Is(err, ErrRedisDisabled) {
+		Warn("Redis is unavailable, falling back to the in-memory cache", "driver", cfg.Driver)
+		cfg.Driver = "memory"
+		cache, err = NewCache(cfg, nil)
+	}
+	if err != nil {
+		fmt.Printf("Failed to initialize cache: %v\n", err)
+		panic(err)
+	}
+
+	AppCache = cache
+	Info("Cache initialized successfully", "driver", cfg.Driver)
+}
+
+// NewCache creates the Cache for the configured driver
+func NewCache(cfg CacheConfig, redis *RedisClient) (Cache, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryCache(cfg), nil
+	case "redis":
+		if !redis.IsEnabled() {
+			return nil, ErrRedisDisabled
+		}
+		return NewRedisCache(cfg, redis), nil
+	case "two_tier":
+		if !redis.IsEnabled() {
+			return nil, ErrRedisDisabled
+		}
+		return NewTieredCache(cfg, NewMemoryCache(cfg), NewRedisCache(cfg, redis)), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache driver: %s", cfg.Driver)
+	}
+}
+
+// CacheStatus returns the counters of the global cache, or nil without one
+func CacheStatus() *CacheStats {
+	if AppCache == nil {
+		return nil
+	}
+	stats := AppCache.Stats()
+	return &stats
+}
+
+// OnCacheError registers a function called after a cache operation failed
+func OnCacheError(hook func(CacheFailure)) {
+	cacheErrorMutex.Lock()
+	defer cacheErrorMutex.Unlock()
+
+	cacheErrorHooks = append(cacheErrorHooks, hook)
+}
+
+// reportCacheError passes a failed operation to the OnCacheError hooks
+func reportCacheError(backend, operation, key string, err error) {
+	cacheErrorMutex.RLock()
+	hooks := cacheErrorHooks
+	cacheErrorMutex.RUnlock()
+
+	failure := CacheFailure{Backend: backend, Operation: operation, Key: key, Error: err.Error()}
+	for _, hook := range hooks {
+		hook(failure)
+	}
+}
+
+// JitterTTL spreads a TTL by up to ±jitter of its length, so entries
+// cached together do not all expire together
+func JitterTTL(ttl time.Duration, jitter float64) time.Duration {
+	if ttl <= 0 || jitter <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration((rand.Float64()*2-1)*jitter*float64(ttl))
+}
+
+// cacheGetOrLoad implements GetOrLoad on top of Get and Set. Concurrent
+// misses for a key share one load. A failing cache never fails the call:
+// the value is loaded instead, and a value that cannot be stored is still
+// returned.
+func cacheGetOrLoad(cache Cache, flights *SingleFlight, key string, ttl time.Duration, load func() ([]byte, error)) ([]byte, error) {
+	if value, err := cache.Get(key); err == nil {
+		return value, nil
+	}
+
+	return flights.Do(key, func() ([]byte, error) {
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		cache.Set(key, value, ttl)
+		return value, nil
+	})
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"container/list"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// memoryEntryOverhead approximates the bookkeeping cost of an entry beyond
+// its key and value, so many tiny entries still count against the limit
+const memoryEntryOverhead = 96
+
+// MemoryCache is an in-process LRU cache with TTLs, split into shards that
+// are locked independently. Each shard holds an equal part of the entry
+// and memory limits and evicts its least recently used entries beyond them.
+type MemoryCache struct {
+	shards     []*memoryShard
+	defaultTTL time.Duration
+	flights    SingleFlight
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// memoryShard is one independently locked LRU list
+type memoryShard struct {
+	mutex      sync.Mutex
+	items      map[string]*list.Element
+	lru        *list.List // front is most recently used
+	bytes      int64
+	maxEntries int
+	maxBytes   int64
+}
+
+// memoryEntry is one cached value
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an empty MemoryCache
+func NewMemoryCache(cfg CacheConfig) *MemoryCache {
+	shardCount := cfg.Shards
+	if shardCount < 1 {
+		shardCount = 1
+	}
+
+	c := &MemoryCache{
+		shards:     make([]*memoryShard, shardCount),
+		defaultTTL: cfg.DefaultTTL.Duration(),
+	}
+	for i := range c.shards {
+		shard := &memoryShard{
+			items: make(map[string]*list.Element),
+			lru:   list.New(),
+		}
+		if cfg.MaxEntries > 0 {
+			shard.maxEntries = (cfg.MaxEntries + shardCount - 1) / shardCount
+		}
+		if cfg.MaxMemoryMB > 0 {
+			shard.maxBytes = int64(cfg.MaxMemoryMB) * 1024 * 1024 / int64(shardCount)
+		}
+		c.shards[i] = shard
+	}
+	return c
+}
+
+// shard returns the shard holding key
+func (c *MemoryCache) shard(key string) *memoryShard {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return c.shards[hash.Sum32()%uint32(len(c.shards))]
+}
+
+// Get returns a live value and marks it as recently used
+func (c *MemoryCache) Get(key string) ([]byte, error) {
+	shard := c.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	element, ok := shard.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, ErrCacheMiss
+	}
+	entry := element.Value.(*memoryEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		shard.remove(element)
+		c.misses.Add(1)
+		return nil, ErrCacheMiss
+	}
+
+	shard.lru.MoveToFront(element)
+	c.hits.Add(1)
+	return entry.value, nil
+}
+
+// Set stores a value, evicting the least recently used entries as needed.
+// A value larger than a whole shard is not cached.
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	entry := &memoryEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+
+	shard := c.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	if element, ok := shard.items[key]; ok {
+		shard.remove(element)
+	}
+	if shard.maxBytes > 0 && entry.size() > shard.maxBytes {
+		return nil
+	}
+	shard.items[key] = shard.lru.PushFront(entry)
+	shard.bytes += entry.size()
+
+	for shard.overLimit() {
+		shard.remove(shard.lru.Back())
+		c.evictions.Add(1)
+	}
+	return nil
+}
+
+// Delete removes keys
+func (c *MemoryCache) Delete(keys ...string) error {
+	for _, key := range keys {
+		shard := c.shard(key)
+		shard.mutex.Lock()
+		if element, ok := shard.items[key]; ok {
+			shard.remove(element)
+		}
+		shard.mutex.Unlock()
+	}
+	return nil
+}
+
+// DeleteByPrefix removes every key starting with prefix
+func (c *MemoryCache) DeleteByPrefix(prefix string) error {
+	for _, shard := range c.shards {
+		shard.mutex.Lock()
+		for key, element := range shard.items {
+			if strings.HasPrefix(key, prefix) {
+				shard.remove(element)
+			}
+		}
+		shard.mutex.Unlock()
+	}
+	return nil
+}
+
+// GetOrLoad returns the cached value, or loads and caches it
+func (c *MemoryCache) GetOrLoad(key string, ttl time.Duration, load func() ([]byte, error)) ([]byte, error) {
+	return cacheGetOrLoad(c, &c.flights, key, ttl, load)
+}
+
+// Stats returns the counters and current size of the cache
+func (c *MemoryCache) Stats() CacheStats {
+	stats := CacheStats{
+		Backend:   "memory",
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+	for _, shard := range c.shards {
+		shard.mutex.Lock()
+		stats.Entries += int64(len(shard.items))
+		stats.Bytes += shard.bytes
+		shard.mutex.Unlock()
+	}
+	return stats
+}
+
+// remove drops an element from the shard. The caller holds the mutex.
+func (s *memoryShard) remove(element *list.Element) {
+	entry := s.lru.Remove(element).(*memoryEntry)
+	delete(s.items, entry.key)
+	s.bytes -= entry.size()
+}
+
+// overLimit reports whether the shard holds too many entries or bytes
+func (s *memoryShard) overLimit() bool {
+	return (s.maxEntries > 0 && len(s.items) > s.maxEntries) ||
+		(s.maxBytes > 0 && s.bytes > s.maxBytes)
+}
+
+// size approximates the memory used by the entry
+func (e *memoryEntry) size() int64 {
+	return int64(len(e.key) + len(e.value) + memoryEntryOverhead)
+}
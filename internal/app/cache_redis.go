@@ -0,0 +1,117 @@
+package app
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// redisScanCount is the page size used when deleting keys by prefix
+const redisScanCount = 500
+
+// RedisCache is a Cache stored in Redis, shared by every instance. Keys are
+// prefixed with cache.prefix. Failures are counted and reported through
+// OnCacheError.
+type RedisCache struct {
+	client     *RedisClient
+	prefix     string
+	defaultTTL time.Duration
+	flights    SingleFlight
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// NewRedisCache creates a RedisCache using the given client
+func NewRedisCache(cfg CacheConfig, client *RedisClient) *RedisCache {
+	return &RedisCache{
+		client:     client,
+		prefix:     cfg.Prefix,
+		defaultTTL: cfg.DefaultTTL.Duration(),
+	}
+}
+
+// Get returns the cached value
+func (c *RedisCache) Get(key string) ([]byte, error) {
+	value, err := c.client.Get(c.prefix + key)
+	if errors.Is(err, ErrRedisNil) {
+		c.misses.Add(1)
+		return nil, ErrCacheMiss
+	}
+	if err != nil {
+		c.misses.Add(1)
+		return nil, c.fail("get", key, err)
+	}
+	c.hits.Add(1)
+	return []byte(value), nil
+}
+
+// Set stores a value
+func (c *RedisCache) Set(key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	if err := c.client.Set(c.prefix+key, string(value), ttl); err != nil {
+		return c.fail("set", key, err)
+	}
+	return nil
+}
+
+// Delete removes keys
+func (c *RedisCache) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	if err := c.client.Delete(prefixed...); err != nil {
+		return c.fail("delete", keys[0], err)
+	}
+	return nil
+}
+
+// DeleteByPrefix scans for the keys starting with prefix and removes them
+func (c *RedisCache) DeleteByPrefix(prefix string) error {
+	match := RedisGlobEscape(c.prefix+prefix) + "*"
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(cursor, match, redisScanCount)
+		if err != nil {
+			return c.fail("delete_prefix", prefix, err)
+		}
+		if len(keys) > 0 {
+			if err := c.client.Delete(keys...); err != nil {
+				return c.fail("delete_prefix", prefix, err)
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// GetOrLoad returns the cached value, or loads and caches it
+func (c *RedisCache) GetOrLoad(key string, ttl time.Duration, load func() ([]byte, error)) ([]byte, error) {
+	return cacheGetOrLoad(c, &c.flights, key, ttl, load)
+}
+
+// Stats returns the counters of the cache
+func (c *RedisCache) Stats() CacheStats {
+	return CacheStats{
+		Backend: "redis",
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Errors:  c.errors.Load(),
+	}
+}
+
+// fail counts and reports a failed operation and returns its error
+func (c *RedisCache) fail(operation, key string, err error) error {
+	c.errors.Add(1)
+	reportCacheError("redis", operation, key, err)
+	return err
+}
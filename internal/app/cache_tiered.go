@@ -0,0 +1,90 @@
+package app
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// TieredCache puts an in-process cache in front of a shared one. Values
+// read from or written to the shared cache are kept locally for at most
+// cache.local_ttl, which bounds how long an instance can serve a value
+// that another instance has since changed.
+type TieredCache struct {
+	local    Cache
+	shared   Cache
+	localTTL time.Duration
+	flights  SingleFlight
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewTieredCache creates a TieredCache of a local and a shared cache
+func NewTieredCache(cfg CacheConfig, local, shared Cache) *TieredCache {
+	return &TieredCache{
+		local:    local,
+		shared:   shared,
+		localTTL: cfg.LocalTTL.Duration(),
+	}
+}
+
+// Get returns the local copy, or the shared value which is then kept locally
+func (c *TieredCache) Get(key string) ([]byte, error) {
+	if value, err := c.local.Get(key); err == nil {
+		c.hits.Add(1)
+		return value, nil
+	}
+
+	value, err := c.shared.Get(key)
+	if err != nil {
+		c.misses.Add(1)
+		return nil, err
+	}
+	c.local.Set(key, value, c.localTTL)
+	c.hits.Add(1)
+	return value, nil
+}
+
+// Set stores a value in both tiers. The local copy is kept even if the
+// shared cache failed.
+func (c *TieredCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.local.Set(key, value, c.localLifetime(ttl))
+	return c.shared.Set(key, value, ttl)
+}
+
+// Delete removes keys from both tiers
+func (c *TieredCache) Delete(keys ...string) error {
+	return errors.Join(c.local.Delete(keys...), c.shared.Delete(keys...))
+}
+
+// DeleteByPrefix removes every key starting with prefix from both tiers
+func (c *TieredCache) DeleteByPrefix(prefix string) error {
+	return errors.Join(c.local.DeleteByPrefix(prefix), c.shared.DeleteByPrefix(prefix))
+}
+
+// GetOrLoad returns the cached value, or loads and caches it
+func (c *TieredCache) GetOrLoad(key string, ttl time.Duration, load func() ([]byte, error)) ([]byte, error) {
+	return cacheGetOrLoad(c, &c.flights, key, ttl, load)
+}
+
+// Stats returns the overall counters with those of each tier
+func (c *TieredCache) Stats() CacheStats {
+	local, shared := c.local.Stats(), c.shared.Stats()
+	return CacheStats{
+		Backend:   "two_tier",
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: local.Evictions + shared.Evictions,
+		Errors:    local.Errors + shared.Errors,
+		Tiers:     []CacheStats{local, shared},
+	}
+}
+
+// localLifetime caps a TTL at local_ttl
+func (c *TieredCache) localLifetime(ttl time.Duration) time.Duration {
+	if ttl <= 0 || ttl > c.localTTL {
+		return c.localTTL
+	}
+	return ttl
+}
@@ -1,97 +1,374 @@
 package app
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
+	"sync/atomic"
+	"time"
 )
 
 // ServerConfig contains server configuration
 type ServerConfig struct {
-	Port int    `json:"port"`
-	Mode string `json:"mode"`
+	Port        int      `json:"port" reload:"restart"`
+	Mode        string   `json:"mode" reload:"restart"`
+	CORSOrigins []string `json:"cors_origins"` // allowed origins, "*" allows any
 }
 
 // LogConfig contains logging configuration
 type LogConfig struct {
-	Filename   string `json:"filename"`
+	Filename   string `json:"filename" reload:"restart"`
 	Level      string `json:"level"`
-	MaxSize    int    `json:"max_size"`
-	MaxBackups int    `json:"max_backups"`
-	MaxAge     int    `json:"max_age"`
-	Compress   bool   `json:"compress"`
+	Format     string `json:"format" reload:"restart"`
+	Output     string `json:"output" reload:"restart"`
+	MaxSize    int    `json:"max_size" reload:"restart"`
+	MaxBackups int    `json:"max_backups" reload:"restart"`
+	MaxAge     int    `json:"max_age" reload:"restart"`
+	Compress   bool   `json:"compress" reload:"restart"`
 }
 
 // DatabaseConfig contains database configuration
 type DatabaseConfig struct {
+	Driver   string `json:"driver"` // mysql, postgres or sqlite
 	Host     string `json:"host"`
-	Port     int    `json:"port"`
+	Port     int    `json:"port"` // 0 uses the driver's default port
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Password string `json:"password" secret:"true"`
 	DBName   string `json:"db_name"`
+	Path     string `json:"path"` // sqlite database file, or :memory:
+
+	TLS            string            `json:"tls"`      // true, false, skip-verify or preferred
+	Timezone       string            `json:"timezone"` // location of time values, e.g. Local or UTC
+	ConnectTimeout Duration          `json:"connect_timeout"`
+	ReadTimeout    Duration          `json:"read_timeout"`
+	WriteTimeout   Duration          `json:"write_timeout"`
+	Params         map[string]string `json:"params"` // extra DSN parameters
+
+	MaxOpenConns    int      `json:"max_open_conns"`
+	MaxIdleConns    int      `json:"max_idle_conns"`
+	ConnMaxLifetime Duration `json:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `json:"conn_max_idle_time"`
+
+	LogLevel      string   `json:"log_level"`      // silent, error, warn or info (every query)
+	SlowThreshold Duration `json:"slow_threshold"` // queries slower than this are logged at warn
+
+	ConnectRetries  int      `json:"connect_retries"` // further attempts after a failed connect
+	RetryBackoff    Duration `json:"retry_backoff"`   // doubled after every failed attempt
+	MaxRetryBackoff Duration `json:"max_retry_backoff"`
+
+	AutoMigrate bool `json:"auto_migrate"` // apply pending migrations on start
+
+	Replicas              []ReplicaConfig `json:"replicas"`
+	ReplicaPolicy         string          `json:"replica_policy"` // random, round_robin or least_conn
+	ReplicaHealthInterval Duration        `json:"replica_health_interval"`
+	ReadYourWritesWindow  Duration        `json:"read_your_writes_window"` // a client reads the primary this long after a write
+}
+
+// ReplicaConfig is a read replica; unset fields use the primary's values
+type ReplicaConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password" secret:"true"`
+	Path     string `json:"path"` // sqlite only
 }
 
 // RedisConfig contains Redis configuration
 type RedisConfig struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
-	Password string `json:"password"`
+	Username string `json:"username"` // ACL user, empty for the default user
+	Password string `json:"password" secret:"true"`
 	DB       int    `json:"db"`
+	Protocol int    `json:"protocol"` // 2 for RESP2, 3 for RESP3 via HELLO
+
+	PoolSize     int      `json:"pool_size"`    // maximum open connections
+	PoolTimeout  Duration `json:"pool_timeout"` // wait for a free connection
+	IdleTimeout  Duration `json:"idle_timeout"` // idle connections older than this are closed
+	DialTimeout  Duration `json:"dial_timeout"`
+	ReadTimeout  Duration `json:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout"`
+	MaxRetries   int      `json:"max_retries"` // reconnect attempts for commands that did not reach the server
+}
+
+// JWTConfig contains access token signing configuration
+type JWTConfig struct {
+	Algorithm       string   `json:"algorithm"`            // HS256 or RS256
+	Secret          string   `json:"secret" secret:"true"` // HS256 shared secret
+	PrivateKeyFile  string   `json:"private_key_file"`
+	PublicKeyFile   string   `json:"public_key_file"`
+	Issuer          string   `json:"issuer"`
+	Audience        string   `json:"audience"`
+	AccessTokenTTL  Duration `json:"access_token_ttl"`
+	RefreshTokenTTL Duration `json:"refresh_token_ttl"`
+}
+
+// PasswordPolicyConfig contains the rules new passwords must satisfy
+type PasswordPolicyConfig struct {
+	MinLength     int  `json:"min_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	HistorySize   int  `json:"history_size"` // number of previous passwords that may not be reused
+}
+
+// PasswordResetConfig contains forgot-password flow configuration
+type PasswordResetConfig struct {
+	TokenTTL           Duration `json:"token_ttl"`
+	MaxRequestsPerHour int      `json:"max_requests_per_hour"` // per email address
+	ResetURL           string   `json:"reset_url"`             // link sent to users, the token is appended
+}
+
+// EmailVerificationConfig contains email verification configuration
+type EmailVerificationConfig struct {
+	Required          bool     `json:"required"` // refuse login until the address is verified
+	TokenTTL          Duration `json:"token_ttl"`
+	MaxResendsPerHour int      `json:"max_resends_per_hour"` // per email address
+	VerifyURL         string   `json:"verify_url"`           // link sent to users, the token is appended
+}
+
+// LoginProtectionConfig contains brute-force protection settings for login
+type LoginProtectionConfig struct {
+	Store            string   `json:"store" reload:"restart"` // memory or redis
+	AccountThreshold int      `json:"account_threshold"`      // failures per login before it is locked
+	IPThreshold      int      `json:"ip_threshold"`           // failures per client IP before it is locked
+	BaseLockout      Duration `json:"base_lockout"`           // doubled for each further failure
+	MaxLockout       Duration `json:"max_lockout"`
+	FailureWindow    Duration `json:"failure_window"` // how long failures are remembered after the last one
+}
+
+// MFAConfig contains two-factor authentication configuration
+type MFAConfig struct {
+	Issuer        string   `json:"issuer"`         // shown by authenticator apps
+	ChallengeTTL  Duration `json:"challenge_ttl"`  // time allowed to complete the second login step
+	MaxAttempts   int      `json:"max_attempts"`   // code attempts per user per challenge_ttl
+	RecoveryCodes int      `json:"recovery_codes"` // number of recovery codes issued on enrollment
+}
+
+// OIDCProviderConfig describes an external OpenID Connect identity provider
+type OIDCProviderConfig struct {
+	Name         string   `json:"name"`   // used in login URLs and stored with linked identities
+	Issuer       string   `json:"issuer"` // endpoints are discovered from the issuer unless set below
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret" secret:"true"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"`
+	AuthURL      string   `json:"auth_url"`
+	TokenURL     string   `json:"token_url"`
+	JWKSURL      string   `json:"jwks_url"`
+	AllowSignup  bool     `json:"allow_signup"` // create accounts for unknown identities
+}
+
+// OIDCConfig contains single sign-on configuration
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig `json:"providers" reload:"restart"`
+	StateTTL  Duration             `json:"state_ttl"` // time allowed to complete a login at the provider
+}
+
+// SecretsConfig contains the encrypted secrets file settings
+type SecretsConfig struct {
+	File      string `json:"file"`                     // referenced from config values as secret:<name>
+	MasterKey string `json:"master_key" secret:"true"` // usually set through GOAPP_SECRETS_MASTER_KEY
+}
+
+// CacheConfig contains the application cache configuration
+type CacheConfig struct {
+	Driver      string   `json:"driver"`        // memory, redis or two_tier
+	Prefix      string   `json:"prefix"`        // prepended to every Redis key
+	DefaultTTL  Duration `json:"default_ttl"`   // used when a caller passes no TTL
+	TTLJitter   float64  `json:"ttl_jitter"`    // fraction by which service TTLs are spread
+	Shards      int      `json:"shards"`        // in-memory LRU shards
+	MaxEntries  int      `json:"max_entries"`   // in-memory entry limit, 0 for none
+	MaxMemoryMB int      `json:"max_memory_mb"` // in-memory size limit, 0 for none
+	LocalTTL    Duration `json:"local_ttl"`     // two_tier: longest time a local copy is served
+}
+
+// LockConfig contains the distributed lock configuration
+type LockConfig struct {
+	Driver string   `json:"driver"` // memory or redis
+	Prefix string   `json:"prefix"` // prepended to every Redis key
+	TTL    Duration `json:"ttl"`    // lease of task and leader locks, renewed every third of it
+}
+
+// TasksConfig contains the task scheduler configuration
+type TasksConfig struct {
+	Schedule map[string]Duration `json:"schedule"` // task name to interval, run by the elected leader only
 }
 
-// Config is the main configuration struct
+// NotifierConfig contains outgoing notification configuration
+type NotifierConfig struct {
+	Driver   string `json:"driver"` // log or file
+	SpoolDir string `json:"spool_dir"`
+	From     string `json:"from"`
+}
+
+// Config is the main configuration struct. Fields tagged reload:"restart"
+// are only read at startup; a reload reports their changes but keeps the
+// running values.
 type Config struct {
-	Server   ServerConfig   `json:"server"`
-	Log      LogConfig      `json:"log"`
-	Database DatabaseConfig `json:"database"`
-	Redis    RedisConfig    `json:"redis"`
+	Server            ServerConfig            `json:"server"`
+	Log               LogConfig               `json:"log"`
+	Database          DatabaseConfig          `json:"database" reload:"restart"`
+	Redis             RedisConfig             `json:"redis" reload:"restart"`
+	JWT               JWTConfig               `json:"jwt" reload:"restart"`
+	Password          PasswordPolicyConfig    `json:"password"`
+	PasswordReset     PasswordResetConfig     `json:"password_reset"`
+	EmailVerification EmailVerificationConfig `json:"email_verification"`
+	LoginProtection   LoginProtectionConfig   `json:"login_protection"`
+	MFA               MFAConfig               `json:"mfa"`
+	OIDC              OIDCConfig              `json:"oidc"`
+	Cache             CacheConfig             `json:"cache" reload:"restart"`
+	Lock              LockConfig              `json:"lock" reload:"restart"`
+	Tasks             TasksConfig             `json:"tasks" reload:"restart"`
+	Notifier          NotifierConfig          `json:"notifier" reload:"restart"`
+	Secrets           SecretsConfig           `json:"secrets"`
+	Features          map[string]bool         `json:"features"` // feature flags, see FeatureEnabled
+}
+
+// current holds the configuration in effect. It is replaced as a whole by
+// LoadConfig and on reload, never modified in place.
+var current atomic.Pointer[Config]
+
+// GetConfig returns the configuration in effect, or the defaults before
+// LoadConfig has run. The returned value is shared and must not be modified.
+func GetConfig() *Config {
+	if cfg := current.Load(); cfg != nil {
+		return cfg
+	}
+	defaults := DefaultConfig()
+	current.CompareAndSwap(nil, &defaults)
+	return current.Load()
+}
+
+// SetConfig replaces the configuration in effect
+func SetConfig(cfg *Config) {
+	current.Store(cfg)
 }
 
-// ConfigData holds the application configuration
-var ConfigData Config
+// FeatureEnabled reports whether a feature flag is switched on
+func FeatureEnabled(name string) bool {
+	return GetConfig().Features[name]
+}
 
-func init() {
-	// Set default configuration
-	ConfigData = Config{
+// DefaultConfig returns the built-in configuration defaults
+func DefaultConfig() Config {
+	return Config{
 		Server: ServerConfig{
-			Port: 8080,
-			Mode: "release",
+			Port:        8080,
+			Mode:        "release",
+			CORSOrigins: []string{"*"},
 		},
 		Log: LogConfig{
 			Filename:   "logs/app.log",
 			Level:      "info",
+			Format:     "json",
+			Output:     "file",
 			MaxSize:    100,
 			MaxBackups: 10,
 			MaxAge:     30,
 			Compress:   true,
 		},
 		Database: DatabaseConfig{
-			Host:     "localhost",
-			Port:     3306,
-			Username: "root",
-			Password: "",
-			DBName:   "goapp",
+			Driver:          "mysql",
+			Host:            "localhost",
+			Username:        "root",
+			Password:        "",
+			DBName:          "goapp",
+			Path:            "storage/goapp.db",
+			Timezone:        "Local",
+			ConnectTimeout:  Duration(10 * time.Second),
+			ReadTimeout:     Duration(30 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			MaxOpenConns:    100,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration(time.Hour),
+			ConnMaxIdleTime: Duration(10 * time.Minute),
+			LogLevel:        "warn",
+			SlowThreshold:   Duration(200 * time.Millisecond),
+			ConnectRetries:  5,
+			RetryBackoff:    Duration(time.Second),
+			MaxRetryBackoff: Duration(30 * time.Second),
+
+			ReplicaPolicy:         "round_robin",
+			ReplicaHealthInterval: Duration(5 * time.Second),
+			ReadYourWritesWindow:  Duration(5 * time.Second),
 		},
 		Redis: RedisConfig{
-			Host:     "localhost",
-			Port:     6379,
-			Password: "",
-			DB:       0,
+			Host:         "localhost",
+			Port:         6379,
+			Password:     "",
+			DB:           0,
+			Protocol:     2,
+			PoolSize:     10,
+			PoolTimeout:  Duration(4 * time.Second),
+			IdleTimeout:  Duration(5 * time.Minute),
+			DialTimeout:  Duration(5 * time.Second),
+			ReadTimeout:  Duration(3 * time.Second),
+			WriteTimeout: Duration(3 * time.Second),
+			MaxRetries:   2,
+		},
+		JWT: JWTConfig{
+			Algorithm:       "HS256",
+			Issuer:          "goapp",
+			Audience:        "goapp-api",
+			AccessTokenTTL:  Duration(15 * time.Minute),
+			RefreshTokenTTL: Duration(30 * 24 * time.Hour),
+		},
+		Password: PasswordPolicyConfig{
+			MinLength:    8,
+			RequireUpper: true,
+			RequireLower: true,
+			RequireDigit: true,
+			HistorySize:  5,
+		},
+		PasswordReset: PasswordResetConfig{
+			TokenTTL:           Duration(30 * time.Minute),
+			MaxRequestsPerHour: 3,
+			ResetURL:           "http://localhost:8080/reset-password?token=",
+		},
+		EmailVerification: EmailVerificationConfig{
+			Required:          false,
+			TokenTTL:          Duration(24 * time.Hour),
+			MaxResendsPerHour: 3,
+			VerifyURL:         "http://localhost:8080/api/v1/users/verify?token=",
+		},
+		LoginProtection: LoginProtectionConfig{
+			Store:            "memory",
+			AccountThreshold: 5,
+			IPThreshold:      20,
+			BaseLockout:      Duration(30 * time.Second),
+			MaxLockout:       Duration(time.Hour),
+			FailureWindow:    Duration(time.Hour),
+		},
+		MFA: MFAConfig{
+			Issuer:        "goapp",
+			ChallengeTTL:  Duration(5 * time.Minute),
+			MaxAttempts:   5,
+			RecoveryCodes: 10,
+		},
+		OIDC: OIDCConfig{
+			StateTTL: Duration(10 * time.Minute),
+		},
+		Cache: CacheConfig{
+			Driver:      "memory",
+			Prefix:      "goapp:cache:",
+			DefaultTTL:  Duration(5 * time.Minute),
+			TTLJitter:   0.1,
+			Shards:      16,
+			MaxEntries:  10000,
+			MaxMemoryMB: 64,
+			LocalTTL:    Duration(30 * time.Second),
+		},
+		Lock: LockConfig{
+			Driver: "memory",
+			Prefix: "goapp:lock:",
+			TTL:    Duration(15 * time.Second),
+		},
+		Notifier: NotifierConfig{
+			Driver:   "log",
+			SpoolDir: "storage/mail",
+			From:     "no-reply@goapp.local",
+		},
+		Secrets: SecretsConfig{
+			File: "secrets.enc",
 		},
-	}
-
-	// Try to load configuration from file
-	if _, err := os.Stat("config.json"); err == nil {
-		file, err := os.Open("config.json")
-		if err != nil {
-			fmt.Printf("Warning: Could not open config file: %v\n", err)
-			return
-		}
-		defer file.Close()
-
-		decoder := json.NewDecoder(file)
-		if err := decoder.Decode(&ConfigData); err != nil {
-			fmt.Printf("Warning: Could not decode config file: %v\n", err)
-		}
 	}
 }
@@ -0,0 +1,387 @@
+package app
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPrefix prefixes every environment variable read by LoadConfig
+	EnvPrefix = "GOAPP_"
+	// ConfigFileEnv names the environment variable holding the config file path
+	ConfigFileEnv = "GOAPP_CONFIG"
+	// maskedValue replaces secrets in printed configuration
+	maskedValue = "********"
+)
+
+// defaultConfigFiles are tried in order when no file is given explicitly
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.toml", "config.json"}
+
+// ConfigError lists every problem found while loading the configuration
+type ConfigError struct {
+	Problems []string
+}
+
+// Error implements the error interface
+func (e *ConfigError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// configField is a single settable configuration value
+type configField struct {
+	key   string // dotted path of JSON names, e.g. database.host
+	value reflect.Value
+}
+
+// LoadConfig builds the configuration from, in increasing priority, the defaults,
+// a config file, GOAPP_* environment variables and command-line flags. The
+// file is taken from --config, then GOAPP_CONFIG, then the first of
+// ./config.{yaml,yml,toml,json} that exists; its format follows the
+// extension. Secret references are resolved last, see resolveSecrets.
+// Unknown keys, unparsable values and failed validation are
+// reported together as a *ConfigError. It returns the arguments left after
+// the flags.
+func LoadConfig(args []string) ([]string, error) {
+	cfg, rest, path, err := buildConfig(args)
+	if err != nil {
+		return nil, err
+	}
+
+	reloadMutex.Lock()
+	configArgs, configPath = args, path
+	reloadMutex.Unlock()
+
+	SetConfig(cfg)
+	return rest, nil
+}
+
+// buildConfig layers the configuration sources for the given arguments and
+// returns the result, the remaining arguments and the config file used
+func buildConfig(args []string) (*Config, []string, string, error) {
+	cfg := DefaultConfig()
+	fields := configFields(&cfg)
+
+	// Flags are parsed first so --config is known, but applied last
+	flagValues := make(map[string]string)
+	flagSet := flag.NewFlagSet("goapp", flag.ContinueOnError)
+	configFile := flagSet.String("config", "", "path to the config file (env "+ConfigFileEnv+")")
+	for _, field := range fields {
+		key := field.key
+		flagSet.Func(key, fmt.Sprintf("override %s (env %s)", key, envName(key)), func(raw string) error {
+			flagValues[key] = raw
+			return nil
+		})
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, nil, "", err
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv(ConfigFileEnv)
+	}
+	if path == "" {
+		path = findDefaultConfigFile()
+	}
+
+	var problems []string
+	if path != "" {
+		fileProblems, err := loadConfigFile(path, &cfg)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		problems = append(problems, fileProblems...)
+	}
+
+	for _, field := range fields {
+		if raw, exists := os.LookupEnv(envName(field.key)); exists {
+			if err := setConfigValue(field.value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", envName(field.key), err))
+			}
+		}
+	}
+
+	for _, field := range fields {
+		if raw, exists := flagValues[field.key]; exists {
+			if err := setConfigValue(field.value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("--%s: %v", field.key, err))
+			}
+		}
+	}
+
+	problems = append(problems, resolveSecrets(&cfg)...)
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, nil, "", &ConfigError{Problems: problems}
+	}
+	return &cfg, flagSet.Args(), path, nil
+}
+
+// findDefaultConfigFile returns the first default config file that exists
+func findDefaultConfigFile() string {
+	for _, path := range defaultConfigFiles {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadConfigFile decodes a config file over cfg, choosing the format from
+// the extension. Problems with individual keys are returned rather than
+// failing so they can be reported together.
+func loadConfigFile(path string, cfg *Config) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	data := make(map[string]interface{})
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(content, &data)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &data)
+	case ".toml":
+		err = toml.Unmarshal(content, &data)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q, use .json, .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	var problems []string
+	decodeSection(reflect.ValueOf(cfg).Elem(), data, "", &problems)
+	return problems, nil
+}
+
+// decodeSection applies a parsed config file section to a struct. Keys are
+// matched against the JSON names of the fields, so the three formats share
+// one schema. Unknown keys and bad values are collected in problems.
+func decodeSection(v reflect.Value, data map[string]interface{}, prefix string, problems *[]string) {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := data[key]
+		path := prefix + key
+
+		field, exists := fieldByName(v, key)
+		if !exists {
+			*problems = append(*problems, fmt.Sprintf("%s: unknown key", path))
+			continue
+		}
+
+		switch {
+		case field.Kind() == reflect.Struct:
+			section, ok := raw.(map[string]interface{})
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: expected a section", path))
+				continue
+			}
+			decodeSection(field, section, path+".", problems)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct:
+			items, ok := raw.([]interface{})
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: expected a list", path))
+				continue
+			}
+			list := reflect.MakeSlice(field.Type(), len(items), len(items))
+			for i, item := range items {
+				section, ok := item.(map[string]interface{})
+				if !ok {
+					*problems = append(*problems, fmt.Sprintf("%s[%d]: expected a section", path, i))
+					continue
+				}
+				decodeSection(list.Index(i), section, fmt.Sprintf("%s[%d].", path, i), problems)
+			}
+			field.Set(list)
+		default:
+			if err := decodeValue(field, raw); err != nil {
+				*problems = append(*problems, fmt.Sprintf("%s: %v", path, err))
+			}
+		}
+	}
+}
+
+// decodeValue converts a parsed file value into a field through JSON
+func decodeValue(field reflect.Value, raw interface{}) error {
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("unsupported value %v", raw)
+	}
+	if err := json.Unmarshal(encoded, field.Addr().Interface()); err != nil {
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			return fmt.Errorf("expected %s, got %s", typeErr.Type, typeErr.Value)
+		}
+		return err
+	}
+	return nil
+}
+
+// fieldByName finds the struct field with the given JSON name
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// jsonName returns the JSON name of a struct field
+func jsonName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// MaskedConfig returns a copy of the configuration with every secret replaced
+func MaskedConfig() Config {
+	cfg := *GetConfig()
+	deepCopy(reflect.ValueOf(&cfg).Elem())
+	maskSecrets(reflect.ValueOf(&cfg).Elem())
+	return cfg
+}
+
+// deepCopy replaces the slices, maps and pointers reachable from the
+// settable value v with copies, so changing v leaves the original alone
+func deepCopy(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				deepCopy(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		for i := 0; i < copied.Len(); i++ {
+			deepCopy(copied.Index(i))
+		}
+		v.Set(copied)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		entries := v.MapRange()
+		for entries.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(entries.Value())
+			deepCopy(value)
+			copied.SetMapIndex(entries.Key(), value)
+		}
+		v.Set(copied)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(v.Elem())
+		deepCopy(copied.Elem())
+		v.Set(copied)
+	}
+}
+
+// PrintConfig writes the effective configuration as JSON with secrets masked
+func PrintConfig() error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(MaskedConfig())
+}
+
+// maskSecrets blanks out non-empty string fields tagged secret:"true"
+func maskSecrets(v reflect.Value) {
+	walkSecretFields(v, "", func(_ string, field reflect.Value) {
+		if field.String() != "" {
+			field.SetString(maskedValue)
+		}
+	})
+}
+
+// configFields lists the settable values of cfg. Nested sections are
+// flattened; any other value, such as a list of providers, is one field.
+func configFields(cfg *Config) []configField {
+	var fields []configField
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			name := jsonName(v.Type().Field(i))
+			if name == "" || name == "-" {
+				continue
+			}
+			key := prefix + name
+			if v.Field(i).Kind() == reflect.Struct {
+				walk(v.Field(i), key+".")
+				continue
+			}
+			fields = append(fields, configField{key: key, value: v.Field(i)})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return fields
+}
+
+// envName returns the environment variable of a config key,
+// e.g. database.host becomes GOAPP_DATABASE_HOST
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// setConfigValue parses raw into v. String lists are comma separated and
+// anything else that is not a scalar is given as JSON.
+func setConfigValue(v reflect.Value, raw string) error {
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			var items []string
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			v.Set(reflect.ValueOf(items))
+			return nil
+		}
+		return json.Unmarshal([]byte(raw), v.Addr().Interface())
+	default:
+		return json.Unmarshal([]byte(raw), v.Addr().Interface())
+	}
+	return nil
+}
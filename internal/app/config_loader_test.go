@@ -0,0 +1,43 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestMaskedConfigLeavesLiveConfigAlone(t *testing.T) {
+	previous := GetConfig()
+	t.Cleanup(func() { SetConfig(previous) })
+
+	cfg := DefaultConfig()
+	cfg.Database.Password = "primary-secret"
+	cfg.Database.Replicas = []ReplicaConfig{{Host: "replica", Password: "replica-secret"}}
+	cfg.OIDC.Providers = []OIDCProviderConfig{{Name: "idp", ClientSecret: "client-secret", Scopes: []string{"openid"}}}
+	SetConfig(&cfg)
+
+	masked := MaskedConfig()
+	if masked.Database.Password != maskedValue {
+		t.Errorf("database password = %q, want it masked", masked.Database.Password)
+	}
+	if got := masked.Database.Replicas[0].Password; got != maskedValue {
+		t.Errorf("replica password = %q, want it masked", got)
+	}
+	if got := masked.OIDC.Providers[0].ClientSecret; got != maskedValue {
+		t.Errorf("client secret = %q, want it masked", got)
+	}
+
+	live := GetConfig()
+	if got := live.Database.Password; got != "primary-secret" {
+		t.Errorf("live database password = %q", got)
+	}
+	if got := live.Database.Replicas[0].Password; got != "replica-secret" {
+		t.Errorf("live replica password = %q", got)
+	}
+	if got := live.OIDC.Providers[0].ClientSecret; got != "client-secret" {
+		t.Errorf("live client secret = %q", got)
+	}
+
+	masked.OIDC.Providers[0].Scopes[0] = "changed"
+	if got := live.OIDC.Providers[0].Scopes[0]; got != "openid" {
+		t.Errorf("live scopes changed through the masked copy: %q", got)
+	}
+}
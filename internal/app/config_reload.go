@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// configPollInterval is how often the config file is checked for changes
+const configPollInterval = 2 * time.Second
+
+var (
+	// reloadMutex serializes reloads and guards the sources below
+	reloadMutex sync.Mutex
+	configArgs  []string
+	configPath  string
+
+	reloadHooks []func(ConfigReload)
+)
+
+// ConfigChange is one setting that differs after a reload
+type ConfigChange struct {
+	Key             string      `json:"key"`
+	Old             interface{} `json:"old"`
+	New             interface{} `json:"new"`
+	RestartRequired bool        `json:"restart_required"`
+}
+
+// ConfigReload describes a reload that changed the configuration
+type ConfigReload struct {
+	Source  string         `json:"source"` // signal, file or manual
+	File    string         `json:"file"`
+	Changes []ConfigChange `json:"changes"`
+}
+
+// OnConfigReload registers a function called after every reload that
+// changed something
+func OnConfigReload(hook func(ConfigReload)) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// ReloadConfig rebuilds the configuration from the sources LoadConfig used
+// and swaps it in. Settings marked reload:"restart" keep their running
+// values and are reported as needing a restart. An invalid configuration
+// is rejected and the current one stays in effect.
+func ReloadConfig(source string) (*ConfigReload, error) {
+	reloadMutex.Lock()
+	reload, err := swapConfig(source)
+	hooks := reloadHooks
+	reloadMutex.Unlock()
+
+	if err != nil || len(reload.Changes) == 0 {
+		return reload, err
+	}
+	for _, hook := range hooks {
+		hook(*reload)
+	}
+	return reload, nil
+}
+
+// swapConfig builds and swaps in the new configuration. The caller holds
+// reloadMutex.
+func swapConfig(source string) (*ConfigReload, error) {
+	cfg, _, path, err := buildConfig(configArgs)
+	if err != nil {
+		Error("Configuration reload rejected", "source", source, "error", err)
+		return nil, err
+	}
+	configPath = path
+
+	reload := &ConfigReload{Source: source, File: path}
+	diffConfig(reflect.ValueOf(GetConfig()).Elem(), reflect.ValueOf(cfg).Elem(), "", false, &reload.Changes)
+	if len(reload.Changes) == 0 {
+		Info("Configuration reloaded without changes", "source", source)
+		return reload, nil
+	}
+
+	SetConfig(cfg)
+
+	for _, change := range reload.Changes {
+		if change.RestartRequired {
+			Warn("Configuration change requires a restart", "key", change.Key)
+		}
+	}
+	Info("Configuration reloaded", "source", source, "changes", len(reload.Changes))
+	return reload, nil
+}
+
+// WatchConfig reloads the configuration on SIGHUP and whenever the config
+// file changes. It returns a function that stops watching.
+func WatchConfig() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+
+		lastModified := configFileVersion()
+		for {
+			select {
+			case <-signals:
+				ReloadConfig("signal")
+				lastModified = configFileVersion()
+			case <-ticker.C:
+				if version := configFileVersion(); version != lastModified {
+					lastModified = version
+					ReloadConfig("file")
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// configFileVersion identifies the current contents of the config file by
+// its modification time and size
+func configFileVersion() string {
+	reloadMutex.Lock()
+	path := configPath
+	reloadMutex.Unlock()
+
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%d", info.ModTime(), info.Size())
+}
+
+// diffConfig records every setting that differs between two configurations.
+// Changed settings that need a restart are set back to their running value
+// in next.
+func diffConfig(running, next reflect.Value, prefix string, restart bool, changes *[]ConfigChange) {
+	for i := 0; i < running.NumField(); i++ {
+		field := running.Type().Field(i)
+		name := jsonName(field)
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+		fieldRestart := restart || field.Tag.Get("reload") == "restart"
+
+		if field.Type.Kind() == reflect.Struct {
+			diffConfig(running.Field(i), next.Field(i), key+".", fieldRestart, changes)
+			continue
+		}
+		if reflect.DeepEqual(running.Field(i).Interface(), next.Field(i).Interface()) {
+			continue
+		}
+
+		*changes = append(*changes, ConfigChange{
+			Key:             key,
+			Old:             displayValue(field, running.Field(i)),
+			New:             displayValue(field, next.Field(i)),
+			RestartRequired: fieldRestart,
+		})
+		if fieldRestart {
+			next.Field(i).Set(running.Field(i))
+		}
+	}
+}
+
+// displayValue returns a setting's value with secrets masked
+func displayValue(field reflect.StructField, v reflect.Value) interface{} {
+	if field.Tag.Get("secret") == "true" {
+		if v.IsZero() {
+			return ""
+		}
+		return maskedValue
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct {
+		masked := reflect.New(v.Type()).Elem()
+		masked.Set(v)
+		deepCopy(masked)
+		maskSecrets(masked)
+		return masked.Interface()
+	}
+	return v.Interface()
+}
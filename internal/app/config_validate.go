@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	validServerModes     = []string{gin.DebugMode, gin.ReleaseMode, gin.TestMode}
+	validLogLevels       = []string{"debug", "info", "warn", "error"}
+	validLogFormats      = []string{"json", "logfmt", "console"}
+	validLogOutputs      = []string{"file", "stdout", "stderr"}
+	validJWTAlgorithms   = []string{"HS256", "RS256"}
+	validAttemptStores   = []string{"memory", "redis"}
+	validNotifierDrivers = []string{"log", "file"}
+	validCacheDrivers    = []string{"memory", "redis", "two_tier"}
+	validLockDrivers     = []string{"memory", "redis"}
+	validDBLogLevels     = []string{"silent", "error", "warn", "info"}
+	validDBDrivers       = []string{"mysql", "postgres", "sqlite"}
+	validReplicaPolicies = []string{"random", "round_robin", "least_conn"}
+)
+
+// Validate checks the configuration and reports every problem at once
+func (c *Config) Validate() error {
+	if problems := c.validate(); len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// validate returns a description of every invalid setting
+func (c *Config) validate() []string {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	oneOf := func(key, value string, allowed []string) {
+		for _, candidate := range allowed {
+			if value == candidate {
+				return
+			}
+		}
+		problems = append(problems, fmt.Sprintf("%s: %q is not one of %s", key, value, strings.Join(allowed, ", ")))
+	}
+	port := func(key string, value int) {
+		check(value >= 1 && value <= 65535, "%s: %d is not a valid port (1-65535)", key, value)
+	}
+	positive := func(key string, value Duration) {
+		check(value > 0, "%s: must be a positive duration", key)
+	}
+
+	port("server.port", c.Server.Port)
+	oneOf("server.mode", c.Server.Mode, validServerModes)
+
+	oneOf("log.level", c.Log.Level, validLogLevels)
+	oneOf("log.format", c.Log.Format, validLogFormats)
+	oneOf("log.output", c.Log.Output, validLogOutputs)
+	check(c.Log.Output != "file" || c.Log.Filename != "", "log.filename: must not be empty when log.output is file")
+	check(c.Log.MaxSize >= 0, "log.max_size: must not be negative")
+	check(c.Log.MaxBackups >= 0, "log.max_backups: must not be negative")
+	check(c.Log.MaxAge >= 0, "log.max_age: must not be negative")
+
+	oneOf("database.driver", c.Database.Driver, validDBDrivers)
+	if c.Database.Driver == "sqlite" {
+		check(c.Database.Path != "", "database.path: required for sqlite")
+	} else {
+		check(c.Database.Port == 0 || c.Database.Port >= 1 && c.Database.Port <= 65535, "database.port: %d is not a valid port (1-65535)", c.Database.Port)
+		check(c.Database.Host != "", "database.host: must not be empty")
+	}
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns: must not be negative")
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns: must not be negative")
+	check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns, "database.max_idle_conns: must not exceed max_open_conns")
+	check(c.Database.ConnectRetries >= 0, "database.connect_retries: must not be negative")
+	oneOf("database.log_level", c.Database.LogLevel, validDBLogLevels)
+	if _, err := time.LoadLocation(c.Database.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("database.timezone: %v", err))
+	}
+	oneOf("database.replica_policy", c.Database.ReplicaPolicy, validReplicaPolicies)
+	positive("database.replica_health_interval", c.Database.ReplicaHealthInterval)
+	check(c.Database.ReadYourWritesWindow >= 0, "database.read_your_writes_window: must not be negative")
+	for i, replica := range c.Database.Replicas {
+		key := fmt.Sprintf("database.replicas[%d]", i)
+		check(replica.Port == 0 || replica.Port >= 1 && replica.Port <= 65535, "%s.port: %d is not a valid port (1-65535)", key, replica.Port)
+		if c.Database.Driver == "sqlite" {
+			check(replica.Path != "", "%s.path: required for sqlite", key)
+		} else {
+			check(replica.Host != "", "%s.host: must not be empty", key)
+		}
+	}
+
+	port("redis.port", c.Redis.Port)
+	check(c.Redis.DB >= 0, "redis.db: must not be negative")
+	check(c.Redis.Protocol == 2 || c.Redis.Protocol == 3, "redis.protocol: %d is not 2 or 3", c.Redis.Protocol)
+	check(c.Redis.PoolSize >= 1, "redis.pool_size: must be at least 1")
+	positive("redis.pool_timeout", c.Redis.PoolTimeout)
+	positive("redis.dial_timeout", c.Redis.DialTimeout)
+	check(c.Redis.IdleTimeout >= 0, "redis.idle_timeout: must not be negative")
+	check(c.Redis.ReadTimeout >= 0, "redis.read_timeout: must not be negative")
+	check(c.Redis.WriteTimeout >= 0, "redis.write_timeout: must not be negative")
+	check(c.Redis.MaxRetries >= 0, "redis.max_retries: must not be negative")
+
+	oneOf("jwt.algorithm", strings.ToUpper(c.JWT.Algorithm), validJWTAlgorithms)
+	if strings.ToUpper(c.JWT.Algorithm) == "RS256" {
+		check(c.JWT.PrivateKeyFile != "", "jwt.private_key_file: required for RS256")
+	}
+	positive("jwt.access_token_ttl", c.JWT.AccessTokenTTL)
+	positive("jwt.refresh_token_ttl", c.JWT.RefreshTokenTTL)
+
+	check(c.Password.MinLength >= 1, "password.min_length: must be at least 1")
+	check(c.Password.HistorySize >= 0, "password.history_size: must not be negative")
+
+	positive("password_reset.token_ttl", c.PasswordReset.TokenTTL)
+	positive("email_verification.token_ttl", c.EmailVerification.TokenTTL)
+
+	oneOf("login_protection.store", c.LoginProtection.Store, validAttemptStores)
+	positive("login_protection.base_lockout", c.LoginProtection.BaseLockout)
+	positive("login_protection.failure_window", c.LoginProtection.FailureWindow)
+	check(c.LoginProtection.MaxLockout >= c.LoginProtection.BaseLockout, "login_protection.max_lockout: must not be shorter than base_lockout")
+
+	positive("mfa.challenge_ttl", c.MFA.ChallengeTTL)
+	check(c.MFA.MaxAttempts >= 1, "mfa.max_attempts: must be at least 1")
+
+	positive("oidc.state_ttl", c.OIDC.StateTTL)
+	names := make(map[string]bool)
+	for i, provider := range c.OIDC.Providers {
+		key := fmt.Sprintf("oidc.providers[%d]", i)
+		check(provider.Name != "", "%s.name: must not be empty", key)
+		check(!names[provider.Name], "%s.name: duplicate provider %q", key, provider.Name)
+		check(provider.Issuer != "", "%s.issuer: must not be empty", key)
+		check(provider.ClientID != "", "%s.client_id: must not be empty", key)
+		check(provider.RedirectURL != "", "%s.redirect_url: must not be empty", key)
+		names[provider.Name] = true
+	}
+
+	oneOf("cache.driver", c.Cache.Driver, validCacheDrivers)
+	positive("cache.default_ttl", c.Cache.DefaultTTL)
+	positive("cache.local_ttl", c.Cache.LocalTTL)
+	check(c.Cache.TTLJitter >= 0 && c.Cache.TTLJitter < 1, "cache.ttl_jitter: must be at least 0 and below 1")
+	check(c.Cache.Shards >= 1, "cache.shards: must be at least 1")
+	check(c.Cache.MaxEntries >= 0, "cache.max_entries: must not be negative")
+	check(c.Cache.MaxMemoryMB >= 0, "cache.max_memory_mb: must not be negative")
+
+	oneOf("lock.driver", c.Lock.Driver, validLockDrivers)
+	check(c.Lock.TTL.Duration() >= time.Second, "lock.ttl: must be at least 1s")
+	scheduled := make([]string, 0, len(c.Tasks.Schedule))
+	for name := range c.Tasks.Schedule {
+		scheduled = append(scheduled, name)
+	}
+	sort.Strings(scheduled)
+	for _, name := range scheduled {
+		positive("tasks.schedule."+name, c.Tasks.Schedule[name])
+	}
+
+	oneOf("notifier.driver", c.Notifier.Driver, validNotifierDrivers)
+
+	return problems
+}
@@ -2,60 +2,284 @@ package app
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/glebarez/sqlite"
+	mysqldriver "github.com/go-sql-driver/mysql"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 )
 
+// sqliteMemoryPath selects an in-memory SQLite database
+const sqliteMemoryPath = ":memory:"
+
+// defaultDBPorts are used when database.port is 0
+var defaultDBPorts = map[string]int{
+	"mysql":    3306,
+	"postgres": 5432,
+}
+
+// postgresSSLModes maps database.tls to PostgreSQL sslmode values
+var postgresSSLModes = map[string]string{
+	"":            "disable",
+	"false":       "disable",
+	"preferred":   "prefer",
+	"skip-verify": "require",
+	"true":        "verify-full",
+}
+
 // DB is the global database connection
 var DB *gorm.DB
 
-// InitDB initializes the database connection
+// gormLogLevels maps database.log_level to GORM log levels
+var gormLogLevels = map[string]gormlogger.LogLevel{
+	"silent": gormlogger.Silent,
+	"error":  gormlogger.Error,
+	"warn":   gormlogger.Warn,
+	"info":   gormlogger.Info,
+}
+
+// InitDB initializes the database connection from the database config,
+// retrying with backoff before giving up
 func InitDB() {
-	// Default database configuration
-	host := "localhost"
-	port := 3306
-	username := "root"
-	password := ""
-	dbname := "goapp"
-
-	// Build connection string
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		username,
-		password,
-		host,
-		port,
-		dbname,
-	)
-
-	// Configure GORM
-	config := &gorm.Config{
-		Logger: gormlogger.Default.LogMode(gormlogger.Info),
-	}
-
-	// Try to connect to the database
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), config)
+	db, err := OpenDatabase(GetConfig().Database)
 	if err != nil {
 		fmt.Printf("Failed to connect to database: %v\n", err)
 		panic(err)
 	}
+	DB = db
 
-	// Set connection pool settings
-	sqlDB, err := DB.DB()
+	cfg := GetConfig().Database
+	fmt.Println("Database connected successfully")
+	if cfg.Driver == "sqlite" {
+		Info("Database connected successfully", "driver", cfg.Driver, "path", cfg.Path)
+	} else {
+		Info("Database connected successfully", "driver", cfg.Driver, "host", cfg.Host, "database", cfg.DBName)
+	}
+}
+
+// OpenDatabase connects to the configured database and applies the pool
+// settings. Failed attempts are retried connect_retries times, waiting
+// retry_backoff and doubling the wait up to max_retry_backoff.
+func OpenDatabase(cfg DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := newDialector(cfg)
 	if err != nil {
-		fmt.Printf("Failed to get underlying *sql.DB: %v\n", err)
-		panic(err)
+		return nil, err
+	}
+
+	gormConfig := &gorm.Config{
+		Logger: newGormLogger(cfg),
 	}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	backoff := cfg.RetryBackoff.Duration()
+	for attempt := 0; ; attempt++ {
+		db, err := gorm.Open(dialector, gormConfig)
+		if err == nil {
+			if err = configurePool(db, cfg); err == nil {
+				if len(cfg.Replicas) > 0 {
+					if err := useReplicas(db, cfg); err != nil {
+						CloseDatabase(db)
+						return nil, fmt.Errorf("error configuring database replicas: %w", err)
+					}
+				}
+				return db, nil
+			}
+			CloseDatabase(db)
+		}
 
-	fmt.Println("Database connected successfully")
-	Info("Database connected successfully")
+		if attempt >= cfg.ConnectRetries {
+			return nil, fmt.Errorf("error connecting to database after %d attempts: %w", attempt+1, err)
+		}
+
+		Warn("Database connection failed, retrying", "attempt", attempt+1, "retry_in", backoff.String(), "error", err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if max := cfg.MaxRetryBackoff.Duration(); max > 0 && backoff > max {
+			backoff = max
+		}
+	}
+}
+
+// newDialector returns the GORM dialector of the configured driver
+func newDialector(cfg DatabaseConfig) (gorm.Dialector, error) {
+	dsn, err := BuildDSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Driver {
+	case "", "mysql":
+		return mysql.Open(dsn), nil
+	case "postgres":
+		return postgres.Open(dsn), nil
+	case "sqlite":
+		if cfg.Path != sqliteMemoryPath {
+			if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+				return nil, fmt.Errorf("error creating database directory: %w", err)
+			}
+		}
+		return sqlite.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+}
+
+// BuildDSN builds the connection string of the configured driver
+func BuildDSN(cfg DatabaseConfig) (string, error) {
+	switch cfg.Driver {
+	case "", "mysql":
+		return buildMySQLDSN(cfg)
+	case "postgres":
+		return buildPostgresDSN(cfg)
+	case "sqlite":
+		return buildSQLiteDSN(cfg), nil
+	}
+	return "", fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+}
+
+// buildMySQLDSN builds a go-sql-driver/mysql DSN
+func buildMySQLDSN(cfg DatabaseConfig) (string, error) {
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return "", fmt.Errorf("invalid database timezone: %w", err)
+	}
+
+	dsn := mysqldriver.NewConfig()
+	dsn.User = cfg.Username
+	dsn.Passwd = cfg.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(databasePort(cfg)))
+	dsn.DBName = cfg.DBName
+	dsn.ParseTime = true
+	dsn.Loc = location
+	dsn.TLSConfig = cfg.TLS
+	dsn.Timeout = cfg.ConnectTimeout.Duration()
+	dsn.ReadTimeout = cfg.ReadTimeout.Duration()
+	dsn.WriteTimeout = cfg.WriteTimeout.Duration()
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	for key, value := range cfg.Params {
+		dsn.Params[key] = value
+	}
+	return dsn.FormatDSN(), nil
+}
+
+// buildPostgresDSN builds a postgres:// URL for pgx
+func buildPostgresDSN(cfg DatabaseConfig) (string, error) {
+	sslMode, exists := postgresSSLModes[cfg.TLS]
+	if !exists {
+		return "", fmt.Errorf("unsupported database tls setting for postgres: %s", cfg.TLS)
+	}
+
+	params := url.Values{}
+	params.Set("sslmode", sslMode)
+	if cfg.Timezone != "" && cfg.Timezone != "Local" {
+		params.Set("TimeZone", cfg.Timezone)
+	}
+	if timeout := cfg.ConnectTimeout.Duration(); timeout > 0 {
+		params.Set("connect_timeout", strconv.Itoa(int(timeout.Seconds())))
+	}
+	for key, value := range cfg.Params {
+		params.Set(key, value)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.Username, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(databasePort(cfg))),
+		Path:     "/" + cfg.DBName,
+		RawQuery: params.Encode(),
+	}
+	return dsn.String(), nil
+}
+
+// buildSQLiteDSN builds a SQLite DSN with foreign keys enforced. An
+// in-memory database uses a shared cache so all pool connections see it.
+func buildSQLiteDSN(cfg DatabaseConfig) string {
+	pragmas := []string{"foreign_keys(1)"}
+	if timeout := cfg.ConnectTimeout.Duration(); timeout > 0 {
+		pragmas = append(pragmas, fmt.Sprintf("busy_timeout(%d)", timeout.Milliseconds()))
+	}
+
+	params := make([]string, 0, len(pragmas)+len(cfg.Params))
+	for _, pragma := range pragmas {
+		params = append(params, "_pragma="+pragma)
+	}
+	keys := make([]string, 0, len(cfg.Params))
+	for key := range cfg.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(cfg.Params[key]))
+	}
+
+	path := cfg.Path
+	if path == sqliteMemoryPath {
+		path = "file::memory:"
+		params = append(params, "cache=shared")
+	}
+	return path + "?" + strings.Join(params, "&")
+}
+
+// databasePort returns the configured port or the driver's default
+func databasePort(cfg DatabaseConfig) int {
+	if cfg.Port != 0 {
+		return cfg.Port
+	}
+	return defaultDBPorts[cfg.Driver]
+}
+
+// configurePool applies the connection pool settings
+func configurePool(db *gorm.DB, cfg DatabaseConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("error getting underlying *sql.DB: %w", err)
+	}
+
+	// An in-memory SQLite database lives only as long as a connection to
+	// it, so keep exactly one connection open for good
+	if cfg.Driver == "sqlite" && cfg.Path == sqliteMemoryPath {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+		return nil
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration())
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime.Duration())
+	return nil
+}
+
+// CloseDatabase stops the replica health checks of a database opened by
+// OpenDatabase and closes its replica and primary connections
+func CloseDatabase(db *gorm.DB) error {
+	if set, ok := db.Config.Plugins[replicaPluginName].(*replicaSet); ok {
+		set.close()
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// CloseDB closes the global database connection
+func CloseDB() error {
+	if DB == nil {
+		return nil
+	}
+	return CloseDatabase(DB)
 }
 
 // GetDB returns the database instance
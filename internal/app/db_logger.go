@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLoggerPackage prefixes the function names of gormLogger's methods
+const gormLoggerPackage = "goapp/internal/app.(*gormLogger)"
+
+// gormLogger writes GORM's messages to the application log at the matching
+// level: failed queries at error, slow queries at warn, and every query at
+// info only when database.log_level is info. log.level still applies on top.
+type gormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// newGormLogger creates the GORM logger for the database config
+func newGormLogger(cfg DatabaseConfig) gormlogger.Interface {
+	level, exists := gormLogLevels[cfg.LogLevel]
+	if !exists {
+		level = gormlogger.Warn
+	}
+	return &gormLogger{level: level, slowThreshold: cfg.SlowThreshold.Duration()}
+}
+
+// LogMode implements gormlogger.Interface
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info implements gormlogger.Interface
+func (l *gormLogger) Info(_ context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.log(slog.LevelInfo, fmt.Sprintf(format, args...))
+	}
+}
+
+// Warn implements gormlogger.Interface
+func (l *gormLogger) Warn(_ context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.log(slog.LevelWarn, fmt.Sprintf(format, args...))
+	}
+}
+
+// Error implements gormlogger.Interface
+func (l *gormLogger) Error(_ context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.log(slog.LevelError, fmt.Sprintf(format, args...))
+	}
+}
+
+// Trace implements gormlogger.Interface. A record that was not found is
+// an ordinary result, not an error.
+func (l *gormLogger) Trace(_ context.Context, begin time.Time, query func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := query()
+		l.log(slog.LevelError, "Database query failed", "error", err, "sql", sql, "rows", rows, "elapsed", elapsed)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := query()
+		l.log(slog.LevelWarn, "Slow database query", "sql", sql, "rows", rows, "elapsed", elapsed, "threshold", l.slowThreshold)
+	case l.level >= gormlogger.Info:
+		sql, rows := query()
+		l.log(slog.LevelInfo, "Database query", "sql", sql, "rows", rows, "elapsed", elapsed)
+	}
+}
+
+// log writes a GORM record with the code that ran the query as its caller
+func (l *gormLogger) log(level slog.Level, msg string, args ...interface{}) {
+	logRecord(level, gormCaller(), msg, append([]interface{}{"component", "gorm"}, args...))
+}
+
+// gormCaller returns the program counter of the first frame outside GORM
+// and this logger, which is the code that ran the query
+func gormCaller() uintptr {
+	var pcs [32]uintptr
+	count := runtime.Callers(2, pcs[:])
+	for _, pc := range pcs[:count] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "gorm.io/") && !strings.HasPrefix(frame.Function, gormLoggerPackage) {
+			return pc
+		}
+	}
+	return 0
+}
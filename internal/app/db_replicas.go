@@ -0,0 +1,328 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// replicaPluginName registers the replica router with GORM
+	replicaPluginName = "goapp:replicas"
+	// replicaReadSetting marks a statement that may be served by a replica
+	replicaReadSetting = "goapp:replica_read"
+	// replicaUsedSetting holds the replica a statement was sent to
+	replicaUsedSetting = "goapp:replica_used"
+)
+
+// ReplicaRead marks a query as safe to serve from a read replica:
+//
+//	r.db.WithContext(ctx).Clauses(app.ReplicaRead).Find(&products)
+//
+// Queries without it, writes, transactions, locking reads and requests
+// that asked to read their own writes always use the primary.
+var ReplicaRead clause.Expression = replicaRead{}
+
+// replicaRead implements gorm.StatementModifier
+type replicaRead struct{}
+
+// ModifyStatement marks the statement as replica eligible
+func (replicaRead) ModifyStatement(stmt *gorm.Statement) {
+	stmt.Settings.Store(replicaReadSetting, struct{}{})
+}
+
+// Build implements clause.Expression; the marker adds no SQL
+func (replicaRead) Build(clause.Builder) {}
+
+// primaryReadsKey is the context key of the read-your-writes override
+type primaryReadsKey struct{}
+
+// WithPrimaryReads returns a context whose reads all go to the primary, so
+// a client sees its own writes despite replication lag
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether ctx asked for reads from the primary
+func PrimaryReads(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
+
+// ReplicaState reports the health of one read replica
+type ReplicaState struct {
+	Name      string `json:"name"`
+	Healthy   bool   `json:"healthy"`
+	InUse     int    `json:"in_use"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// replica is one read replica connection pool
+type replica struct {
+	name      string
+	pool      *sql.DB
+	healthy   atomic.Bool
+	lastError atomic.Value // string
+}
+
+// replicaSet is a GORM plugin that sends replica-eligible reads to a
+// healthy replica picked by the configured policy. Reads fall back to the
+// primary while no replica is healthy.
+type replicaSet struct {
+	replicas []*replica
+	policy   string
+	next     atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// useReplicas opens the configured replicas and registers the router. The
+// health checks run until the database is closed with CloseDatabase.
+func useReplicas(db *gorm.DB, cfg DatabaseConfig) error {
+	set := &replicaSet{
+		policy: cfg.ReplicaPolicy,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for _, replicaCfg := range cfg.Replicas {
+		r, err := openReplica(cfg, replicaCfg)
+		if err != nil {
+			set.closePools()
+			return err
+		}
+		set.replicas = append(set.replicas, r)
+	}
+
+	if err := db.Use(set); err != nil {
+		set.closePools()
+		return err
+	}
+	set.checkHealth(cfg.ReplicaHealthInterval.Duration())
+	go set.watchHealth(cfg.ReplicaHealthInterval.Duration())
+	return nil
+}
+
+// openReplica opens the pool of a replica. Unset connection settings are
+// inherited from the primary; connectivity is left to the health checks.
+func openReplica(primary DatabaseConfig, replicaCfg ReplicaConfig) (*replica, error) {
+	cfg := primary
+	cfg.Replicas = nil
+	if replicaCfg.Host != "" {
+		cfg.Host = replicaCfg.Host
+	}
+	if replicaCfg.Port != 0 {
+		cfg.Port = replicaCfg.Port
+	}
+	if replicaCfg.Username != "" {
+		cfg.Username = replicaCfg.Username
+		cfg.Password = replicaCfg.Password
+	}
+	if replicaCfg.Path != "" {
+		cfg.Path = replicaCfg.Path
+	}
+
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               newGormLogger(cfg),
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	pool, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if err := configurePool(db, cfg); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	name := cfg.Path
+	if cfg.Driver != "sqlite" {
+		name = net.JoinHostPort(cfg.Host, strconv.Itoa(databasePort(cfg)))
+	}
+	r := &replica{name: name, pool: pool}
+	r.healthy.Store(true)
+	return r, nil
+}
+
+// Name implements gorm.Plugin
+func (s *replicaSet) Name() string {
+	return replicaPluginName
+}
+
+// Initialize implements gorm.Plugin
+func (s *replicaSet) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register(replicaPluginName, s.route); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register(replicaPluginName, s.route); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register(replicaPluginName+":errors", s.recordError); err != nil {
+		return err
+	}
+	return db.Callback().Row().After("gorm:row").Register(replicaPluginName+":errors", s.recordError)
+}
+
+// route switches a replica-eligible statement to a healthy replica
+func (s *replicaSet) route(db *gorm.DB) {
+	stmt := db.Statement
+	if _, eligible := stmt.Settings.Load(replicaReadSetting); !eligible {
+		return
+	}
+	if _, inTransaction := stmt.ConnPool.(gorm.TxCommitter); inTransaction {
+		return
+	}
+	if _, locking := stmt.Clauses["FOR"]; locking || PrimaryReads(stmt.Context) {
+		return
+	}
+
+	if r := s.pick(); r != nil {
+		stmt.ConnPool = r.pool
+		stmt.Settings.Store(replicaUsedSetting, r)
+	}
+}
+
+// recordError takes a replica out of rotation when a query on it failed
+// because the connection did
+func (s *replicaSet) recordError(db *gorm.DB) {
+	value, used := db.Statement.Settings.Load(replicaUsedSetting)
+	if !used || db.Error == nil {
+		return
+	}
+	var netErr net.Error
+	if errors.Is(db.Error, driver.ErrBadConn) || errors.As(db.Error, &netErr) {
+		s.setHealth(value.(*replica), db.Error)
+	}
+}
+
+// pick returns a healthy replica according to the policy, or nil
+func (s *replicaSet) pick() *replica {
+	healthy := make([]*replica, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return nil
+	}
+
+	switch s.policy {
+	case "random":
+		return healthy[rand.Intn(len(healthy))]
+	case "least_conn":
+		best := healthy[0]
+		for _, r := range healthy[1:] {
+			if r.pool.Stats().InUse < best.pool.Stats().InUse {
+				best = r
+			}
+		}
+		return best
+	default:
+		return healthy[s.next.Add(1)%uint64(len(healthy))]
+	}
+}
+
+// watchHealth pings every replica each interval until the set is closed
+func (s *replicaSet) watchHealth(interval time.Duration) {
+	defer close(s.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.checkHealth(interval)
+		}
+	}
+}
+
+// close stops the health checks and closes the replica pools
+func (s *replicaSet) close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.closePools()
+	})
+}
+
+// closePools closes the connection pool of every replica
+func (s *replicaSet) closePools() {
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
+}
+
+// checkHealth pings every replica once
+func (s *replicaSet) checkHealth(timeout time.Duration) {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := r.pool.PingContext(ctx)
+		cancel()
+		s.setHealth(r, err)
+	}
+}
+
+// setHealth records a health check result and logs changes
+func (s *replicaSet) setHealth(r *replica, err error) {
+	healthy := err == nil
+	if err != nil {
+		r.lastError.Store(err.Error())
+	}
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		Info("Database replica healthy, added to rotation", "replica", r.name)
+	} else {
+		Warn("Database replica unhealthy, removed from rotation", "replica", r.name, "error", err)
+	}
+}
+
+// ReplicaStatus reports the read replicas of the application database
+func ReplicaStatus() []ReplicaState {
+	if DB == nil {
+		return nil
+	}
+	set, ok := DB.Config.Plugins[replicaPluginName].(*replicaSet)
+	if !ok {
+		return nil
+	}
+
+	states := make([]ReplicaState, 0, len(set.replicas))
+	for _, r := range set.replicas {
+		state := ReplicaState{
+			Name:    r.name,
+			Healthy: r.healthy.Load(),
+			InUse:   r.pool.Stats().InUse,
+		}
+		if !state.Healthy {
+			state.LastError, _ = r.lastError.Load().(string)
+		}
+		states = append(states, state)
+	}
+	return states
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCloseDatabaseStopsReplicaHealthChecks(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultConfig().Database
+	cfg.Driver = "sqlite"
+	cfg.Path = filepath.Join(dir, "primary.db")
+	cfg.ConnectRetries = 0
+	cfg.ReplicaHealthInterval = Duration(10 * time.Millisecond)
+	cfg.Replicas = []ReplicaConfig{{Path: filepath.Join(dir, "replica.db")}}
+
+	db, err := OpenDatabase(cfg)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	set, ok := db.Config.Plugins[replicaPluginName].(*replicaSet)
+	if !ok {
+		t.Fatal("replica plugin not registered")
+	}
+	if !set.replicas[0].healthy.Load() {
+		t.Fatalf("replica unhealthy: %v", set.replicas[0].lastError.Load())
+	}
+
+	if err := CloseDatabase(db); err != nil {
+		t.Fatalf("close database: %v", err)
+	}
+	select {
+	case <-set.done:
+	case <-time.After(time.Second):
+		t.Fatal("health checks still running after close")
+	}
+	if err := set.replicas[0].pool.Ping(); err == nil {
+		t.Error("replica pool still open after close")
+	}
+}
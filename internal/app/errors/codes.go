@@ -9,16 +9,17 @@ const (
 	Success ErrorCode = 0
 
 	// Client errors (1000-1999)
-	BadRequest    ErrorCode = 1000 // Invalid parameters or request
-	Unauthorized  ErrorCode = 1001 // Authentication required
-	Forbidden     ErrorCode = 1003 // Permission denied
-	NotFound      ErrorCode = 1004 // Resource not found
-	Validation    ErrorCode = 1005 // Validation error
-	Conflict      ErrorCode = 1009 // Resource conflict
-	TooManyReq    ErrorCode = 1029 // Too many requests
-	InvalidToken  ErrorCode = 1030 // Invalid token
-	ExpiredToken  ErrorCode = 1031 // Token expired
-	InvalidFormat ErrorCode = 1032 // Invalid data format
+	BadRequest       ErrorCode = 1000 // Invalid parameters or request
+	Unauthorized     ErrorCode = 1001 // Authentication required
+	Forbidden        ErrorCode = 1003 // Permission denied
+	NotFound         ErrorCode = 1004 // Resource not found
+	Validation       ErrorCode = 1005 // Validation error
+	Conflict         ErrorCode = 1009 // Resource conflict
+	TooManyReq       ErrorCode = 1029 // Too many requests
+	InvalidToken     ErrorCode = 1030 // Invalid token
+	ExpiredToken     ErrorCode = 1031 // Token expired
+	InvalidFormat    ErrorCode = 1032 // Invalid data format
+	EmailNotVerified ErrorCode = 1033 // Email address not verified
 
 	// Server errors (5000-5999)
 	InternalServer ErrorCode = 5000 // Internal server error
@@ -31,20 +32,21 @@ const (
 
 // Standard error messages
 var standardMessages = map[ErrorCode]string{
-	BadRequest:     "Invalid parameters",
-	Unauthorized:   "Authentication required",
-	Forbidden:      "Permission denied",
-	NotFound:       "Resource not found",
-	InternalServer: "Internal server error",
-	Validation:     "Validation error",
-	Database:       "Database error",
-	InvalidToken:   "Invalid token",
-	ExpiredToken:   "Token expired",
-	TooManyReq:     "Too many requests",
-	InvalidFormat:  "Invalid data format",
-	NotImplemented: "Feature not implemented",
-	ThirdParty:     "Third-party service error",
-	Config:         "Configuration error",
+	BadRequest:       "Invalid parameters",
+	Unauthorized:     "Authentication required",
+	Forbidden:        "Permission denied",
+	NotFound:         "Resource not found",
+	InternalServer:   "Internal server error",
+	Validation:       "Validation error",
+	Database:         "Database error",
+	InvalidToken:     "Invalid token",
+	ExpiredToken:     "Token expired",
+	TooManyReq:       "Too many requests",
+	InvalidFormat:    "Invalid data format",
+	EmailNotVerified: "Email address not verified",
+	NotImplemented:   "Feature not implemented",
+	ThirdParty:       "Third-party service error",
+	Config:           "Configuration error",
 }
 
 // GetStandardMessage returns the standard message for an error code
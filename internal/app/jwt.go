@@ -0,0 +1,212 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "goapp/internal/app/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenClaims are the claims carried by an access token
+type TokenClaims struct {
+	UserID    int64  `json:"uid"`
+	Username  string `json:"username"`
+	IsAdmin   bool   `json:"admin"`
+	SessionID string `json:"sid,omitempty"`
+	Purpose   string `json:"purpose,omitempty"` // empty for access tokens
+	jwt.RegisteredClaims
+}
+
+// PurposeMFAChallenge marks a token proving the first login step was passed
+const PurposeMFAChallenge = "mfa_challenge"
+
+// TokenManager signs and verifies access tokens
+type TokenManager struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	issuer    string
+	audience  string
+	ttl       time.Duration
+}
+
+// JWT is the global token manager
+var JWT *TokenManager
+
+// InitJWT initializes the token manager from the JWT configuration
+func InitJWT() {
+	manager, err := NewTokenManager(GetConfig().JWT)
+	if err != nil {
+		fmt.Printf("Failed to initialize JWT: %v\n", err)
+		panic(err)
+	}
+
+	JWT = manager
+	Info("JWT initialized successfully", "algorithm", GetConfig().JWT.Algorithm, "issuer", GetConfig().JWT.Issuer)
+}
+
+// NewTokenManager creates a TokenManager for the configured algorithm
+func NewTokenManager(cfg JWTConfig) (*TokenManager, error) {
+	manager := &TokenManager{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.AccessTokenTTL.Duration(),
+	}
+	if manager.ttl <= 0 {
+		manager.ttl = 15 * time.Minute
+	}
+
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", "HS256":
+		secret := []byte(cfg.Secret)
+		if len(secret) == 0 {
+			// Tokens signed with a random secret do not survive a restart,
+			// which is acceptable for local development only
+			buf := make([]byte, 32)
+			if _, err := rand.Read(buf); err != nil {
+				return nil, fmt.Errorf("error generating JWT secret: %w", err)
+			}
+			secret = []byte(hex.EncodeToString(buf))
+			Warn("No JWT secret configured, using a random secret")
+		}
+		manager.method = jwt.SigningMethodHS256
+		manager.signKey = secret
+		manager.verifyKey = secret
+	case "RS256":
+		privatePEM, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading JWT private key: %w", err)
+		}
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing JWT private key: %w", err)
+		}
+		manager.method = jwt.SigningMethodRS256
+		manager.signKey = privateKey
+		manager.verifyKey = &privateKey.PublicKey
+
+		if cfg.PublicKeyFile != "" {
+			publicPEM, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading JWT public key: %w", err)
+			}
+			publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing JWT public key: %w", err)
+			}
+			manager.verifyKey = publicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.Algorithm)
+	}
+
+	return manager, nil
+}
+
+// GenerateAccessToken issues a signed access token for a user's session
+func (m *TokenManager) GenerateAccessToken(userID int64, username string, isAdmin bool, sessionID string) (string, time.Time, error) {
+	token, expiresAt, err := m.sign(TokenClaims{
+		UserID:    userID,
+		Username:  username,
+		IsAdmin:   isAdmin,
+		SessionID: sessionID,
+	}, m.ttl)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error signing access token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// GenerateChallengeToken issues a short-lived token for completing an MFA login.
+// It is not accepted as an access token.
+func (m *TokenManager) GenerateChallengeToken(userID int64, ttl time.Duration) (string, time.Time, error) {
+	token, expiresAt, err := m.sign(TokenClaims{
+		UserID:  userID,
+		Purpose: PurposeMFAChallenge,
+	}, ttl)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error signing challenge token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// ParseAccessToken verifies the signature and claims of an access token
+func (m *TokenManager) ParseAccessToken(tokenString string) (*TokenClaims, *apperrors.AppError) {
+	claims, appErr := m.parse(tokenString)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if claims.Purpose != "" {
+		return nil, apperrors.New(apperrors.InvalidToken)
+	}
+	return claims, nil
+}
+
+// ParseChallengeToken verifies a token issued by GenerateChallengeToken
+func (m *TokenManager) ParseChallengeToken(tokenString string) (*TokenClaims, *apperrors.AppError) {
+	claims, appErr := m.parse(tokenString)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if claims.Purpose != PurposeMFAChallenge {
+		return nil, apperrors.New(apperrors.InvalidToken)
+	}
+	return claims, nil
+}
+
+// AccessTokenTTL returns the lifetime of issued access tokens
+func (m *TokenManager) AccessTokenTTL() time.Duration {
+	return m.ttl
+}
+
+// sign fills in the registered claims and signs the token
+func (m *TokenManager) sign(claims TokenClaims, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    m.issuer,
+		Subject:   strconv.FormatInt(claims.UserID, 10),
+		Audience:  jwt.ClaimStrings{m.audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	token, err := jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// parse verifies the signature and registered claims of a token
+func (m *TokenManager) parse(tokenString string) (*TokenClaims, *apperrors.AppError) {
+	claims := &TokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return m.verifyKey, nil
+	},
+		jwt.WithValidMethods([]string{m.method.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(m.audience),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, apperrors.New(apperrors.ExpiredToken)
+		}
+		return nil, apperrors.New(apperrors.InvalidToken)
+	}
+
+	if claims.UserID == 0 {
+		return nil, apperrors.NewError(apperrors.InvalidToken, "Token has no subject")
+	}
+	return claims, nil
+}
@@ -0,0 +1,192 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apperrors "goapp/internal/app/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testJWTConfig returns an HS256 configuration with a fixed secret
+func testJWTConfig() JWTConfig {
+	cfg := DefaultConfig().JWT
+	cfg.Secret = "test-secret"
+	return cfg
+}
+
+// newTestTokenManager creates a TokenManager or fails the test
+func newTestTokenManager(t *testing.T, cfg JWTConfig) *TokenManager {
+	t.Helper()
+	manager, err := NewTokenManager(cfg)
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	return manager
+}
+
+// writeRSAKey writes a fresh PEM encoded RSA private key and returns its path
+func writeRSAKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	rsaCfg := testJWTConfig()
+	rsaCfg.Algorithm = "RS256"
+	rsaCfg.PrivateKeyFile = writeRSAKey(t)
+
+	tests := []struct {
+		name string
+		cfg  JWTConfig
+		alg  string
+	}{
+		{"HS256", testJWTConfig(), "HS256"},
+		{"RS256", rsaCfg, "RS256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestTokenManager(t, tt.cfg)
+			token, expiresAt, err := manager.GenerateAccessToken(42, "alice", true, "session-1")
+			if err != nil {
+				t.Fatalf("GenerateAccessToken: %v", err)
+			}
+			if until := time.Until(expiresAt); until <= 0 || until > manager.AccessTokenTTL() {
+				t.Errorf("expires in %v, want within the %v TTL", until, manager.AccessTokenTTL())
+			}
+
+			claims, appErr := manager.ParseAccessToken(token)
+			if appErr != nil {
+				t.Fatalf("ParseAccessToken: %v", appErr)
+			}
+			if claims.UserID != 42 || claims.Username != "alice" || !claims.IsAdmin || claims.SessionID != "session-1" {
+				t.Errorf("claims = %+v", claims)
+			}
+			if claims.Subject != "42" || claims.Issuer != tt.cfg.Issuer {
+				t.Errorf("subject %q, issuer %q", claims.Subject, claims.Issuer)
+			}
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &TokenClaims{})
+			if err != nil || parsed.Method.Alg() != tt.alg {
+				t.Errorf("signed with %v, want %s", parsed.Method, tt.alg)
+			}
+		})
+	}
+}
+
+func TestParseAccessTokenErrors(t *testing.T) {
+	manager := newTestTokenManager(t, testJWTConfig())
+
+	otherSecret := testJWTConfig()
+	otherSecret.Secret = "another-secret"
+	otherIssuer := testJWTConfig()
+	otherIssuer.Issuer = "someone-else"
+	otherAudience := testJWTConfig()
+	otherAudience.Audience = "another-api"
+
+	sign := func(cfg JWTConfig, claims TokenClaims, ttl time.Duration) string {
+		token, _, err := newTestTokenManager(t, cfg).sign(claims, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	user := TokenClaims{UserID: 42, Username: "alice"}
+	challenge, _, err := manager.GenerateChallengeToken(42, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, TokenClaims{UserID: 42}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		code  apperrors.ErrorCode
+	}{
+		{"expired", sign(testJWTConfig(), user, -time.Minute), apperrors.ExpiredToken},
+		{"wrong secret", sign(otherSecret, user, time.Minute), apperrors.InvalidToken},
+		{"wrong issuer", sign(otherIssuer, user, time.Minute), apperrors.InvalidToken},
+		{"wrong audience", sign(otherAudience, user, time.Minute), apperrors.InvalidToken},
+		{"no subject", sign(testJWTConfig(), TokenClaims{Username: "alice"}, time.Minute), apperrors.InvalidToken},
+		{"challenge token", challenge, apperrors.InvalidToken},
+		{"alg none", unsigned, apperrors.InvalidToken},
+		{"garbage", "not.a.token", apperrors.InvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, appErr := manager.ParseAccessToken(tt.token)
+			if appErr == nil {
+				t.Fatalf("accepted with claims %+v", claims)
+			}
+			if appErr.Code != tt.code {
+				t.Errorf("code = %d, want %d", appErr.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestChallengeTokenIsNotAnAccessToken(t *testing.T) {
+	manager := newTestTokenManager(t, testJWTConfig())
+
+	challenge, _, err := manager.GenerateChallengeToken(42, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, appErr := manager.ParseChallengeToken(challenge)
+	if appErr != nil || claims.UserID != 42 {
+		t.Fatalf("ParseChallengeToken = %+v, %v", claims, appErr)
+	}
+
+	access, _, err := manager.GenerateAccessToken(42, "alice", false, "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, appErr := manager.ParseChallengeToken(access); appErr == nil || appErr.Code != apperrors.InvalidToken {
+		t.Errorf("access token accepted as a challenge: %v", appErr)
+	}
+}
+
+func TestNewTokenManagerRejectsBadConfig(t *testing.T) {
+	missingKey := testJWTConfig()
+	missingKey.Algorithm = "RS256"
+	missingKey.PrivateKeyFile = filepath.Join(t.TempDir(), "missing.pem")
+	unknown := testJWTConfig()
+	unknown.Algorithm = "ES256"
+
+	for name, cfg := range map[string]JWTConfig{"missing key": missingKey, "unknown algorithm": unknown} {
+		if _, err := NewTokenManager(cfg); err == nil {
+			t.Errorf("%s: NewTokenManager succeeded", name)
+		}
+	}
+
+	// Without a secret, tokens are signed with a random one
+	cfg := testJWTConfig()
+	cfg.Secret = ""
+	first := newTestTokenManager(t, cfg)
+	token, _, err := first.GenerateAccessToken(42, "alice", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, appErr := newTestTokenManager(t, cfg).ParseAccessToken(token); appErr == nil {
+		t.Error("two random secrets verified each other's tokens")
+	}
+}
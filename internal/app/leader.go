@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LeaderCallbacks are called as an instance gains and loses leadership
+type LeaderCallbacks struct {
+	// OnStartedLeading runs in its own goroutine after leadership is
+	// gained. Its context is cancelled when leadership ends, and it should
+	// return promptly then: the lock is only given up once it has.
+	OnStartedLeading func(ctx context.Context)
+	// OnStoppedLeading is called after leadership is lost or given up
+	OnStoppedLeading func()
+}
+
+// LeaderElection elects one of the instances sharing a Locker as the
+// leader, by holding a named lock. The others retry every half lease.
+type LeaderElection struct {
+	lock      *Lock
+	ttl       time.Duration
+	callbacks LeaderCallbacks
+	leader    atomic.Bool
+}
+
+// NewLeaderElection creates a LeaderElection for the named lock
+func NewLeaderElection(locker Locker, name string, ttl time.Duration, callbacks LeaderCallbacks) *LeaderElection {
+	return &LeaderElection{
+		lock:      NewLock(locker, name, ttl),
+		ttl:       ttl,
+		callbacks: callbacks,
+	}
+}
+
+// IsLeader reports whether this instance currently leads
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Token returns the fencing token of the current term
+func (e *LeaderElection) Token() int64 {
+	return e.lock.Token()
+}
+
+// Run campaigns for leadership until ctx is done, then gives up the lock
+func (e *LeaderElection) Run(ctx context.Context) {
+	for {
+		acquired, err := e.lock.TryLock()
+		if err != nil {
+			Warn("Leader election failed, retrying", "lock", e.lock.name, "error", err)
+		}
+		if acquired {
+			e.lead(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.ttl / 2):
+		}
+	}
+}
+
+// lead runs one term of leadership, until the lock is lost or ctx is done
+func (e *LeaderElection) lead(ctx context.Context) {
+	e.leader.Store(true)
+	Info("Leadership gained", "lock", e.lock.name, "token", e.lock.Token())
+
+	termCtx, cancel := context.WithCancel(ctx)
+	var term sync.WaitGroup
+	if e.callbacks.OnStartedLeading != nil {
+		term.Add(1)
+		go func() {
+			defer term.Done()
+			e.callbacks.OnStartedLeading(termCtx)
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-e.lock.Lost():
+	}
+
+	// The term's work stops before another instance can take over. The
+	// lease is renewed meanwhile, unless it is the one that was lost.
+	cancel()
+	term.Wait()
+	e.lock.Unlock()
+
+	e.leader.Store(false)
+	Info("Leadership ended", "lock", e.lock.name)
+	if e.callbacks.OnStoppedLeading != nil {
+		e.callbacks.OnStoppedLeading()
+	}
+}
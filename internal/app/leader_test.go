@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// candidate runs a LeaderElection and records its terms
+type candidate struct {
+	election *LeaderElection
+	cancel   context.CancelFunc
+	started  chan context.Context
+	stopped  chan struct{}
+	done     chan struct{}
+}
+
+func newCandidate(t *testing.T, locker Locker, ttl time.Duration) *candidate {
+	c := &candidate{
+		started: make(chan context.Context, 10),
+		stopped: make(chan struct{}, 10),
+		done:    make(chan struct{}),
+	}
+	c.election = NewLeaderElection(locker, "leader", ttl, LeaderCallbacks{
+		OnStartedLeading: func(ctx context.Context) { c.started <- ctx },
+		OnStoppedLeading: func() { c.stopped <- struct{}{} },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	go func() {
+		defer close(c.done)
+		c.election.Run(ctx)
+	}()
+	t.Cleanup(c.stop)
+	return c
+}
+
+// stop ends the campaign and waits for Run to return
+func (c *candidate) stop() {
+	c.cancel()
+	<-c.done
+}
+
+// term waits for the next term of c to start
+func (c *candidate) term(t *testing.T) context.Context {
+	t.Helper()
+	select {
+	case ctx := <-c.started:
+		return ctx
+	case <-time.After(time.Second):
+		t.Fatal("leadership not gained")
+		return nil
+	}
+}
+
+func TestLeaderElectionHandsOverOnShutdown(t *testing.T) {
+	for name, locker := range lockers(t) {
+		t.Run(name, func(t *testing.T) {
+			ttl := 60 * time.Millisecond
+			first := newCandidate(t, locker, ttl)
+			firstTerm := first.term(t)
+			if !first.election.IsLeader() {
+				t.Fatal("first candidate does not report leading")
+			}
+
+			second := newCandidate(t, locker, ttl)
+			time.Sleep(2 * ttl)
+			if second.election.IsLeader() {
+				t.Fatal("two leaders at once")
+			}
+
+			first.stop()
+			select {
+			case <-firstTerm.Done():
+			default:
+				t.Error("term context not cancelled when leadership ended")
+			}
+			select {
+			case <-first.stopped:
+			default:
+				t.Error("OnStoppedLeading not called")
+			}
+			if first.election.IsLeader() {
+				t.Error("stopped candidate still reports leading")
+			}
+
+			second.term(t)
+			if second.election.Token() <= first.election.Token() {
+				t.Errorf("new leader token = %d, want more than %d", second.election.Token(), first.election.Token())
+			}
+		})
+	}
+}
+
+func TestLeaderElectionHandsOverWhenLeaseIsLost(t *testing.T) {
+	locker := &flakyLocker{Locker: NewMemoryLocker()}
+	ttl := 60 * time.Millisecond
+	first := newCandidate(t, locker, ttl)
+	firstTerm := first.term(t)
+
+	// Renewals start failing, as if the leader were cut off from the
+	// store; its lease runs out and the other candidate takes over
+	second := newCandidate(t, locker.Locker, ttl)
+	locker.failing.Store(true)
+
+	select {
+	case <-firstTerm.Done():
+	case <-time.After(time.Second):
+		t.Fatal("term not ended after the lease was lost")
+	}
+	if !waitFor(t, time.Second, func() bool { return !first.election.IsLeader() }) {
+		t.Fatal("first candidate still reports leading")
+	}
+
+	second.term(t)
+	if second.election.Token() <= first.election.Token() {
+		t.Errorf("new leader token = %d, want more than %d", second.election.Token(), first.election.Token())
+	}
+}
+
+func TestLeaderElectionWaitsForTheTermToEnd(t *testing.T) {
+	for name, locker := range lockers(t) {
+		t.Run(name, func(t *testing.T) {
+			ttl := 60 * time.Millisecond
+			started := make(chan struct{})
+			var finished atomic.Bool
+			first := NewLeaderElection(locker, "leader", ttl, LeaderCallbacks{
+				OnStartedLeading: func(ctx context.Context) {
+					close(started)
+					<-ctx.Done()
+					// Winding down outlasts the lease, which must be kept meanwhile
+					time.Sleep(3 * ttl)
+					finished.Store(true)
+				},
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				first.Run(ctx)
+			}()
+			<-started
+
+			second := newCandidate(t, locker, ttl)
+			cancel()
+			second.term(t)
+			if !finished.Load() {
+				t.Error("next term started before the previous one finished")
+			}
+			<-done
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLockHeld    = errors.New("lock: held by another owner")
+	ErrLockNotHeld = errors.New("lock: not held by this owner")
+)
+
+// Locker is the storage of named locks. A lock is held by one owner until
+// its lease expires or the owner releases it. Every acquisition returns a
+// fencing token that is larger than the tokens of earlier acquisitions of
+// the same lock, so a resource can reject writes from an owner whose lease
+// has already passed to someone else.
+type Locker interface {
+	// Acquire takes a free lock for owner and returns its fencing token,
+	// or ErrLockHeld
+	Acquire(name, owner string, ttl time.Duration) (int64, error)
+	// Renew extends the lease of a lock owner still holds, or returns
+	// ErrLockNotHeld
+	Renew(name, owner string, ttl time.Duration) error
+	// Release frees a lock owner still holds, or returns ErrLockNotHeld
+	Release(name, owner string) error
+}
+
+// Locks is the global Locker
+var Locks Locker
+
+// InitLocker initializes the Locker selected in the configuration. A Redis
+// Locker falls back to memory when Redis is unavailable, in which case
+// locks only exclude work within this process.
+func InitLocker() {
+	cfg := GetConfig().Lock
+	locker, err := NewLocker(cfg, Redis)
+	if errors.Is(err, ErrRedisDisabled) {
+		Warn("Redis is unavailable, locks only apply within this process", "driver", cfg.Driver)
+		cfg.Driver = "memory"
+		locker, err = NewLocker(cfg, nil)
+	}
+	if err != nil {
+		fmt.Printf("Failed to initialize locks: %v\n", err)
+		panic(err)
+	}
+
+	Locks = locker
+	Info("Locks initialized successfully", "driver", cfg.Driver)
+}
+
+// NewLocker creates the Locker for the configured driver
+func NewLocker(cfg LockConfig, redis *RedisClient) (Locker, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return NewMemoryLocker(), nil
+	case "redis":
+		if !redis.IsEnabled() {
+			return nil, ErrRedisDisabled
+		}
+		return NewRedisLocker(redis, cfg.Prefix), nil
+	default:
+		return nil, fmt.Errorf("unsupported lock driver: %s", cfg.Driver)
+	}
+}
+
+// Lock is a lease on a named lock that renews itself while held:
+//
+//	lock := app.NewLock(app.Locks, "report", 15*time.Second)
+//	if err := lock.Lock(ctx); err != nil { ... }
+//	defer lock.Unlock()
+//
+// The lease is renewed every third of its TTL. If renewal fails for a whole
+// TTL, the lock is considered lost and Lost is closed; work guarded by it
+// should stop. A Lock can be taken again after Unlock or a loss.
+type Lock struct {
+	locker Locker
+	name   string
+	owner  string
+	ttl    time.Duration
+
+	mutex sync.Mutex
+	held  bool
+	token int64
+	lost  chan struct{}
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewLock creates a Lock with a unique owner ID
+func NewLock(locker Locker, name string, ttl time.Duration) *Lock {
+	return &Lock{
+		locker: locker,
+		name:   name,
+		owner:  uuid.New().String(),
+		ttl:    ttl,
+		lost:   make(chan struct{}),
+	}
+}
+
+// TryLock takes the lock if it is free and reports whether it did
+func (l *Lock) TryLock() (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.held {
+		return false, fmt.Errorf("lock %s is already held by this Lock", l.name)
+	}
+	token, err := l.locker.Acquire(l.name, l.owner, l.ttl)
+	if errors.Is(err, ErrLockHeld) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	l.held = true
+	l.token = token
+	l.lost = make(chan struct{})
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go l.renew(l.lost, l.stop, l.done)
+	return true, nil
+}
+
+// Lock waits until the lock is taken or ctx is done
+func (l *Lock) Lock(ctx context.Context) error {
+	retry := l.ttl / 10
+	if retry < 10*time.Millisecond {
+		retry = 10 * time.Millisecond
+	}
+	for {
+		acquired, err := l.TryLock()
+		if err != nil || acquired {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+// Unlock stops renewal and releases the lock. It returns ErrLockNotHeld if
+// the lease was lost in the meantime.
+func (l *Lock) Unlock() error {
+	l.mutex.Lock()
+	if !l.held {
+		l.mutex.Unlock()
+		return ErrLockNotHeld
+	}
+	l.held = false
+	stop, done := l.stop, l.done
+	l.mutex.Unlock()
+
+	close(stop)
+	<-done
+	return l.locker.Release(l.name, l.owner)
+}
+
+// Token returns the fencing token of the current lease
+func (l *Lock) Token() int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.token
+}
+
+// Lost returns a channel closed when the current lease is lost
+func (l *Lock) Lost() <-chan struct{} {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.lost
+}
+
+// renew extends the lease every third of the TTL until stopped. A lease
+// that another owner took is lost, and so is one that could not be renewed
+// for half the TTL: its holder is told while the lease still keeps other
+// instances out.
+func (l *Lock) renew(lost, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		// The new lease runs from no earlier than the request
+		attempt := time.Now()
+		err := l.locker.Renew(l.name, l.owner, l.ttl)
+		if err == nil {
+			renewed = attempt
+			continue
+		}
+		if !errors.Is(err, ErrLockNotHeld) && time.Since(renewed) < l.ttl/2 {
+			Warn("Lock renewal failed, retrying", "lock", l.name, "error", err)
+			continue
+		}
+
+		Warn("Lock lost", "lock", l.name, "token", l.Token(), "error", err)
+		l.mutex.Lock()
+		l.held = false
+		l.mutex.Unlock()
+		close(lost)
+		return
+	}
+}
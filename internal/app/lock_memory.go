@@ -0,0 +1,75 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryLocker implements Locker in process memory, for single-node use
+type MemoryLocker struct {
+	mutex  sync.Mutex
+	leases map[string]memoryLease
+	tokens map[string]int64
+}
+
+// memoryLease is the current holder of a lock
+type memoryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// NewMemoryLocker creates an empty MemoryLocker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		leases: make(map[string]memoryLease),
+		tokens: make(map[string]int64),
+	}
+}
+
+// Acquire takes the lock if it is free or its lease expired
+func (l *MemoryLocker) Acquire(name, owner string, ttl time.Duration) (int64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, held := l.lease(name, ""); held {
+		return 0, ErrLockHeld
+	}
+	l.leases[name] = memoryLease{owner: owner, expiresAt: time.Now().Add(ttl)}
+	l.tokens[name]++
+	return l.tokens[name], nil
+}
+
+// Renew extends the lease if owner still holds it
+func (l *MemoryLocker) Renew(name, owner string, ttl time.Duration) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, held := l.lease(name, owner); !held {
+		return ErrLockNotHeld
+	}
+	l.leases[name] = memoryLease{owner: owner, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release frees the lock if owner still holds it
+func (l *MemoryLocker) Release(name, owner string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, held := l.lease(name, owner); !held {
+		return ErrLockNotHeld
+	}
+	delete(l.leases, name)
+	return nil
+}
+
+// lease returns the live lease of a lock, held by owner unless owner is
+// empty. The caller holds the mutex.
+func (l *MemoryLocker) lease(name, owner string) (memoryLease, bool) {
+	lease, ok := l.leases[name]
+	if !ok || !time.Now().Before(lease.expiresAt) {
+		delete(l.leases, name)
+		return memoryLease{}, false
+	}
+	return lease, owner == "" || lease.owner == owner
+}
@@ -0,0 +1,86 @@
+package app
+
+import "time"
+
+var (
+	// lockAcquireScript sets the lock key to the owner if it is free and
+	// returns the next fencing token, or 0 when the lock is held
+	lockAcquireScript = NewRedisScript(`
+if redis.call("SET", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then
+	return redis.call("INCR", KEYS[2])
+end
+return 0`)
+
+	// lockRenewScript extends the lease if the owner still holds the lock
+	lockRenewScript = NewRedisScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	// lockReleaseScript deletes the lock if the owner still holds it
+	lockReleaseScript = NewRedisScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// RedisLocker implements Locker in Redis so locks are shared between
+// instances. The lock key holds the owner with the lease as its expiry; a
+// separate counter that never expires issues the fencing tokens. Both keys
+// share a hash tag so they live on the same cluster slot.
+type RedisLocker struct {
+	client *RedisClient
+	prefix string
+}
+
+// NewRedisLocker creates a RedisLocker using the given client
+func NewRedisLocker(client *RedisClient, prefix string) *RedisLocker {
+	return &RedisLocker{client: client, prefix: prefix}
+}
+
+// Acquire takes the lock if it is free
+func (l *RedisLocker) Acquire(name, owner string, ttl time.Duration) (int64, error) {
+	token, err := redisInt(lockAcquireScript.Run(l.client, []string{l.key(name), l.fenceKey(name)}, owner, ttl))
+	if err != nil {
+		return 0, err
+	}
+	if token == 0 {
+		return 0, ErrLockHeld
+	}
+	return token, nil
+}
+
+// Renew extends the lease if owner still holds the lock
+func (l *RedisLocker) Renew(name, owner string, ttl time.Duration) error {
+	return l.ownerChecked(lockRenewScript.Run(l.client, []string{l.key(name)}, owner, ttl))
+}
+
+// Release frees the lock if owner still holds it
+func (l *RedisLocker) Release(name, owner string) error {
+	return l.ownerChecked(lockReleaseScript.Run(l.client, []string{l.key(name)}, owner))
+}
+
+// ownerChecked turns the 0 reply of a script whose owner check failed into
+// ErrLockNotHeld
+func (l *RedisLocker) ownerChecked(reply interface{}, err error) error {
+	result, err := redisInt(reply, err)
+	if err != nil {
+		return err
+	}
+	if result == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// key returns the Redis key of a lock
+func (l *RedisLocker) key(name string) string {
+	return l.prefix + "{" + name + "}"
+}
+
+// fenceKey returns the Redis key of the fencing token counter of a lock
+func (l *RedisLocker) fenceKey(name string) string {
+	return l.key(name) + ":fence"
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func init() {
+	// The fake server runs the lock scripts as Go
+	registerFakeRedisScript(lockAcquireScript, func(s *FakeRedisServer, session *fakeRedisSession, keys, args []string) {
+		if s.lookup(session, keys[0]) != nil {
+			session.writeInt(0)
+			return
+		}
+		ttl, _ := strconv.ParseInt(args[1], 10, 64)
+		db := s.db(session)
+		db[keys[0]] = &fakeRedisEntry{kind: "string", str: args[0], expiresAt: time.Now().Add(time.Duration(ttl) * time.Millisecond)}
+		s.incrBy(session, keys[1], 1)
+	})
+	registerFakeRedisScript(lockRenewScript, func(s *FakeRedisServer, session *fakeRedisSession, keys, args []string) {
+		entry := s.lookup(session, keys[0])
+		if entry == nil || entry.kind != "string" || entry.str != args[0] {
+			session.writeInt(0)
+			return
+		}
+		ttl, _ := strconv.ParseInt(args[1], 10, 64)
+		entry.expiresAt = time.Now().Add(time.Duration(ttl) * time.Millisecond)
+		session.writeInt(1)
+	})
+	registerFakeRedisScript(lockReleaseScript, func(s *FakeRedisServer, session *fakeRedisSession, keys, args []string) {
+		entry := s.lookup(session, keys[0])
+		if entry == nil || entry.kind != "string" || entry.str != args[0] {
+			session.writeInt(0)
+			return
+		}
+		delete(s.db(session), keys[0])
+		session.writeInt(1)
+	})
+}
+
+func TestRedisLockerKeysShareHashTag(t *testing.T) {
+	locker := NewRedisLocker(nil, "lock:")
+	if got := locker.key("report"); got != "lock:{report}" {
+		t.Errorf("key = %q", got)
+	}
+	if got := locker.fenceKey("report"); got != "lock:{report}:fence" {
+		t.Errorf("fence key = %q", got)
+	}
+}
@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lockers returns a MemoryLocker and a RedisLocker backed by a fake server
+func lockers(t *testing.T) map[string]Locker {
+	_, cfg := newFakeRedis(t, "", "")
+	client := NewRedisClient(cfg)
+	t.Cleanup(func() { client.Close() })
+
+	return map[string]Locker{
+		"memory": NewMemoryLocker(),
+		"redis":  NewRedisLocker(client, "lock:"),
+	}
+}
+
+// waitFor polls cond until it holds or the timeout passes
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+func TestLockerOwnership(t *testing.T) {
+	for name, locker := range lockers(t) {
+		t.Run(name, func(t *testing.T) {
+			token, err := locker.Acquire("job", "a", time.Minute)
+			if err != nil {
+				t.Fatalf("acquire: %v", err)
+			}
+			if _, err := locker.Acquire("job", "b", time.Minute); !errors.Is(err, ErrLockHeld) {
+				t.Errorf("acquire held lock: err = %v, want ErrLockHeld", err)
+			}
+			if err := locker.Renew("job", "b", time.Minute); !errors.Is(err, ErrLockNotHeld) {
+				t.Errorf("renew by other owner: err = %v, want ErrLockNotHeld", err)
+			}
+			if err := locker.Release("job", "b"); !errors.Is(err, ErrLockNotHeld) {
+				t.Errorf("release by other owner: err = %v, want ErrLockNotHeld", err)
+			}
+			if err := locker.Renew("job", "a", time.Minute); err != nil {
+				t.Errorf("renew: %v", err)
+			}
+			if err := locker.Release("job", "a"); err != nil {
+				t.Fatalf("release: %v", err)
+			}
+			if err := locker.Release("job", "a"); !errors.Is(err, ErrLockNotHeld) {
+				t.Errorf("second release: err = %v, want ErrLockNotHeld", err)
+			}
+
+			next, err := locker.Acquire("job", "b", time.Minute)
+			if err != nil {
+				t.Fatalf("acquire after release: %v", err)
+			}
+			if next <= token {
+				t.Errorf("token after release = %d, want more than %d", next, token)
+			}
+			if other, err := locker.Acquire("other", "a", time.Minute); err != nil || other != 1 {
+				t.Errorf("acquire other lock = %d, %v; want its own token 1", other, err)
+			}
+		})
+	}
+}
+
+func TestLockerLeaseExpiry(t *testing.T) {
+	for name, locker := range lockers(t) {
+		t.Run(name, func(t *testing.T) {
+			ttl := 50 * time.Millisecond
+			token, err := locker.Acquire("job", "a", ttl)
+			if err != nil {
+				t.Fatalf("acquire: %v", err)
+			}
+
+			// A renewal moves the expiry forward
+			time.Sleep(ttl / 2)
+			if err := locker.Renew("job", "a", ttl); err != nil {
+				t.Fatalf("renew: %v", err)
+			}
+			time.Sleep(ttl / 2)
+			if _, err := locker.Acquire("job", "b", ttl); !errors.Is(err, ErrLockHeld) {
+				t.Fatalf("acquire renewed lock: err = %v, want ErrLockHeld", err)
+			}
+
+			time.Sleep(ttl)
+			next, err := locker.Acquire("job", "b", time.Minute)
+			if err != nil {
+				t.Fatalf("acquire expired lock: %v", err)
+			}
+			if next <= token {
+				t.Errorf("token after expiry = %d, want more than %d", next, token)
+			}
+			if err := locker.Renew("job", "a", ttl); !errors.Is(err, ErrLockNotHeld) {
+				t.Errorf("renew expired lease: err = %v, want ErrLockNotHeld", err)
+			}
+			if err := locker.Release("job", "a"); !errors.Is(err, ErrLockNotHeld) {
+				t.Errorf("release expired lease: err = %v, want ErrLockNotHeld", err)
+			}
+		})
+	}
+}
+
+func TestLockerFencingTokensIncrease(t *testing.T) {
+	for name, locker := range lockers(t) {
+		t.Run(name, func(t *testing.T) {
+			var last int64
+			for i := 0; i < 20; i++ {
+				token, err := locker.Acquire("job", "a", time.Minute)
+				if err != nil {
+					t.Fatalf("acquire %d: %v", i, err)
+				}
+				if token <= last {
+					t.Fatalf("token %d = %d, want more than %d", i, token, last)
+				}
+				last = token
+				if err := locker.Release("job", "a"); err != nil {
+					t.Fatalf("release %d: %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLockRenewsItsLease(t *testing.T) {
+	for name, locker := range lockers(t) {
+		t.Run(name, func(t *testing.T) {
+			ttl := 60 * time.Millisecond
+			lock := NewLock(locker, "job", ttl)
+			if acquired, err := lock.TryLock(); err != nil || !acquired {
+				t.Fatalf("TryLock = %v, %v", acquired, err)
+			}
+
+			time.Sleep(3 * ttl)
+			if acquired, err := NewLock(locker, "job", ttl).TryLock(); err != nil || acquired {
+				t.Errorf("other TryLock after %v = %v, %v; want the lease renewed", 3*ttl, acquired, err)
+			}
+			select {
+			case <-lock.Lost():
+				t.Error("lease lost while renewing")
+			default:
+			}
+
+			if err := lock.Unlock(); err != nil {
+				t.Fatalf("Unlock: %v", err)
+			}
+			if err := lock.Unlock(); !errors.Is(err, ErrLockNotHeld) {
+				t.Errorf("second Unlock: err = %v, want ErrLockNotHeld", err)
+			}
+			if acquired, err := NewLock(locker, "job", ttl).TryLock(); err != nil || !acquired {
+				t.Errorf("other TryLock after Unlock = %v, %v", acquired, err)
+			}
+		})
+	}
+}
+
+func TestLockWaitsForRelease(t *testing.T) {
+	locker := NewMemoryLocker()
+	ttl := 200 * time.Millisecond
+	holder := NewLock(locker, "job", ttl)
+	if acquired, err := holder.TryLock(); err != nil || !acquired {
+		t.Fatalf("TryLock = %v, %v", acquired, err)
+	}
+
+	waiter := NewLock(locker, "job", ttl)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := waiter.Lock(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Lock on held lock: err = %v, want DeadlineExceeded", err)
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- waiter.Lock(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	if err := holder.Unlock(); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("Lock: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Lock did not return after the holder unlocked")
+	}
+	if waiter.Token() <= holder.Token() {
+		t.Errorf("waiter token = %d, want more than %d", waiter.Token(), holder.Token())
+	}
+	waiter.Unlock()
+}
+
+func TestLockLostToAnotherOwner(t *testing.T) {
+	locker := NewMemoryLocker()
+	ttl := 60 * time.Millisecond
+	lock := NewLock(locker, "job", ttl)
+	if acquired, err := lock.TryLock(); err != nil || !acquired {
+		t.Fatalf("TryLock = %v, %v", acquired, err)
+	}
+
+	// Another owner takes over once the lease is gone
+	locker.Release("job", lock.owner)
+	if _, err := locker.Acquire("job", "other", time.Minute); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost not closed after another owner took the lock")
+	}
+	if err := lock.Unlock(); !errors.Is(err, ErrLockNotHeld) {
+		t.Errorf("Unlock after loss: err = %v, want ErrLockNotHeld", err)
+	}
+}
+
+// flakyLocker fails renewals while failing is set, as if the store were
+// unreachable
+type flakyLocker struct {
+	Locker
+	failing atomic.Bool
+}
+
+func (l *flakyLocker) Renew(name, owner string, ttl time.Duration) error {
+	if l.failing.Load() {
+		return errors.New("connection refused")
+	}
+	return l.Locker.Renew(name, owner, ttl)
+}
+
+func TestLockLostWhenRenewalsFail(t *testing.T) {
+	locker := &flakyLocker{Locker: NewMemoryLocker()}
+	ttl := 60 * time.Millisecond
+	lock := NewLock(locker, "job", ttl)
+	if acquired, err := lock.TryLock(); err != nil || !acquired {
+		t.Fatalf("TryLock = %v, %v", acquired, err)
+	}
+
+	// A failed renewal is retried while the lease lasts
+	locker.failing.Store(true)
+	time.Sleep(ttl / 2)
+	locker.failing.Store(false)
+	time.Sleep(ttl)
+	select {
+	case <-lock.Lost():
+		t.Fatal("lease lost after a single failed renewal")
+	default:
+	}
+
+	locker.failing.Store(true)
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost not closed after renewals failed for half the TTL")
+	}
+}
+
+func TestLockLostBeforeLeaseExpires(t *testing.T) {
+	locker := &flakyLocker{Locker: NewMemoryLocker()}
+	ttl := 300 * time.Millisecond
+	lock := NewLock(locker, "job", ttl)
+	if acquired, err := lock.TryLock(); err != nil || !acquired {
+		t.Fatalf("TryLock = %v, %v", acquired, err)
+	}
+
+	locker.failing.Store(true)
+	select {
+	case <-lock.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("Lost not closed after renewals failed")
+	}
+
+	// The holder hears of the loss while nobody else can take the lock yet
+	if _, err := locker.Acquire("job", "other", ttl); !errors.Is(err, ErrLockHeld) {
+		t.Errorf("Acquire right after the loss = %v, want ErrLockHeld", err)
+	}
+	if !waitFor(t, time.Second, func() bool {
+		_, err := locker.Acquire("job", "other", ttl)
+		return err == nil
+	}) {
+		t.Error("lease never expired")
+	}
+}
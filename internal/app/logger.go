@@ -1,12 +1,17 @@
 package app
 
 import (
+	stdcontext "context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"goapp/internal/context"
 
@@ -17,36 +22,171 @@ var (
 	logger *Logger
 )
 
-// Logger represents our custom logger
+// redactedText replaces secret values in log output
+const redactedText = "[REDACTED]"
+
+// minRedactLength keeps very short values from blanking out unrelated text
+const minRedactLength = 4
+
+// sensitiveLogKeys are argument names whose values are never logged
+var sensitiveLogKeys = map[string]bool{
+	"password":      true,
+	"secret":        true,
+	"client_secret": true,
+	"master_key":    true,
+	"api_key":       true,
+	"authorization": true,
+	"access_token":  true,
+	"refresh_token": true,
+}
+
+var (
+	redactMutex    sync.RWMutex
+	redactedValues = make(map[string]bool)
+	redactor       *strings.Replacer
+)
+
+// RedactValue makes sure a secret never appears in log output
+func RedactValue(value string) {
+	if len(value) < minRedactLength {
+		return
+	}
+
+	redactMutex.Lock()
+	defer redactMutex.Unlock()
+
+	if redactedValues[value] {
+		return
+	}
+	redactedValues[value] = true
+
+	pairs := make([]string, 0, len(redactedValues)*2)
+	for secret := range redactedValues {
+		pairs = append(pairs, secret, redactedText)
+	}
+	redactor = strings.NewReplacer(pairs...)
+}
+
+// redact replaces registered secret values in a log line
+func redact(line string) string {
+	redactMutex.RLock()
+	defer redactMutex.RUnlock()
+
+	if redactor == nil {
+		return line
+	}
+	return redactor.Replace(line)
+}
+
+// logLevels maps the configured log levels to slog levels
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+// configLevel is a slog.Leveler that reads the configured log level on
+// every call, so a reload applies at once
+type configLevel struct{}
+
+// Level implements slog.Leveler
+func (configLevel) Level() slog.Level {
+	level, exists := logLevels[GetConfig().Log.Level]
+	if !exists {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// Logger writes structured log records through a slog.Handler
 type Logger struct {
-	debugLogger *log.Logger
-	infoLogger  *log.Logger
-	warnLogger  *log.Logger
-	errorLogger *log.Logger
+	handler slog.Handler
 }
 
-// InitLogger initializes the logger
+// InitLogger initializes the logger with the configured format and output
 func InitLogger() {
-	logFile := ConfigData.Log.Filename
+	cfg := GetConfig().Log
+	output, err := openLogOutput(cfg)
+	if err != nil {
+		log.Fatalf("Failed to open log output: %v", err)
+	}
+	logger = NewLogger(cfg.Format, output)
+}
+
+// NewLogger creates a Logger writing json, logfmt or console records to w
+func NewLogger(format string, w io.Writer) *Logger {
+	w = redactWriter{w}
+	options := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       configLevel{},
+		ReplaceAttr: replaceLogAttr,
+	}
+
+	var handler slog.Handler
+	switch format {
+	case "logfmt":
+		handler = slog.NewTextHandler(w, options)
+	case "console":
+		handler = newConsoleHandler(w, configLevel{}, isTerminal(w))
+	default:
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return &Logger{handler: handler}
+}
+
+// GetLogger returns the logger instance
+func GetLogger() *Logger {
+	return logger
+}
+
+// Handler returns the slog.Handler behind the logger, for code that logs
+// through log/slog directly
+func (l *Logger) Handler() slog.Handler {
+	return l.handler
+}
+
+// openLogOutput opens the configured log output
+func openLogOutput(cfg LogConfig) (io.Writer, error) {
+	switch cfg.Output {
+	case "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
 
 	// Create logs directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
-		log.Fatalf("Failed to create log directory: %v", err)
+	if err := os.MkdirAll(filepath.Dir(cfg.Filename), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
+	return os.OpenFile(cfg.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+}
 
-	// Open log file
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatalf("Failed to open log file: %v", err)
+// redactWriter replaces registered secret values in every record written.
+// Handlers write each record in a single call, so a secret is never split.
+type redactWriter struct {
+	w io.Writer
+}
+
+// Write implements io.Writer
+func (r redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, redact(string(p))); err != nil {
+		return 0, err
 	}
+	return len(p), nil
+}
 
-	// Create loggers for different levels
-	logger = &Logger{
-		debugLogger: log.New(file, "DEBUG: ", log.Ldate|log.Ltime),
-		infoLogger:  log.New(file, "INFO:  ", log.Ldate|log.Ltime),
-		warnLogger:  log.New(file, "WARN:  ", log.Ldate|log.Ltime),
-		errorLogger: log.New(file, "ERROR: ", log.Ldate|log.Ltime),
+// replaceLogAttr renders the source of a record as a short "caller"
+// attribute and drops it when the record has none
+func replaceLogAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) > 0 || attr.Key != slog.SourceKey {
+		return attr
+	}
+	source, ok := attr.Value.Any().(*slog.Source)
+	if !ok || source.File == "" {
+		return slog.Attr{}
 	}
+	return slog.String("caller", fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line))
 }
 
 // GetTraceID retrieves the trace ID from the context
@@ -61,111 +201,130 @@ func GetTraceID(args ...interface{}) string {
 	return "-"
 }
 
-// formatMessage formats a log message with caller information and arguments
-func formatMessage(msg string, args ...interface{}) string {
-	// Get caller information
-	_, file, line, _ := runtime.Caller(2)
-	file = filepath.Base(file)
-
-	// Get trace ID
-	traceID := GetTraceID(args...)
-
-	// Format the message
-	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("[%s:%d][%s] ", file, line, traceID))
-	builder.WriteString(msg)
-
-	// Add key-value pairs if any
-	if len(args) > 0 {
-		builder.WriteString(" |")
-		for i := 0; i < len(args); i += 2 {
-			if i+1 < len(args) {
-				// Skip trace_id as it's already included in the prefix
-				if args[i] != "trace_id" {
-					builder.WriteString(fmt.Sprintf(" %v=%v", args[i], args[i+1]))
-				}
-			}
+// logAttrs turns key-value pairs into typed attributes. Values of sensitive
+// keys are redacted, and a trailing key without a value is dropped.
+func logAttrs(args []interface{}) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		key, ok := args[i].(string)
+		if !ok {
+			key = fmt.Sprint(args[i])
+		}
+		if sensitiveLogKeys[strings.ToLower(key)] {
+			attrs = append(attrs, slog.String(key, redactedText))
+			continue
 		}
+		attrs = append(attrs, slog.Attr{Key: key, Value: logValue(args[i+1])})
+	}
+	return attrs
+}
+
+// logValue converts a logged value, keeping numbers and booleans typed.
+// Strings and errors are redacted before encoding, since escaping could
+// hide a secret from the writer.
+func logValue(value interface{}) slog.Value {
+	switch v := value.(type) {
+	case string:
+		return slog.StringValue(redact(v))
+	case error:
+		return slog.StringValue(redact(v.Error()))
+	case time.Duration:
+		return slog.StringValue(v.String())
+	case fmt.Stringer:
+		return slog.StringValue(redact(v.String()))
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+// logAt writes a record for the caller of the public logging function.
+// skip counts the frames above logAt to that caller.
+func logAt(level slog.Level, skip int, msg string, args []interface{}) {
+	if !logger.handler.Enabled(stdcontext.Background(), level) {
+		return
 	}
 
-	return builder.String()
+	var pcs [1]uintptr
+	runtime.Callers(skip+2, pcs[:])
+	logRecord(level, pcs[0], msg, args)
+}
+
+// logRecord writes a record with the caller at pc; 0 records no caller
+func logRecord(level slog.Level, pc uintptr, msg string, args []interface{}) {
+	if !logger.handler.Enabled(stdcontext.Background(), level) {
+		return
+	}
+
+	record := slog.NewRecord(time.Now(), level, redact(msg), pc)
+	record.AddAttrs(logAttrs(args)...)
+	logger.handler.Handle(stdcontext.Background(), record)
 }
 
 // Debug logs a debug message
 func Debug(msg string, args ...interface{}) {
-	if ConfigData.Log.Level == "debug" {
-		logger.debugLogger.Println(formatMessage(msg, args...))
-	}
+	logAt(slog.LevelDebug, 1, msg, args)
 }
 
 // Debugf logs a formatted debug message
 func Debugf(format string, args ...interface{}) {
-	if ConfigData.Log.Level == "debug" {
-		logger.debugLogger.Println(formatMessage(fmt.Sprintf(format, args...)))
-	}
+	logAt(slog.LevelDebug, 1, fmt.Sprintf(format, args...), nil)
 }
 
 // Info logs an info message
 func Info(msg string, args ...interface{}) {
-	logger.infoLogger.Println(formatMessage(msg, args...))
+	logAt(slog.LevelInfo, 1, msg, args)
 }
 
 // Infof logs a formatted info message
 func Infof(format string, args ...interface{}) {
-	logger.infoLogger.Println(formatMessage(fmt.Sprintf(format, args...)))
+	logAt(slog.LevelInfo, 1, fmt.Sprintf(format, args...), nil)
 }
 
 // Warn logs a warning message
 func Warn(msg string, args ...interface{}) {
-	logger.warnLogger.Println(formatMessage(msg, args...))
+	logAt(slog.LevelWarn, 1, msg, args)
 }
 
 // Warnf logs a formatted warning message
 func Warnf(format string, args ...interface{}) {
-	logger.warnLogger.Println(formatMessage(fmt.Sprintf(format, args...)))
+	logAt(slog.LevelWarn, 1, fmt.Sprintf(format, args...), nil)
 }
 
 // Error logs an error message
 func Error(msg string, args ...interface{}) {
-	logger.errorLogger.Println(formatMessage(msg, args...))
+	logAt(slog.LevelError, 1, msg, args)
 }
 
 // Errorf logs a formatted error message
 func Errorf(format string, args ...interface{}) {
-	logger.errorLogger.Println(formatMessage(fmt.Sprintf(format, args...)))
+	logAt(slog.LevelError, 1, fmt.Sprintf(format, args...), nil)
 }
 
 // Log is an alias for Info for backward compatibility
 func Log(msg string, args ...interface{}) {
-	Info(msg, args...)
+	logAt(slog.LevelInfo, 1, msg, args)
 }
 
 // Context-aware logging functions
 
 // DebugContext logs a debug message with context
 func DebugContext(ctx *gin.Context, msg string, args ...interface{}) {
-	if ConfigData.Log.Level == "debug" {
-		newArgs := appendRequestID(ctx, args...)
-		logger.debugLogger.Println(formatMessage(msg, newArgs...))
-	}
+	logAt(slog.LevelDebug, 1, msg, appendRequestID(ctx, args...))
 }
 
 // InfoContext logs an info message with context
 func InfoContext(ctx *gin.Context, msg string, args ...interface{}) {
-	newArgs := appendRequestID(ctx, args...)
-	logger.infoLogger.Println(formatMessage(msg, newArgs...))
+	logAt(slog.LevelInfo, 1, msg, appendRequestID(ctx, args...))
 }
 
 // WarnContext logs a warning message with context
 func WarnContext(ctx *gin.Context, msg string, args ...interface{}) {
-	newArgs := appendRequestID(ctx, args...)
-	logger.warnLogger.Println(formatMessage(msg, newArgs...))
+	logAt(slog.LevelWarn, 1, msg, appendRequestID(ctx, args...))
 }
 
 // ErrorContext logs an error message with context
 func ErrorContext(ctx *gin.Context, msg string, args ...interface{}) {
-	newArgs := appendRequestID(ctx, args...)
-	logger.errorLogger.Println(formatMessage(msg, newArgs...))
+	logAt(slog.LevelError, 1, msg, appendRequestID(ctx, args...))
 }
 
 // appendRequestID adds the request ID from context to the args
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// consoleLevelColors are the ANSI colors of the level labels
+var consoleLevelColors = map[slog.Level]string{
+	slog.LevelDebug: "\033[90m",
+	slog.LevelInfo:  "\033[36m",
+	slog.LevelWarn:  "\033[33m",
+	slog.LevelError: "\033[31m",
+}
+
+const (
+	consoleDim   = "\033[2m"
+	consoleReset = "\033[0m"
+)
+
+// consoleHandler is a slog.Handler that pretty-prints records for reading
+// in a terminal during development:
+//
+//	15:04:05.000 INFO  User logged in  user_id=42 trace_id=abc  auth.go:31
+type consoleHandler struct {
+	mutex  *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	color  bool
+	attrs  []slog.Attr
+	prefix string
+}
+
+// newConsoleHandler creates a consoleHandler, colored if color is set
+func newConsoleHandler(w io.Writer, level slog.Leveler, color bool) *consoleHandler {
+	return &consoleHandler{mutex: &sync.Mutex{}, w: w, level: level, color: color}
+}
+
+// Enabled implements slog.Handler
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// WithAttrs implements slog.Handler
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, attr := range attrs {
+		clone.attrs = append(clone.attrs, slog.Attr{Key: h.prefix + attr.Key, Value: attr.Value})
+	}
+	return &clone
+}
+
+// WithGroup implements slog.Handler
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// Handle implements slog.Handler
+func (h *consoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var line strings.Builder
+	line.WriteString(h.paint(consoleDim, record.Time.Format("15:04:05.000")))
+	line.WriteByte(' ')
+	line.WriteString(h.paint(consoleLevelColors[record.Level], fmt.Sprintf("%-5s", record.Level.String())))
+	line.WriteByte(' ')
+	line.WriteString(record.Message)
+
+	fields := make([]string, 0, len(h.attrs)+record.NumAttrs())
+	for _, attr := range h.attrs {
+		fields = h.appendAttr(fields, "", attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = h.appendAttr(fields, h.prefix, attr)
+		return true
+	})
+	if len(fields) > 0 {
+		line.WriteString("  ")
+		line.WriteString(strings.Join(fields, " "))
+	}
+
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		line.WriteString("  ")
+		line.WriteString(h.paint(consoleDim, fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)))
+	}
+	line.WriteByte('\n')
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, err := io.WriteString(h.w, line.String())
+	return err
+}
+
+// appendAttr formats an attribute as key=value, flattening groups
+func (h *consoleHandler) appendAttr(fields []string, prefix string, attr slog.Attr) []string {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range value.Group() {
+			fields = h.appendAttr(fields, prefix, member)
+		}
+		return fields
+	}
+	if attr.Key == "" {
+		return fields
+	}
+	return append(fields, h.paint(consoleDim, prefix+attr.Key+"=")+consoleValue(value))
+}
+
+// paint wraps text in an ANSI color when colors are enabled
+func (h *consoleHandler) paint(color, text string) string {
+	if !h.color || color == "" {
+		return text
+	}
+	return color + text + consoleReset
+}
+
+// consoleValue formats a value, quoting strings that need it
+func consoleValue(value slog.Value) string {
+	switch value.Kind() {
+	case slog.KindString:
+		text := value.String()
+		if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+			return strconv.Quote(text)
+		}
+		return text
+	case slog.KindTime:
+		return value.Time().Format(time.RFC3339)
+	default:
+		return fmt.Sprint(value.Any())
+	}
+}
+
+// isTerminal reports whether w is a terminal, where colors can be used
+func isTerminal(w io.Writer) bool {
+	if redacted, ok := w.(redactWriter); ok {
+		w = redacted.w
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,17 @@
+package app
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+// TestMain installs the default configuration and a discarding logger,
+// which most of the package expects to be set up by main
+func TestMain(m *testing.M) {
+	cfg := DefaultConfig()
+	cfg.Server.Mode = "test"
+	SetConfig(&cfg)
+	logger = NewLogger("json", io.Discard)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is a message addressed to a single recipient
+type Notification struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Send(notification *Notification) error
+}
+
+// LogNotifier records notifications in the application log instead of delivering them
+type LogNotifier struct {
+	from string
+}
+
+// FileNotifier spools each notification as a file for inspection or later delivery
+type FileNotifier struct {
+	from string
+	dir  string
+}
+
+// Mailer is the global notifier
+var Mailer Notifier
+
+// InitNotifier initializes the notifier selected in the configuration
+func InitNotifier() {
+	notifier, err := NewNotifier(GetConfig().Notifier)
+	if err != nil {
+		fmt.Printf("Failed to initialize notifier: %v\n", err)
+		panic(err)
+	}
+
+	Mailer = notifier
+	Info("Notifier initialized successfully", "driver", GetConfig().Notifier.Driver)
+}
+
+// NewNotifier creates the Notifier for the configured driver
+func NewNotifier(cfg NotifierConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case "", "log":
+		return &LogNotifier{from: cfg.From}, nil
+	case "file":
+		if err := os.MkdirAll(cfg.SpoolDir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating spool directory: %w", err)
+		}
+		return &FileNotifier{from: cfg.From, dir: cfg.SpoolDir}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notifier driver: %s", cfg.Driver)
+	}
+}
+
+// Send logs the recipient and subject of the notification. The body is
+// left out since it may carry reset or verification tokens; use the file
+// driver to read what would be delivered.
+func (n *LogNotifier) Send(notification *Notification) error {
+	Info("Notification",
+		"from", n.from,
+		"to", notification.To,
+		"subject", notification.Subject,
+	)
+	return nil
+}
+
+// Send writes the notification to a new file in the spool directory
+func (n *FileNotifier) Send(notification *Notification) error {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("From: %s\r\n", n.from))
+	builder.WriteString(fmt.Sprintf("To: %s\r\n", notification.To))
+	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", notification.Subject))
+	builder.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	builder.WriteString("\r\n")
+	builder.WriteString(notification.Body)
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), uuid.New().String())
+	if err := os.WriteFile(filepath.Join(n.dir, name), []byte(builder.String()), 0600); err != nil {
+		return fmt.Errorf("error spooling notification: %w", err)
+	}
+	return nil
+}
@@ -1,105 +1,650 @@
 package app
 
 import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
-// RedisClient is a mock Redis client
+var (
+	ErrRedisDisabled    = errors.New("redis is not enabled")
+	ErrRedisClosed      = errors.New("redis: client is closed")
+	ErrRedisPoolTimeout = errors.New("redis: timed out waiting for a free connection")
+)
+
+// RedisClient is a pooled Redis client speaking RESP2, or RESP3 when
+// redis.protocol is 3. It is safe for concurrent use.
 type RedisClient struct {
-	host     string
-	port     int
-	password string
-	db       int
-	enabled  bool
+	cfg     RedisConfig
+	addr    string
+	enabled bool
+
+	// slots limits the number of open connections to pool_size
+	slots chan struct{}
+
+	mutex  sync.Mutex
+	idle   []*redisConn
+	closed bool
+}
+
+// redisConn is one connection of the pool
+type redisConn struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	writer   *bufio.Writer
+	lastUsed time.Time
+}
+
+// ZMember is a member of a sorted set with its score
+type ZMember struct {
+	Member string
+	Score  float64
 }
 
 // Redis is the global Redis client
 var Redis *RedisClient
 
-// InitRedis initializes the Redis connection
+// InitRedis connects to the configured Redis server
 func InitRedis() {
-	// Default Redis configuration
-	host := ConfigData.Redis.Host
-	port := ConfigData.Redis.Port
-	password := ConfigData.Redis.Password
-	db := ConfigData.Redis.DB
+	cfg := GetConfig().Redis
+	client := NewRedisClient(cfg)
+	if err := client.Ping(); err != nil {
+		client.Close()
+		fmt.Printf("Failed to connect to Redis: %v\n", err)
+		panic(err)
+	}
+	Redis = client
+
+	fmt.Printf("Redis initialized successfully (host=%s, port=%d)\n", cfg.Host, cfg.Port)
+	Info("Redis initialized successfully", "host", cfg.Host, "port", cfg.Port, "db", cfg.DB, "protocol", cfg.Protocol)
+}
 
-	Redis = &RedisClient{
-		host:     host,
-		port:     port,
-		password: password,
-		db:       db,
-		enabled:  true,
+// NewRedisClient creates a client for the given configuration. Connections
+// are opened on first use.
+func NewRedisClient(cfg RedisConfig) *RedisClient {
+	poolSize := cfg.PoolSize
+	if poolSize < 1 {
+		poolSize = 1
+	}
+	return &RedisClient{
+		cfg:     cfg,
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		enabled: true,
+		slots:   make(chan struct{}, poolSize),
 	}
+}
+
+// IsEnabled returns whether Redis is enabled
+func (r *RedisClient) IsEnabled() bool {
+	return r != nil && r.enabled
+}
 
-	// In a real application, you would connect to Redis here
-	// For now, just log that Redis is "connected"
-	fmt.Printf("Redis initialized successfully (host=%s, port=%d)\n", host, port)
-	Info("Redis initialized successfully", "host", host, "port", port)
+// Do sends a single command and returns its reply. Error replies from the
+// server are returned as RedisError.
+func (r *RedisClient) Do(args ...interface{}) (interface{}, error) {
+	replies, err := r.exec([][]interface{}{args})
+	if err != nil {
+		return nil, err
+	}
+	if redisErr, ok := replies[0].(RedisError); ok {
+		return nil, redisErr
+	}
+	return replies[0], nil
+}
+
+// Ping checks the connection to the server
+func (r *RedisClient) Ping() error {
+	return redisOK(r.Do("PING"))
 }
 
 // Set stores a key-value pair in Redis with an optional expiration time
 func (r *RedisClient) Set(key string, value string, expiration time.Duration) error {
-	if !r.enabled {
-		return fmt.Errorf("redis is not enabled")
+	if expiration > 0 {
+		return redisOK(r.Do("SET", key, value, "PX", expiration))
 	}
+	return redisOK(r.Do("SET", key, value))
+}
 
-	fmt.Printf("MOCK REDIS: Set %s=%s with expiration %v\n", key, value, expiration)
-	return nil
+// SetNX stores a key only if it does not exist and reports whether it did
+func (r *RedisClient) SetNX(key string, value string, expiration time.Duration) (bool, error) {
+	args := []interface{}{"SET", key, value, "NX"}
+	if expiration > 0 {
+		args = append(args, "PX", expiration)
+	}
+	reply, err := r.Do(args...)
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
 }
 
-// Get retrieves a value from Redis by key
+// Get retrieves a value from Redis by key, or ErrRedisNil if it is missing
 func (r *RedisClient) Get(key string) (string, error) {
-	if !r.enabled {
-		return "", fmt.Errorf("redis is not enabled")
+	return redisString(r.Do("GET", key))
+}
+
+// Delete removes keys from Redis
+func (r *RedisClient) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
 	}
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	_, err := redisInt(r.Do(args...))
+	return err
+}
+
+// GetTTL gets the TTL of a key. It is negative when the key does not exist
+// (-2) or has no expiry (-1).
+func (r *RedisClient) GetTTL(key string) (time.Duration, error) {
+	ms, err := redisInt(r.Do("PTTL", key))
+	if err != nil {
+		return 0, err
+	}
+	if ms < 0 {
+		return time.Duration(ms), nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Incr increments the integer value of a key by one
+func (r *RedisClient) Incr(key string) (int64, error) {
+	return redisInt(r.Do("INCR", key))
+}
 
-	fmt.Printf("MOCK REDIS: Get %s\n", key)
-	return "mock-value-for-" + key, nil
+// IncrBy increments the integer value of a key by delta
+func (r *RedisClient) IncrBy(key string, delta int64) (int64, error) {
+	return redisInt(r.Do("INCRBY", key, delta))
 }
 
-// Delete removes a key from Redis
-func (r *RedisClient) Delete(key string) error {
-	if !r.enabled {
-		return fmt.Errorf("redis is not enabled")
+// Expire sets a timeout on a key
+func (r *RedisClient) Expire(key string, expiration time.Duration) error {
+	_, err := redisInt(r.Do("PEXPIRE", key, expiration))
+	return err
+}
+
+// Scan returns a page of keys matching the glob pattern and the cursor of
+// the next page, which is 0 once the iteration is complete
+func (r *RedisClient) Scan(cursor uint64, match string, count int64) ([]string, uint64, error) {
+	reply, err := redisArray(r.Do("SCAN", strconv.FormatUint(cursor, 10), "MATCH", match, "COUNT", count))
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(reply) != 2 {
+		return nil, 0, fmt.Errorf("redis: unexpected SCAN reply of %d items", len(reply))
 	}
 
-	fmt.Printf("MOCK REDIS: Delete %s\n", key)
-	return nil
+	next, err := redisString(reply[0], nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	nextCursor, err := strconv.ParseUint(next, 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("redis: invalid SCAN cursor %q", next)
+	}
+	items, err := redisArray(reply[1], nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		key, err := redisString(item, nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nextCursor, nil
 }
 
-// IsEnabled returns whether Redis is enabled
-func (r *RedisClient) IsEnabled() bool {
-	return r != nil && r.enabled
+// RedisScript is a Lua script run with EVALSHA, falling back to EVAL when
+// the server has not cached it yet
+type RedisScript struct {
+	source string
+	sha    string
 }
 
-// GetTTL gets the TTL of a key
-func (r *RedisClient) GetTTL(key string) (time.Duration, error) {
-	if !r.enabled {
-		return 0, fmt.Errorf("redis is not enabled")
+// NewRedisScript creates a RedisScript from its source
+func NewRedisScript(source string) *RedisScript {
+	sum := sha1.Sum([]byte(source))
+	return &RedisScript{source: source, sha: hex.EncodeToString(sum[:])}
+}
+
+// Run runs the script with the given keys and arguments
+func (s *RedisScript) Run(client *RedisClient, keys []string, args ...interface{}) (interface{}, error) {
+	command := make([]interface{}, 0, 3+len(keys)+len(args))
+	command = append(command, "EVALSHA", s.sha, len(keys))
+	for _, key := range keys {
+		command = append(command, key)
 	}
+	command = append(command, args...)
 
-	fmt.Printf("MOCK REDIS: GetTTL %s\n", key)
-	return time.Hour, nil // Mock 1 hour TTL
+	reply, err := client.Do(command...)
+	var redisErr RedisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		command[0], command[1] = "EVAL", s.source
+		reply, err = client.Do(command...)
+	}
+	return reply, err
 }
 
-// Incr increments the integer value of a key by one
-func (r *RedisClient) Incr(key string) (int64, error) {
-	if !r.enabled {
-		return 0, fmt.Errorf("redis is not enabled")
+// RedisGlobEscape escapes the glob characters of s for use in a SCAN
+// pattern, so "user:*" can match keys under a literal prefix
+func RedisGlobEscape(s string) string {
+	var builder strings.Builder
+	for _, ch := range s {
+		switch ch {
+		case '*', '?', '[', ']', '\\':
+			builder.WriteByte('\\')
+		}
+		builder.WriteRune(ch)
+	}
+	return builder.String()
+}
+
+// MGet retrieves several keys at once. Missing keys are left out of the result.
+func (r *RedisClient) MGet(keys ...string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "MGET")
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	items, err := redisArray(r.Do(args...))
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		if item == nil || i >= len(keys) {
+			continue
+		}
+		if values[keys[i]], err = redisString(item, nil); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// MSet stores several key-value pairs at once, without expiry
+func (r *RedisClient) MSet(values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(values)*2+1)
+	args = append(args, "MSET")
+	for key, value := range values {
+		args = append(args, key, value)
+	}
+	return redisOK(r.Do(args...))
+}
+
+// HSet sets fields of a hash and returns how many were added
+func (r *RedisClient) HSet(key string, fields map[string]string) (int64, error) {
+	args := make([]interface{}, 0, len(fields)*2+2)
+	args = append(args, "HSET", key)
+	for field, value := range fields {
+		args = append(args, field, value)
+	}
+	return redisInt(r.Do(args...))
+}
+
+// HGet retrieves a field of a hash, or ErrRedisNil if it is missing
+func (r *RedisClient) HGet(key, field string) (string, error) {
+	return redisString(r.Do("HGET", key, field))
+}
+
+// HGetAll retrieves every field of a hash
+func (r *RedisClient) HGetAll(key string) (map[string]string, error) {
+	return redisStringMap(r.Do("HGETALL", key))
+}
+
+// HDel removes fields from a hash and returns how many existed
+func (r *RedisClient) HDel(key string, fields ...string) (int64, error) {
+	args := make([]interface{}, 0, len(fields)+2)
+	args = append(args, "HDEL", key)
+	for _, field := range fields {
+		args = append(args, field)
+	}
+	return redisInt(r.Do(args...))
+}
+
+// ZAdd adds or updates members of a sorted set and returns how many were added
+func (r *RedisClient) ZAdd(key string, members ...ZMember) (int64, error) {
+	args := make([]interface{}, 0, len(members)*2+2)
+	args = append(args, "ZADD", key)
+	for _, member := range members {
+		args = append(args, member.Score, member.Member)
+	}
+	return redisInt(r.Do(args...))
+}
+
+// ZRem removes members from a sorted set and returns how many existed
+func (r *RedisClient) ZRem(key string, members ...string) (int64, error) {
+	args := make([]interface{}, 0, len(members)+2)
+	args = append(args, "ZREM", key)
+	for _, member := range members {
+		args = append(args, member)
+	}
+	return redisInt(r.Do(args...))
+}
+
+// ZScore returns the score of a member, or ErrRedisNil if it is missing
+func (r *RedisClient) ZScore(key, member string) (float64, error) {
+	return redisFloat(r.Do("ZSCORE", key, member))
+}
+
+// ZCard returns the number of members of a sorted set
+func (r *RedisClient) ZCard(key string) (int64, error) {
+	return redisInt(r.Do("ZCARD", key))
+}
+
+// ZRange returns the members ranked start to stop (inclusive, negative
+// counts from the end) in ascending score order
+func (r *RedisClient) ZRange(key string, start, stop int64) ([]ZMember, error) {
+	items, err := redisArray(r.Do("ZRANGE", key, start, stop, "WITHSCORES"))
+	if err != nil {
+		return nil, err
+	}
+
+	// RESP3 sends [member, score] pairs, RESP2 a flat list
+	if len(items) > 0 {
+		if _, nested := items[0].([]interface{}); nested {
+			flat := make([]interface{}, 0, len(items)*2)
+			for _, item := range items {
+				pair, _ := item.([]interface{})
+				if len(pair) != 2 {
+					return nil, fmt.Errorf("redis: malformed sorted set reply")
+				}
+				flat = append(flat, pair...)
+			}
+			items = flat
+		}
+	}
+	if len(items)%2 != 0 {
+		return nil, fmt.Errorf("redis: malformed sorted set reply")
 	}
 
-	fmt.Printf("MOCK REDIS: Incr %s\n", key)
-	return 1, nil // Mock value after increment
+	members := make([]ZMember, 0, len(items)/2)
+	for i := 0; i < len(items); i += 2 {
+		member, err := redisString(items[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		score, err := redisFloat(items[i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, ZMember{Member: member, Score: score})
+	}
+	return members, nil
 }
 
 // Close closes the Redis client connection
 func (r *RedisClient) Close() error {
-	if !r.enabled {
-		return fmt.Errorf("redis is not enabled")
+	if !r.IsEnabled() {
+		return ErrRedisDisabled
 	}
 
-	fmt.Println("MOCK REDIS: Connection closed")
+	r.mutex.Lock()
+	r.closed = true
+	r.mutex.Unlock()
+
+	r.closeIdle()
 	return nil
 }
+
+// RedisPipeline queues commands and sends them in one round trip
+type RedisPipeline struct {
+	client   *RedisClient
+	commands [][]interface{}
+}
+
+// Pipeline starts a pipeline on the client
+func (r *RedisClient) Pipeline() *RedisPipeline {
+	return &RedisPipeline{client: r}
+}
+
+// Do queues a command
+func (p *RedisPipeline) Do(args ...interface{}) {
+	p.commands = append(p.commands, args)
+}
+
+// Exec sends the queued commands and returns one reply per command. Error
+// replies are returned in place as RedisError; the error result is only set
+// when the commands could not be sent or read.
+func (p *RedisPipeline) Exec() ([]interface{}, error) {
+	commands := p.commands
+	p.commands = nil
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	return p.client.exec(commands)
+}
+
+// exec sends commands on a pooled connection. A connection that fails is
+// discarded. The commands are retried on a new connection up to
+// max_retries times when they cannot have reached the server: the dial or
+// write failed, or a reused connection turned out to be closed. A server
+// restart or a dropped idle connection is recovered from this way without
+// running a command such as INCR twice.
+func (r *RedisClient) exec(commands [][]interface{}) ([]interface{}, error) {
+	if !r.IsEnabled() {
+		return nil, ErrRedisDisabled
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= r.cfg.MaxRetries; attempt++ {
+		conn, reused, err := r.getConn()
+		if err != nil {
+			// A rejected handshake, such as a wrong password, fails the same way again
+			var redisErr RedisError
+			if errors.Is(err, ErrRedisClosed) || errors.Is(err, ErrRedisPoolTimeout) || errors.As(err, &redisErr) {
+				return nil, err
+			}
+			lastErr = err
+			continue
+		}
+
+		replies, err := r.roundTrip(conn, commands)
+		r.putConn(conn, err != nil)
+		if err == nil {
+			return replies, nil
+		}
+
+		var writeErr redisWriteError
+		stale := reused && (errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET))
+		if !errors.As(err, &writeErr) && !stale {
+			return nil, err
+		}
+		if stale {
+			// The other idle connections most likely went down with this one
+			r.closeIdle()
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// redisWriteError marks a failure to send commands, so they never ran
+type redisWriteError struct {
+	err error
+}
+
+// Error implements the error interface
+func (e redisWriteError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e redisWriteError) Unwrap() error {
+	return e.err
+}
+
+// roundTrip writes commands and reads their replies
+func (r *RedisClient) roundTrip(conn *redisConn, commands [][]interface{}) ([]interface{}, error) {
+	if timeout := r.cfg.WriteTimeout.Duration(); timeout > 0 {
+		conn.conn.SetWriteDeadline(time.Now().Add(timeout))
+	}
+	for _, args := range commands {
+		if err := writeRedisCommand(conn.writer, args); err != nil {
+			return nil, err
+		}
+	}
+	if err := conn.writer.Flush(); err != nil {
+		return nil, redisWriteError{err}
+	}
+
+	if timeout := r.cfg.ReadTimeout.Duration(); timeout > 0 {
+		conn.conn.SetReadDeadline(time.Now().Add(timeout))
+	}
+	replies := make([]interface{}, 0, len(commands))
+	for len(replies) < len(commands) {
+		reply, err := readRedisReply(conn.reader)
+		if err != nil {
+			return nil, err
+		}
+		if _, push := reply.(redisPush); push {
+			continue
+		}
+		replies = append(replies, reply)
+	}
+	return replies, nil
+}
+
+// getConn takes an idle connection or dials a new one, waiting up to
+// pool_timeout while pool_size connections are in use. It reports whether
+// the connection was reused from the pool.
+func (r *RedisClient) getConn() (*redisConn, bool, error) {
+	timer := time.NewTimer(r.cfg.PoolTimeout.Duration())
+	defer timer.Stop()
+	select {
+	case r.slots <- struct{}{}:
+	case <-timer.C:
+		return nil, false, ErrRedisPoolTimeout
+	}
+
+	r.mutex.Lock()
+	if r.closed {
+		r.mutex.Unlock()
+		<-r.slots
+		return nil, false, ErrRedisClosed
+	}
+	for len(r.idle) > 0 {
+		conn := r.idle[len(r.idle)-1]
+		r.idle = r.idle[:len(r.idle)-1]
+		if idleTimeout := r.cfg.IdleTimeout.Duration(); idleTimeout > 0 && time.Since(conn.lastUsed) > idleTimeout {
+			conn.conn.Close()
+			continue
+		}
+		r.mutex.Unlock()
+		return conn, true, nil
+	}
+	r.mutex.Unlock()
+
+	conn, err := r.dial()
+	if err != nil {
+		<-r.slots
+		return nil, false, err
+	}
+	return conn, false, nil
+}
+
+// closeIdle closes the idle connections of the pool
+func (r *RedisClient) closeIdle() {
+	r.mutex.Lock()
+	idle := r.idle
+	r.idle = nil
+	r.mutex.Unlock()
+
+	for _, conn := range idle {
+		conn.conn.Close()
+	}
+}
+
+// putConn returns a connection to the pool, or closes it if it failed
+func (r *RedisClient) putConn(conn *redisConn, broken bool) {
+	r.mutex.Lock()
+	if broken || r.closed {
+		conn.conn.Close()
+	} else {
+		conn.lastUsed = time.Now()
+		r.idle = append(r.idle, conn)
+	}
+	r.mutex.Unlock()
+	<-r.slots
+}
+
+// dial opens a connection and runs the handshake: HELLO for RESP3, AUTH
+// and SELECT as configured
+func (r *RedisClient) dial() (*redisConn, error) {
+	netConn, err := net.DialTimeout("tcp", r.addr, r.cfg.DialTimeout.Duration())
+	if err != nil {
+		return nil, fmt.Errorf("redis: error connecting to %s: %w", r.addr, err)
+	}
+	conn := &redisConn{
+		conn:     netConn,
+		reader:   bufio.NewReader(netConn),
+		writer:   bufio.NewWriter(netConn),
+		lastUsed: time.Now(),
+	}
+
+	var handshake [][]interface{}
+	if r.cfg.Protocol == 3 {
+		hello := []interface{}{"HELLO", 3}
+		if r.cfg.Password != "" {
+			hello = append(hello, "AUTH", redisUsername(r.cfg.Username), r.cfg.Password)
+		}
+		handshake = append(handshake, hello)
+	} else if r.cfg.Password != "" {
+		if r.cfg.Username != "" {
+			handshake = append(handshake, []interface{}{"AUTH", r.cfg.Username, r.cfg.Password})
+		} else {
+			handshake = append(handshake, []interface{}{"AUTH", r.cfg.Password})
+		}
+	}
+	if r.cfg.DB != 0 {
+		handshake = append(handshake, []interface{}{"SELECT", r.cfg.DB})
+	}
+	if len(handshake) == 0 {
+		return conn, nil
+	}
+
+	replies, err := r.roundTrip(conn, handshake)
+	if err == nil {
+		for _, reply := range replies {
+			if redisErr, ok := reply.(RedisError); ok {
+				err = redisErr
+				break
+			}
+		}
+	}
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("redis: handshake with %s failed: %w", r.addr, err)
+	}
+	return conn, nil
+}
+
+// redisUsername returns the ACL user for AUTH, which HELLO always requires
+func redisUsername(username string) string {
+	if username == "" {
+		return "default"
+	}
+	return username
+}
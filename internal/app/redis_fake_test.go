@@ -0,0 +1,1069 @@
+package app
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeRedisServer is a minimal in-process Redis server for exercising
+// RedisClient in tests without a real server. It speaks RESP2 and,
+// after HELLO 3, RESP3, and implements the commands RedisClient uses: PING,
+// HELLO, AUTH, SELECT, GET, SET, SETNX, DEL, EXISTS, INCR, INCRBY, EXPIRE,
+// PEXPIRE, TTL, PTTL, SCAN, MGET, MSET, HSET, HGET, HGETALL, HDEL, ZADD,
+// ZREM, ZSCORE, ZCARD, ZRANGE, FLUSHDB and FLUSHALL. Keys expire lazily.
+// EVAL and EVALSHA only run the scripts registered with
+// registerFakeRedisScript, which are emulated in Go; like Redis, EVALSHA
+// replies NOSCRIPT until EVAL has loaded the script.
+type FakeRedisServer struct {
+	// username and password, when password is set, must be presented with
+	// AUTH or HELLO before any other command
+	username string
+	password string
+
+	listener net.Listener
+
+	mutex   sync.Mutex
+	dbs     map[int]map[string]*fakeRedisEntry
+	scripts map[string]bool // SHA1 of the scripts loaded with EVAL
+	calls   map[string]int  // commands received, by name
+	conns   map[net.Conn]struct{}
+	closed  bool
+}
+
+// fakeRedisEntry is one key of the fake server
+type fakeRedisEntry struct {
+	kind      string // "string", "hash" or "zset"
+	str       string
+	hash      map[string]string
+	zset      map[string]float64
+	expiresAt time.Time
+}
+
+// fakeRedisSession is the per-connection state of the fake server
+type fakeRedisSession struct {
+	w        *bufio.Writer
+	protocol int
+	db       int
+	authed   bool
+}
+
+// errFakeRedisQuit ends a connection after QUIT
+var errFakeRedisQuit = errors.New("quit")
+
+// NewFakeRedisServer starts a fake server on addr, such as "127.0.0.1:0"
+// for a free port. With a password, clients must authenticate as username,
+// or as the default user when username is empty.
+func NewFakeRedisServer(addr, username, password string) (*FakeRedisServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error starting fake redis: %w", err)
+	}
+
+	s := &FakeRedisServer{
+		username: username,
+		password: password,
+		listener: listener,
+		dbs:      make(map[int]map[string]*fakeRedisEntry),
+		scripts:  make(map[string]bool),
+		calls:    make(map[string]int),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address the server listens on
+func (s *FakeRedisServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and drops all connections
+func (s *FakeRedisServer) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	return s.listener.Close()
+}
+
+// DropConnections closes every client connection but keeps listening, as
+// a proxy or the server's idle timeout would
+func (s *FakeRedisServer) DropConnections() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// Conns returns the number of open client connections
+func (s *FakeRedisServer) Conns() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.conns)
+}
+
+// Calls returns how many times the named command was received
+func (s *FakeRedisServer) Calls(name string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls[strings.ToUpper(name)]
+}
+
+// newFakeRedis starts a fake server for the test and returns a client
+// configured for it
+func newFakeRedis(t *testing.T, username, password string) (*FakeRedisServer, RedisConfig) {
+	t.Helper()
+
+	server, err := NewFakeRedisServer("127.0.0.1:0", username, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	host, port, _ := strings.Cut(server.Addr(), ":")
+	cfg := DefaultConfig().Redis
+	cfg.Host = host
+	cfg.Port, _ = strconv.Atoi(port)
+	cfg.Username = username
+	cfg.Password = password
+	return server, cfg
+}
+
+// serve accepts connections until the server is closed
+func (s *FakeRedisServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+// handle runs the commands of one connection
+func (s *FakeRedisServer) handle(conn net.Conn) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	session := &fakeRedisSession{
+		w:        bufio.NewWriter(conn),
+		protocol: 2,
+		authed:   s.password == "",
+	}
+	for {
+		request, err := readRedisReply(r)
+		if err != nil {
+			return
+		}
+		items, ok := request.([]interface{})
+		if !ok || len(items) == 0 {
+			session.writeError("ERR protocol error: expected a command array")
+		} else {
+			args := make([]string, len(items))
+			for i, item := range items {
+				args[i] = fmt.Sprint(item)
+			}
+			err = s.execute(session, args)
+		}
+
+		// Flush once the client has no more pipelined commands buffered
+		if r.Buffered() == 0 || err != nil {
+			if flushErr := session.w.Flush(); flushErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// execute runs one command and writes its reply
+func (s *FakeRedisServer) execute(session *fakeRedisSession, args []string) error {
+	name := strings.ToUpper(args[0])
+	args = args[1:]
+
+	s.mutex.Lock()
+	s.calls[name]++
+	s.mutex.Unlock()
+
+	switch name {
+	case "QUIT":
+		session.writeSimple("OK")
+		return errFakeRedisQuit
+	case "HELLO":
+		s.hello(session, args)
+		return nil
+	case "AUTH":
+		s.auth(session, args)
+		return nil
+	}
+	if !session.authed {
+		session.writeError("NOAUTH Authentication required.")
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	handler, ok := fakeRedisCommands[name]
+	if !ok {
+		session.writeError(fmt.Sprintf("ERR unknown command '%s'", strings.ToLower(name)))
+		return nil
+	}
+	handler(s, session, args)
+	return nil
+}
+
+// hello switches the protocol and optionally authenticates
+func (s *FakeRedisServer) hello(session *fakeRedisSession, args []string) {
+	protocol := session.protocol
+	if len(args) > 0 {
+		version, err := strconv.Atoi(args[0])
+		if err != nil || (version != 2 && version != 3) {
+			session.writeError("NOPROTO unsupported protocol version")
+			return
+		}
+		protocol = version
+		args = args[1:]
+	}
+	if len(args) >= 3 && strings.EqualFold(args[0], "AUTH") {
+		if !s.checkAuth(args[1], args[2]) {
+			session.writeError("WRONGPASS invalid username-password pair or user is disabled.")
+			return
+		}
+		session.authed = true
+	}
+	if !session.authed {
+		session.writeError("NOAUTH HELLO must be called with the client already authenticated")
+		return
+	}
+
+	session.protocol = protocol
+	session.writeMap([]string{"server", "redis", "version", "7.2.0", "proto", strconv.Itoa(protocol), "mode", "standalone", "role", "master"})
+}
+
+// auth checks AUTH [username] password
+func (s *FakeRedisServer) auth(session *fakeRedisSession, args []string) {
+	var username, password string
+	switch len(args) {
+	case 1:
+		username, password = "default", args[0]
+	case 2:
+		username, password = args[0], args[1]
+	default:
+		session.writeError("ERR wrong number of arguments for 'auth' command")
+		return
+	}
+	if s.password == "" {
+		session.writeError("ERR AUTH called without any password configured for the default user")
+		return
+	}
+	if !s.checkAuth(username, password) {
+		session.writeError("WRONGPASS invalid username-password pair or user is disabled.")
+		return
+	}
+	session.authed = true
+	session.writeSimple("OK")
+}
+
+// checkAuth compares credentials with the configured ones
+func (s *FakeRedisServer) checkAuth(username, password string) bool {
+	expected := s.username
+	if expected == "" {
+		expected = "default"
+	}
+	return username == expected && password == s.password
+}
+
+// fakeRedisCommands maps command names to their handlers, which run with
+// the server mutex held
+var fakeRedisCommands = map[string]func(s *FakeRedisServer, session *fakeRedisSession, args []string){
+	"PING":     (*FakeRedisServer).cmdPing,
+	"SELECT":   (*FakeRedisServer).cmdSelect,
+	"FLUSHDB":  (*FakeRedisServer).cmdFlushDB,
+	"FLUSHALL": (*FakeRedisServer).cmdFlushAll,
+	"GET":      (*FakeRedisServer).cmdGet,
+	"SET":      (*FakeRedisServer).cmdSet,
+	"SETNX":    (*FakeRedisServer).cmdSetNX,
+	"DEL":      (*FakeRedisServer).cmdDel,
+	"EXISTS":   (*FakeRedisServer).cmdExists,
+	"INCR":     (*FakeRedisServer).cmdIncr,
+	"INCRBY":   (*FakeRedisServer).cmdIncrBy,
+	"EXPIRE":   (*FakeRedisServer).cmdExpire,
+	"PEXPIRE":  (*FakeRedisServer).cmdPExpire,
+	"TTL":      (*FakeRedisServer).cmdTTL,
+	"PTTL":     (*FakeRedisServer).cmdPTTL,
+	"EVAL":     (*FakeRedisServer).cmdEval,
+	"EVALSHA":  (*FakeRedisServer).cmdEvalSHA,
+	"SCAN":     (*FakeRedisServer).cmdScan,
+	"MGET":     (*FakeRedisServer).cmdMGet,
+	"MSET":     (*FakeRedisServer).cmdMSet,
+	"HSET":     (*FakeRedisServer).cmdHSet,
+	"HGET":     (*FakeRedisServer).cmdHGet,
+	"HGETALL":  (*FakeRedisServer).cmdHGetAll,
+	"HDEL":     (*FakeRedisServer).cmdHDel,
+	"ZADD":     (*FakeRedisServer).cmdZAdd,
+	"ZREM":     (*FakeRedisServer).cmdZRem,
+	"ZSCORE":   (*FakeRedisServer).cmdZScore,
+	"ZCARD":    (*FakeRedisServer).cmdZCard,
+	"ZRANGE":   (*FakeRedisServer).cmdZRange,
+}
+
+// db returns the keyspace selected by a session
+func (s *FakeRedisServer) db(session *fakeRedisSession) map[string]*fakeRedisEntry {
+	db, ok := s.dbs[session.db]
+	if !ok {
+		db = make(map[string]*fakeRedisEntry)
+		s.dbs[session.db] = db
+	}
+	return db
+}
+
+// lookup returns a live key, removing it when it expired
+func (s *FakeRedisServer) lookup(session *fakeRedisSession, key string) *fakeRedisEntry {
+	db := s.db(session)
+	entry, ok := db[key]
+	if !ok {
+		return nil
+	}
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		delete(db, key)
+		return nil
+	}
+	return entry
+}
+
+// lookupKind returns a live key of the given kind, writing WRONGTYPE and
+// returning false when the key holds another kind
+func (s *FakeRedisServer) lookupKind(session *fakeRedisSession, key, kind string) (*fakeRedisEntry, bool) {
+	entry := s.lookup(session, key)
+	if entry != nil && entry.kind != kind {
+		session.writeError("WRONGTYPE Operation against a key holding the wrong kind of value")
+		return nil, false
+	}
+	return entry, true
+}
+
+// fakeRedisScript emulates a Lua script; it runs with the server mutex held
+// and writes the script's reply
+type fakeRedisScript func(s *FakeRedisServer, session *fakeRedisSession, keys, args []string)
+
+// fakeRedisScripts maps the SHA1 of a script to its emulation
+var fakeRedisScripts = make(map[string]fakeRedisScript)
+
+// registerFakeRedisScript makes the fake server run fn for the script
+func registerFakeRedisScript(script *RedisScript, fn fakeRedisScript) {
+	fakeRedisScripts[script.sha] = fn
+}
+
+// arity writes an error and returns false when args has fewer than min items
+// or, with even set, an odd count
+func arity(session *fakeRedisSession, name string, args []string, min int, even bool) bool {
+	if len(args) < min || (even && len(args)%2 != 0) {
+		session.writeError(fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return false
+	}
+	return true
+}
+
+func (s *FakeRedisServer) cmdPing(session *fakeRedisSession, args []string) {
+	if len(args) > 0 {
+		session.writeBulk(args[0])
+		return
+	}
+	session.writeSimple("PONG")
+}
+
+func (s *FakeRedisServer) cmdSelect(session *fakeRedisSession, args []string) {
+	if !arity(session, "select", args, 1, false) {
+		return
+	}
+	db, err := strconv.Atoi(args[0])
+	if err != nil || db < 0 || db > 15 {
+		session.writeError("ERR DB index is out of range")
+		return
+	}
+	session.db = db
+	session.writeSimple("OK")
+}
+
+func (s *FakeRedisServer) cmdFlushDB(session *fakeRedisSession, args []string) {
+	delete(s.dbs, session.db)
+	session.writeSimple("OK")
+}
+
+func (s *FakeRedisServer) cmdFlushAll(session *fakeRedisSession, args []string) {
+	s.dbs = make(map[int]map[string]*fakeRedisEntry)
+	session.writeSimple("OK")
+}
+
+func (s *FakeRedisServer) cmdGet(session *fakeRedisSession, args []string) {
+	if !arity(session, "get", args, 1, false) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "string")
+	if !ok {
+		return
+	}
+	if entry == nil {
+		session.writeNull()
+		return
+	}
+	session.writeBulk(entry.str)
+}
+
+// cmdSet supports SET key value [EX seconds | PX milliseconds] [NX | XX]
+func (s *FakeRedisServer) cmdSet(session *fakeRedisSession, args []string) {
+	if !arity(session, "set", args, 2, false) {
+		return
+	}
+	key, value := args[0], args[1]
+	var expiresAt time.Time
+	var nx, xx bool
+	for i := 2; i < len(args); i++ {
+		switch option := strings.ToUpper(args[i]); option {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 >= len(args) {
+				session.writeError("ERR syntax error")
+				return
+			}
+			amount, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || amount <= 0 {
+				session.writeError("ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Millisecond
+			if option == "EX" {
+				unit = time.Second
+			}
+			expiresAt = time.Now().Add(time.Duration(amount) * unit)
+			i++
+		default:
+			session.writeError("ERR syntax error")
+			return
+		}
+	}
+
+	exists := s.lookup(session, key) != nil
+	if (nx && exists) || (xx && !exists) {
+		session.writeNull()
+		return
+	}
+	s.db(session)[key] = &fakeRedisEntry{kind: "string", str: value, expiresAt: expiresAt}
+	session.writeSimple("OK")
+}
+
+func (s *FakeRedisServer) cmdSetNX(session *fakeRedisSession, args []string) {
+	if !arity(session, "setnx", args, 2, false) {
+		return
+	}
+	if s.lookup(session, args[0]) != nil {
+		session.writeInt(0)
+		return
+	}
+	s.db(session)[args[0]] = &fakeRedisEntry{kind: "string", str: args[1]}
+	session.writeInt(1)
+}
+
+func (s *FakeRedisServer) cmdDel(session *fakeRedisSession, args []string) {
+	if !arity(session, "del", args, 1, false) {
+		return
+	}
+	var removed int64
+	for _, key := range args {
+		if s.lookup(session, key) != nil {
+			delete(s.db(session), key)
+			removed++
+		}
+	}
+	session.writeInt(removed)
+}
+
+func (s *FakeRedisServer) cmdExists(session *fakeRedisSession, args []string) {
+	if !arity(session, "exists", args, 1, false) {
+		return
+	}
+	var count int64
+	for _, key := range args {
+		if s.lookup(session, key) != nil {
+			count++
+		}
+	}
+	session.writeInt(count)
+}
+
+func (s *FakeRedisServer) cmdIncr(session *fakeRedisSession, args []string) {
+	if !arity(session, "incr", args, 1, false) {
+		return
+	}
+	s.incrBy(session, args[0], 1)
+}
+
+func (s *FakeRedisServer) cmdIncrBy(session *fakeRedisSession, args []string) {
+	if !arity(session, "incrby", args, 2, false) {
+		return
+	}
+	delta, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		session.writeError("ERR value is not an integer or out of range")
+		return
+	}
+	s.incrBy(session, args[0], delta)
+}
+
+// incrBy adds delta to an integer key, keeping its expiry
+func (s *FakeRedisServer) incrBy(session *fakeRedisSession, key string, delta int64) {
+	entry, ok := s.lookupKind(session, key, "string")
+	if !ok {
+		return
+	}
+	if entry == nil {
+		entry = &fakeRedisEntry{kind: "string", str: "0"}
+		s.db(session)[key] = entry
+	}
+	value, err := strconv.ParseInt(entry.str, 10, 64)
+	if err != nil {
+		session.writeError("ERR value is not an integer or out of range")
+		return
+	}
+	value += delta
+	entry.str = strconv.FormatInt(value, 10)
+	session.writeInt(value)
+}
+
+func (s *FakeRedisServer) cmdExpire(session *fakeRedisSession, args []string) {
+	s.expire(session, "expire", args, time.Second)
+}
+
+func (s *FakeRedisServer) cmdPExpire(session *fakeRedisSession, args []string) {
+	s.expire(session, "pexpire", args, time.Millisecond)
+}
+
+// expire sets the time to live of a key; a non-positive one deletes it
+func (s *FakeRedisServer) expire(session *fakeRedisSession, name string, args []string, unit time.Duration) {
+	if !arity(session, name, args, 2, false) {
+		return
+	}
+	amount, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		session.writeError("ERR value is not an integer or out of range")
+		return
+	}
+	entry := s.lookup(session, args[0])
+	if entry == nil {
+		session.writeInt(0)
+		return
+	}
+	if amount <= 0 {
+		delete(s.db(session), args[0])
+	} else {
+		entry.expiresAt = time.Now().Add(time.Duration(amount) * unit)
+	}
+	session.writeInt(1)
+}
+
+func (s *FakeRedisServer) cmdTTL(session *fakeRedisSession, args []string) {
+	s.ttl(session, "ttl", args, time.Second)
+}
+
+func (s *FakeRedisServer) cmdPTTL(session *fakeRedisSession, args []string) {
+	s.ttl(session, "pttl", args, time.Millisecond)
+}
+
+// ttl replies with the time to live of a key, -1 without expiry and -2 for
+// a missing key
+func (s *FakeRedisServer) ttl(session *fakeRedisSession, name string, args []string, unit time.Duration) {
+	if !arity(session, name, args, 1, false) {
+		return
+	}
+	entry := s.lookup(session, args[0])
+	switch {
+	case entry == nil:
+		session.writeInt(-2)
+	case entry.expiresAt.IsZero():
+		session.writeInt(-1)
+	default:
+		// Round up like Redis so a live key never reports 0 too early
+		remaining := time.Until(entry.expiresAt)
+		session.writeInt(int64((remaining + unit - 1) / unit))
+	}
+}
+
+func (s *FakeRedisServer) cmdEval(session *fakeRedisSession, args []string) {
+	if !arity(session, "eval", args, 2, false) {
+		return
+	}
+	sum := sha1.Sum([]byte(args[0]))
+	sha := hex.EncodeToString(sum[:])
+	script, ok := fakeRedisScripts[sha]
+	if !ok {
+		session.writeError("ERR fake redis can only run registered scripts")
+		return
+	}
+	s.scripts[sha] = true
+	s.runScript(session, script, args[1:])
+}
+
+func (s *FakeRedisServer) cmdEvalSHA(session *fakeRedisSession, args []string) {
+	if !arity(session, "evalsha", args, 2, false) {
+		return
+	}
+	sha := strings.ToLower(args[0])
+	script, ok := fakeRedisScripts[sha]
+	if !ok || !s.scripts[sha] {
+		session.writeError("NOSCRIPT No matching script. Please use EVAL.")
+		return
+	}
+	s.runScript(session, script, args[1:])
+}
+
+// runScript splits numkeys key... arg... and runs the emulation
+func (s *FakeRedisServer) runScript(session *fakeRedisSession, script fakeRedisScript, args []string) {
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil || numKeys < 0 || numKeys > len(args)-1 {
+		session.writeError("ERR Number of keys can't be greater than number of args")
+		return
+	}
+	script(s, session, args[1:1+numKeys], args[1+numKeys:])
+}
+
+// cmdScan supports SCAN cursor [MATCH pattern] [COUNT count]. COUNT is only
+// a hint in Redis; the fake returns every match in the first page, so keys
+// deleted between pages cannot make the iteration skip others.
+func (s *FakeRedisServer) cmdScan(session *fakeRedisSession, args []string) {
+	if !arity(session, "scan", args, 1, false) {
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		session.writeError("ERR invalid cursor")
+		return
+	}
+	match := "*"
+	for i := 1; i+1 < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			match = args[i+1]
+		case "COUNT":
+			if count, err := strconv.Atoi(args[i+1]); err != nil || count < 1 {
+				session.writeError("ERR value is not an integer or out of range")
+				return
+			}
+		default:
+			session.writeError("ERR syntax error")
+			return
+		}
+	}
+
+	var page []string
+	for key := range s.db(session) {
+		if fakeRedisMatch(match, key) && s.lookup(session, key) != nil {
+			page = append(page, key)
+		}
+	}
+	sort.Strings(page)
+
+	session.writeArrayHeader(2)
+	session.writeBulk("0")
+	session.writeArrayHeader(len(page))
+	for _, key := range page {
+		session.writeBulk(key)
+	}
+}
+
+// fakeRedisMatch matches a key against a glob pattern with *, ? and
+// backslash escapes
+func fakeRedisMatch(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(key); i >= 0; i-- {
+				if fakeRedisMatch(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
+
+func (s *FakeRedisServer) cmdMGet(session *fakeRedisSession, args []string) {
+	if !arity(session, "mget", args, 1, false) {
+		return
+	}
+	session.writeArrayHeader(len(args))
+	for _, key := range args {
+		entry := s.lookup(session, key)
+		if entry == nil || entry.kind != "string" {
+			session.writeNull()
+			continue
+		}
+		session.writeBulk(entry.str)
+	}
+}
+
+func (s *FakeRedisServer) cmdMSet(session *fakeRedisSession, args []string) {
+	if !arity(session, "mset", args, 2, true) {
+		return
+	}
+	db := s.db(session)
+	for i := 0; i < len(args); i += 2 {
+		db[args[i]] = &fakeRedisEntry{kind: "string", str: args[i+1]}
+	}
+	session.writeSimple("OK")
+}
+
+func (s *FakeRedisServer) cmdHSet(session *fakeRedisSession, args []string) {
+	if !arity(session, "hset", args, 3, false) || !arity(session, "hset", args[1:], 2, true) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "hash")
+	if !ok {
+		return
+	}
+	if entry == nil {
+		entry = &fakeRedisEntry{kind: "hash", hash: make(map[string]string)}
+		s.db(session)[args[0]] = entry
+	}
+	var added int64
+	for i := 1; i < len(args); i += 2 {
+		if _, exists := entry.hash[args[i]]; !exists {
+			added++
+		}
+		entry.hash[args[i]] = args[i+1]
+	}
+	session.writeInt(added)
+}
+
+func (s *FakeRedisServer) cmdHGet(session *fakeRedisSession, args []string) {
+	if !arity(session, "hget", args, 2, false) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "hash")
+	if !ok {
+		return
+	}
+	value, exists := "", false
+	if entry != nil {
+		value, exists = entry.hash[args[1]]
+	}
+	if !exists {
+		session.writeNull()
+		return
+	}
+	session.writeBulk(value)
+}
+
+func (s *FakeRedisServer) cmdHGetAll(session *fakeRedisSession, args []string) {
+	if !arity(session, "hgetall", args, 1, false) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "hash")
+	if !ok {
+		return
+	}
+	var pairs []string
+	if entry != nil {
+		fields := make([]string, 0, len(entry.hash))
+		for field := range entry.hash {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			pairs = append(pairs, field, entry.hash[field])
+		}
+	}
+	session.writeMap(pairs)
+}
+
+func (s *FakeRedisServer) cmdHDel(session *fakeRedisSession, args []string) {
+	if !arity(session, "hdel", args, 2, false) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "hash")
+	if !ok {
+		return
+	}
+	var removed int64
+	if entry != nil {
+		for _, field := range args[1:] {
+			if _, exists := entry.hash[field]; exists {
+				delete(entry.hash, field)
+				removed++
+			}
+		}
+		if len(entry.hash) == 0 {
+			delete(s.db(session), args[0])
+		}
+	}
+	session.writeInt(removed)
+}
+
+// cmdZAdd supports ZADD key score member [score member ...]
+func (s *FakeRedisServer) cmdZAdd(session *fakeRedisSession, args []string) {
+	if !arity(session, "zadd", args, 3, false) || !arity(session, "zadd", args[1:], 2, true) {
+		return
+	}
+	scores := make([]float64, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		score, err := parseRedisFloat(args[i])
+		if err != nil || math.IsNaN(score) {
+			session.writeError("ERR value is not a valid float")
+			return
+		}
+		scores = append(scores, score)
+	}
+
+	entry, ok := s.lookupKind(session, args[0], "zset")
+	if !ok {
+		return
+	}
+	if entry == nil {
+		entry = &fakeRedisEntry{kind: "zset", zset: make(map[string]float64)}
+		s.db(session)[args[0]] = entry
+	}
+	var added int64
+	for i, score := range scores {
+		member := args[2+2*i]
+		if _, exists := entry.zset[member]; !exists {
+			added++
+		}
+		entry.zset[member] = score
+	}
+	session.writeInt(added)
+}
+
+func (s *FakeRedisServer) cmdZRem(session *fakeRedisSession, args []string) {
+	if !arity(session, "zrem", args, 2, false) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "zset")
+	if !ok {
+		return
+	}
+	var removed int64
+	if entry != nil {
+		for _, member := range args[1:] {
+			if _, exists := entry.zset[member]; exists {
+				delete(entry.zset, member)
+				removed++
+			}
+		}
+		if len(entry.zset) == 0 {
+			delete(s.db(session), args[0])
+		}
+	}
+	session.writeInt(removed)
+}
+
+func (s *FakeRedisServer) cmdZScore(session *fakeRedisSession, args []string) {
+	if !arity(session, "zscore", args, 2, false) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "zset")
+	if !ok {
+		return
+	}
+	score, exists := 0.0, false
+	if entry != nil {
+		score, exists = entry.zset[args[1]]
+	}
+	if !exists {
+		session.writeNull()
+		return
+	}
+	session.writeDouble(score)
+}
+
+func (s *FakeRedisServer) cmdZCard(session *fakeRedisSession, args []string) {
+	if !arity(session, "zcard", args, 1, false) {
+		return
+	}
+	entry, ok := s.lookupKind(session, args[0], "zset")
+	if !ok {
+		return
+	}
+	if entry == nil {
+		session.writeInt(0)
+		return
+	}
+	session.writeInt(int64(len(entry.zset)))
+}
+
+// cmdZRange supports ZRANGE key start stop [WITHSCORES] by rank
+func (s *FakeRedisServer) cmdZRange(session *fakeRedisSession, args []string) {
+	if !arity(session, "zrange", args, 3, false) {
+		return
+	}
+	start, err1 := strconv.Atoi(args[1])
+	stop, err2 := strconv.Atoi(args[2])
+	if err1 != nil || err2 != nil {
+		session.writeError("ERR value is not an integer or out of range")
+		return
+	}
+	withScores := len(args) > 3 && strings.EqualFold(args[3], "WITHSCORES")
+
+	entry, ok := s.lookupKind(session, args[0], "zset")
+	if !ok {
+		return
+	}
+	var members []ZMember
+	if entry != nil {
+		for member, score := range entry.zset {
+			members = append(members, ZMember{Member: member, Score: score})
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].Score != members[j].Score {
+				return members[i].Score < members[j].Score
+			}
+			return members[i].Member < members[j].Member
+		})
+	}
+
+	// Negative ranks count from the end, as in Redis
+	if start < 0 {
+		start += len(members)
+	}
+	if stop < 0 {
+		stop += len(members)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= len(members) {
+		stop = len(members) - 1
+	}
+	if start > stop {
+		members = nil
+	} else {
+		members = members[start : stop+1]
+	}
+
+	if !withScores {
+		session.writeArrayHeader(len(members))
+		for _, member := range members {
+			session.writeBulk(member.Member)
+		}
+		return
+	}
+	if session.protocol == 3 {
+		// RESP3 replies with [member, score] pairs
+		session.writeArrayHeader(len(members))
+		for _, member := range members {
+			session.writeArrayHeader(2)
+			session.writeBulk(member.Member)
+			session.writeDouble(member.Score)
+		}
+		return
+	}
+	session.writeArrayHeader(2 * len(members))
+	for _, member := range members {
+		session.writeBulk(member.Member)
+		session.writeDouble(member.Score)
+	}
+}
+
+func (session *fakeRedisSession) writeSimple(value string) {
+	session.w.WriteString("+" + value + "\r\n")
+}
+
+func (session *fakeRedisSession) writeError(message string) {
+	session.w.WriteString("-" + message + "\r\n")
+}
+
+func (session *fakeRedisSession) writeInt(value int64) {
+	session.w.WriteString(":" + strconv.FormatInt(value, 10) + "\r\n")
+}
+
+func (session *fakeRedisSession) writeBulk(value string) {
+	session.w.WriteString("$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n")
+}
+
+// writeNull writes a RESP2 null bulk string or a RESP3 null
+func (session *fakeRedisSession) writeNull() {
+	if session.protocol == 3 {
+		session.w.WriteString("_\r\n")
+		return
+	}
+	session.w.WriteString("$-1\r\n")
+}
+
+// writeDouble writes a RESP3 double, or a bulk string under RESP2
+func (session *fakeRedisSession) writeDouble(value float64) {
+	text := strconv.FormatFloat(value, 'f', -1, 64)
+	if math.IsInf(value, 0) {
+		text = "inf"
+		if value < 0 {
+			text = "-inf"
+		}
+	}
+	if session.protocol == 3 {
+		session.w.WriteString("," + text + "\r\n")
+		return
+	}
+	session.writeBulk(text)
+}
+
+func (session *fakeRedisSession) writeArrayHeader(count int) {
+	session.w.WriteString("*" + strconv.Itoa(count) + "\r\n")
+}
+
+// writeMap writes key/value pairs as a RESP3 map, or a flat array under RESP2
+func (session *fakeRedisSession) writeMap(pairs []string) {
+	if session.protocol == 3 {
+		session.w.WriteString("%" + strconv.Itoa(len(pairs)/2) + "\r\n")
+	} else {
+		session.writeArrayHeader(len(pairs))
+	}
+	for _, item := range pairs {
+		session.writeBulk(item)
+	}
+}
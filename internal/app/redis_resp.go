@@ -0,0 +1,329 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRedisNil is returned when a key or field does not exist
+var ErrRedisNil = errors.New("redis: nil")
+
+// RedisError is an error reply sent by the server, such as WRONGTYPE
+type RedisError string
+
+// Error implements the error interface
+func (e RedisError) Error() string {
+	return string(e)
+}
+
+// redisPush is an out-of-band RESP3 push message
+type redisPush []interface{}
+
+// writeRedisCommand writes a command as a RESP array of bulk strings. The
+// arguments are encoded first, so one that cannot be sent is reported as
+// is before anything is written; failures of the writer itself are
+// returned as redisWriteError.
+func writeRedisCommand(w *bufio.Writer, args []interface{}) error {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		value, err := redisArg(arg)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+
+	w.WriteString("*")
+	w.WriteString(strconv.Itoa(len(values)))
+	w.WriteString("\r\n")
+	for _, value := range values {
+		w.WriteString("$")
+		w.WriteString(strconv.Itoa(len(value)))
+		w.WriteString("\r\n")
+		w.WriteString(value)
+		w.WriteString("\r\n")
+	}
+	// A bufio.Writer keeps its first error, so checking once covers every write
+	if _, err := w.WriteString(""); err != nil {
+		return redisWriteError{err}
+	}
+	return nil
+}
+
+// redisArg converts a command argument to its wire form
+func redisArg(arg interface{}) (string, error) {
+	switch v := arg.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Duration:
+		// Durations are sent in milliseconds, never rounding a positive one to 0
+		ms := v.Milliseconds()
+		if ms == 0 && v > 0 {
+			ms = 1
+		}
+		return strconv.FormatInt(ms, 10), nil
+	default:
+		return "", fmt.Errorf("redis: unsupported argument type %T", arg)
+	}
+}
+
+// readRedisReply reads one RESP2 or RESP3 reply. Error replies are returned
+// as RedisError values, not as the error result, so a pipeline can carry
+// on past them; the error result is only set when the connection failed.
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readRedisLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("redis: empty reply line")
+	}
+
+	kind, payload := line[0], line[1:]
+	switch kind {
+	case '+': // simple string
+		return payload, nil
+	case '-': // error
+		return RedisError(payload), nil
+	case ':': // integer
+		return strconv.ParseInt(payload, 10, 64)
+	case '_': // RESP3 null
+		return nil, nil
+	case '#': // RESP3 boolean
+		return payload == "t", nil
+	case ',': // RESP3 double
+		return parseRedisFloat(payload)
+	case '(': // RESP3 big number, kept as text
+		return payload, nil
+	case '$', '!', '=': // bulk string, blob error, verbatim string
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", payload)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		value := string(buf[:size])
+		switch kind {
+		case '!':
+			return RedisError(value), nil
+		case '=':
+			// Verbatim strings start with a three letter format such as "txt:"
+			if len(value) >= 4 {
+				value = value[4:]
+			}
+		}
+		return value, nil
+	case '*', '~', '>': // array, RESP3 set, RESP3 push
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", payload)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readRedisReply(r); err != nil {
+				return nil, err
+			}
+		}
+		if kind == '>' {
+			return redisPush(items), nil
+		}
+		return items, nil
+	case '%', '|': // RESP3 map, RESP3 attribute
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid map length %q", payload)
+		}
+		items := make(map[string]interface{}, count)
+		for i := 0; i < count; i++ {
+			key, err := readRedisReply(r)
+			if err != nil {
+				return nil, err
+			}
+			value, err := readRedisReply(r)
+			if err != nil {
+				return nil, err
+			}
+			items[fmt.Sprint(key)] = value
+		}
+		if kind == '|' {
+			// Attributes describe the reply that follows; skip them
+			return readRedisReply(r)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+}
+
+// readRedisLine reads a CRLF terminated line without the terminator
+func readRedisLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		return "", fmt.Errorf("redis: malformed reply line %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// parseRedisFloat parses a RESP3 double, including inf and nan
+func parseRedisFloat(value string) (float64, error) {
+	switch value {
+	case "inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// redisString converts a reply to a string, turning a null into ErrRedisNil
+func redisString(reply interface{}, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	switch v := reply.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", ErrRedisNil
+	case RedisError:
+		return "", v
+	}
+	return "", fmt.Errorf("redis: unexpected reply type %T", reply)
+}
+
+// redisInt converts an integer reply
+func redisInt(reply interface{}, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch v := reply.(type) {
+	case int64:
+		return v, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case nil:
+		return 0, ErrRedisNil
+	case RedisError:
+		return 0, v
+	}
+	return 0, fmt.Errorf("redis: unexpected reply type %T", reply)
+}
+
+// redisFloat converts a score reply, sent as a bulk string by RESP2 and as
+// a double by RESP3
+func redisFloat(reply interface{}, err error) (float64, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch v := reply.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return parseRedisFloat(v)
+	case int64:
+		return float64(v), nil
+	case nil:
+		return 0, ErrRedisNil
+	case RedisError:
+		return 0, v
+	}
+	return 0, fmt.Errorf("redis: unexpected reply type %T", reply)
+}
+
+// redisOK checks a status reply
+func redisOK(reply interface{}, err error) error {
+	if err != nil {
+		return err
+	}
+	if redisErr, ok := reply.(RedisError); ok {
+		return redisErr
+	}
+	return nil
+}
+
+// redisArray converts an array or set reply
+func redisArray(reply interface{}, err error) ([]interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	switch v := reply.(type) {
+	case []interface{}:
+		return v, nil
+	case nil:
+		return nil, nil
+	case RedisError:
+		return nil, v
+	}
+	return nil, fmt.Errorf("redis: unexpected reply type %T", reply)
+}
+
+// redisStringMap converts a map reply, sent as a flat array of field/value
+// pairs by RESP2 and as a map by RESP3
+func redisStringMap(reply interface{}, err error) (map[string]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	switch v := reply.(type) {
+	case map[string]interface{}:
+		values := make(map[string]string, len(v))
+		for field, value := range v {
+			if values[field], err = redisString(value, nil); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	case []interface{}:
+		if len(v)%2 != 0 {
+			return nil, fmt.Errorf("redis: odd number of map items")
+		}
+		values := make(map[string]string, len(v)/2)
+		for i := 0; i < len(v); i += 2 {
+			field, err := redisString(v[i], nil)
+			if err != nil {
+				return nil, err
+			}
+			if values[field], err = redisString(v[i+1], nil); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	case nil:
+		return map[string]string{}, nil
+	case RedisError:
+		return nil, v
+	}
+	return nil, fmt.Errorf("redis: unexpected reply type %T", reply)
+}
@@ -0,0 +1,417 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRedisClient creates a client for cfg, closed when the test ends
+func newTestRedisClient(t *testing.T, cfg RedisConfig) *RedisClient {
+	client := NewRedisClient(cfg)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRedisPoolReusesConnections(t *testing.T) {
+	server, cfg := newFakeRedis(t, "", "")
+	client := newTestRedisClient(t, cfg)
+
+	for i := 0; i < 5; i++ {
+		if err := client.Ping(); err != nil {
+			t.Fatalf("ping %d: %v", i, err)
+		}
+	}
+	if got := server.Conns(); got != 1 {
+		t.Errorf("connections after sequential commands = %d, want 1", got)
+	}
+}
+
+func TestRedisPoolExhaustion(t *testing.T) {
+	_, cfg := newFakeRedis(t, "", "")
+	cfg.PoolSize = 2
+	cfg.PoolTimeout = Duration(50 * time.Millisecond)
+	client := newTestRedisClient(t, cfg)
+
+	first, _, err := client.getConn()
+	if err != nil {
+		t.Fatalf("first checkout: %v", err)
+	}
+	second, _, err := client.getConn()
+	if err != nil {
+		t.Fatalf("second checkout: %v", err)
+	}
+
+	started := time.Now()
+	if err := client.Ping(); !errors.Is(err, ErrRedisPoolTimeout) {
+		t.Fatalf("ping with the pool exhausted: err = %v, want ErrRedisPoolTimeout", err)
+	}
+	if waited := time.Since(started); waited < 50*time.Millisecond {
+		t.Errorf("gave up after %v, want pool_timeout", waited)
+	}
+
+	// A connection returned while waiting is handed over
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		client.putConn(first, false)
+	}()
+	conn, reused, err := client.getConn()
+	if err != nil {
+		t.Fatalf("checkout after return: %v", err)
+	}
+	if !reused || conn != first {
+		t.Error("returned connection not reused")
+	}
+	client.putConn(conn, false)
+	client.putConn(second, false)
+
+	if err := client.Ping(); err != nil {
+		t.Errorf("ping after the connections were returned: %v", err)
+	}
+}
+
+func TestRedisPoolDropsIdleConnections(t *testing.T) {
+	server, cfg := newFakeRedis(t, "", "")
+	cfg.IdleTimeout = Duration(20 * time.Millisecond)
+	client := newTestRedisClient(t, cfg)
+
+	if err := client.Ping(); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	first := client.idle[0]
+	time.Sleep(40 * time.Millisecond)
+	if err := client.Ping(); err != nil {
+		t.Fatalf("ping after idle timeout: %v", err)
+	}
+	if client.idle[0] == first {
+		t.Error("connection idle past idle_timeout was reused")
+	}
+	if !waitFor(t, time.Second, func() bool { return server.Conns() == 1 }) {
+		t.Errorf("server connections = %d, want the idle one closed", server.Conns())
+	}
+}
+
+func TestRedisClosedClient(t *testing.T) {
+	_, cfg := newFakeRedis(t, "", "")
+	client := NewRedisClient(cfg)
+	client.Close()
+	if err := client.Ping(); !errors.Is(err, ErrRedisClosed) {
+		t.Errorf("ping on closed client: err = %v, want ErrRedisClosed", err)
+	}
+}
+
+func TestRedisHandshake(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		protocol int
+		db       int
+		commands []string // handshake commands the server should see
+	}{
+		{"resp2 default user", "", 2, 0, []string{"AUTH"}},
+		{"resp2 acl user", "app", 2, 0, []string{"AUTH"}},
+		{"resp2 select", "", 2, 3, []string{"AUTH", "SELECT"}},
+		{"resp3 default user", "", 3, 0, []string{"HELLO"}},
+		{"resp3 acl user with select", "app", 3, 3, []string{"HELLO", "SELECT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cfg := newFakeRedis(t, tt.username, "secret")
+			cfg.Protocol = tt.protocol
+			cfg.DB = tt.db
+			client := newTestRedisClient(t, cfg)
+
+			if err := client.Set("key", "value", 0); err != nil {
+				t.Fatalf("set: %v", err)
+			}
+			for _, name := range tt.commands {
+				if got := server.Calls(name); got != 1 {
+					t.Errorf("%s sent %d times, want once", name, got)
+				}
+			}
+			for _, name := range []string{"AUTH", "HELLO", "SELECT"} {
+				if server.Calls(name) > 0 && !contains(tt.commands, name) {
+					t.Errorf("unexpected %s in handshake", name)
+				}
+			}
+
+			server.mutex.Lock()
+			_, stored := server.dbs[tt.db]["key"]
+			server.mutex.Unlock()
+			if !stored {
+				t.Errorf("key not stored in database %d", tt.db)
+			}
+		})
+	}
+}
+
+func TestRedisHandshakeRejected(t *testing.T) {
+	for _, protocol := range []int{2, 3} {
+		server, cfg := newFakeRedis(t, "", "secret")
+		cfg.Password = "wrong"
+		cfg.Protocol = protocol
+		client := newTestRedisClient(t, cfg)
+
+		err := client.Ping()
+		var redisErr RedisError
+		if !errors.As(err, &redisErr) || !strings.HasPrefix(string(redisErr), "WRONGPASS") {
+			t.Errorf("protocol %d: ping with a wrong password: err = %v, want WRONGPASS", protocol, err)
+		}
+		if calls := server.Calls("AUTH") + server.Calls("HELLO"); calls != 1 {
+			t.Errorf("protocol %d: handshake sent %d times, want no retries", protocol, calls)
+		}
+	}
+
+	// Without credentials the server refuses commands
+	_, cfg := newFakeRedis(t, "", "secret")
+	cfg.Password = ""
+	err := newTestRedisClient(t, cfg).Ping()
+	var redisErr RedisError
+	if !errors.As(err, &redisErr) || !strings.HasPrefix(string(redisErr), "NOAUTH") {
+		t.Errorf("ping without a password: err = %v, want NOAUTH", err)
+	}
+}
+
+func TestRedisProtocolReplies(t *testing.T) {
+	for _, protocol := range []int{2, 3} {
+		_, cfg := newFakeRedis(t, "", "")
+		cfg.Protocol = protocol
+		client := newTestRedisClient(t, cfg)
+
+		if _, err := client.HSet("hash", map[string]string{"a": "1", "b": "2"}); err != nil {
+			t.Fatalf("protocol %d: hset: %v", protocol, err)
+		}
+		raw, err := client.Do("HGETALL", "hash")
+		if err != nil {
+			t.Fatalf("protocol %d: hgetall: %v", protocol, err)
+		}
+		switch protocol {
+		case 2:
+			if _, ok := raw.([]interface{}); !ok {
+				t.Errorf("RESP2 HGETALL reply is %T, want a flat array", raw)
+			}
+		case 3:
+			if _, ok := raw.(map[string]interface{}); !ok {
+				t.Errorf("RESP3 HGETALL reply is %T, want a map", raw)
+			}
+		}
+		fields, err := client.HGetAll("hash")
+		if err != nil {
+			t.Fatalf("protocol %d: HGetAll: %v", protocol, err)
+		}
+		if want := map[string]string{"a": "1", "b": "2"}; !reflect.DeepEqual(fields, want) {
+			t.Errorf("protocol %d: HGetAll = %v, want %v", protocol, fields, want)
+		}
+
+		if _, err := client.ZAdd("scores", ZMember{Member: "x", Score: 1.5}); err != nil {
+			t.Fatalf("protocol %d: zadd: %v", protocol, err)
+		}
+		raw, err = client.Do("ZSCORE", "scores", "x")
+		if err != nil {
+			t.Fatalf("protocol %d: zscore: %v", protocol, err)
+		}
+		if _, isFloat := raw.(float64); isFloat != (protocol == 3) {
+			t.Errorf("protocol %d: ZSCORE reply is %T", protocol, raw)
+		}
+		if score, err := client.ZScore("scores", "x"); err != nil || score != 1.5 {
+			t.Errorf("protocol %d: ZScore = %v, %v; want 1.5", protocol, score, err)
+		}
+
+		if _, err := client.Get("missing"); !errors.Is(err, ErrRedisNil) {
+			t.Errorf("protocol %d: get missing key: err = %v, want ErrRedisNil", protocol, err)
+		}
+	}
+}
+
+func TestRedisPipeline(t *testing.T) {
+	server, cfg := newFakeRedis(t, "", "")
+	client := newTestRedisClient(t, cfg)
+
+	pipeline := client.Pipeline()
+	pipeline.Do("SET", "name", "value")
+	pipeline.Do("INCR", "name")
+	pipeline.Do("INCR", "counter")
+	pipeline.Do("GET", "name")
+	replies, err := pipeline.Exec()
+	if err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if len(replies) != 4 {
+		t.Fatalf("got %d replies, want 4", len(replies))
+	}
+	if replies[0] != "OK" {
+		t.Errorf("SET reply = %v", replies[0])
+	}
+	if _, ok := replies[1].(RedisError); !ok {
+		t.Errorf("INCR of a string = %v, want an error reply in place", replies[1])
+	}
+	if replies[2] != int64(1) {
+		t.Errorf("INCR reply = %v, want 1", replies[2])
+	}
+	if replies[3] != "value" {
+		t.Errorf("GET reply = %v", replies[3])
+	}
+	if got := server.Conns(); got != 1 {
+		t.Errorf("pipeline used %d connections, want 1", got)
+	}
+
+	// The queue is cleared by Exec
+	if replies, err := pipeline.Exec(); replies != nil || err != nil {
+		t.Errorf("second exec = %v, %v; want nothing sent", replies, err)
+	}
+}
+
+func TestRedisScriptFallsBackToEval(t *testing.T) {
+	server, cfg := newFakeRedis(t, "", "")
+	client := newTestRedisClient(t, cfg)
+	locker := NewRedisLocker(client, "lock:")
+
+	if _, err := locker.Acquire("job", "a", time.Minute); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if got := server.Calls("EVALSHA"); got != 1 {
+		t.Errorf("EVALSHA sent %d times, want 1", got)
+	}
+	if got := server.Calls("EVAL"); got != 1 {
+		t.Errorf("EVAL sent %d times after NOSCRIPT, want 1", got)
+	}
+
+	// The server has the script cached now
+	if err := locker.Release("job", "a"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, err := locker.Acquire("job", "a", time.Minute); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if got := server.Calls("EVAL"); got != 2 {
+		t.Errorf("EVAL sent %d times, want only once per script", got)
+	}
+	if got := server.Calls("EVALSHA"); got != 3 {
+		t.Errorf("EVALSHA sent %d times, want 3", got)
+	}
+
+	// Other error replies are not retried with EVAL
+	_, err := NewRedisScript("return 1").Run(client, nil)
+	var redisErr RedisError
+	if !errors.As(err, &redisErr) {
+		t.Errorf("unregistered script: err = %v, want an error reply", err)
+	}
+}
+
+func TestRedisReconnectsAfterDroppedConnections(t *testing.T) {
+	server, cfg := newFakeRedis(t, "", "secret")
+	cfg.DB = 1
+	client := newTestRedisClient(t, cfg)
+
+	if _, err := client.Incr("counter"); err != nil {
+		t.Fatalf("incr: %v", err)
+	}
+	server.DropConnections()
+	if !waitFor(t, time.Second, func() bool { return server.Conns() == 0 }) {
+		t.Fatal("connections not dropped")
+	}
+
+	count, err := client.Incr("counter")
+	if err != nil {
+		t.Fatalf("incr on a stale connection: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("counter = %d, want 2: INCR ran once and the new connection selected database 1", count)
+	}
+}
+
+func TestRedisReconnectsAfterServerRestart(t *testing.T) {
+	server, cfg := newFakeRedis(t, "", "")
+	cfg.MaxRetries = 1
+	client := newTestRedisClient(t, cfg)
+
+	// restart replaces the server with an empty one on the same address
+	restart := func() {
+		t.Helper()
+		addr := server.Addr()
+		server.Close()
+		var err error
+		if server, err = NewFakeRedisServer(addr, "", ""); err != nil {
+			t.Fatalf("restart: %v", err)
+		}
+		t.Cleanup(func() { server.Close() })
+	}
+
+	if err := client.Set("key", "value", 0); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	restart()
+
+	// The pooled connection is stale; the command runs once on a new one
+	if count, err := client.Incr("counter"); err != nil || count != 1 {
+		t.Errorf("incr after restart = %d, %v; want 1", count, err)
+	}
+	if got := server.Calls("INCR"); got != 1 {
+		t.Errorf("INCR received %d times, want 1", got)
+	}
+
+	// Commands fail while the server is down, and work once it is back
+	addr := server.Addr()
+	server.Close()
+	if err := client.Ping(); err == nil {
+		t.Fatal("ping with the server down succeeded")
+	}
+	restarted, err := NewFakeRedisServer(addr, "", "")
+	if err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	t.Cleanup(func() { restarted.Close() })
+	if err := client.Ping(); err != nil {
+		t.Errorf("ping after restart: %v", err)
+	}
+}
+
+// failingWriter fails every write, like a connection that went away
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestWriteRedisCommand(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := writeRedisCommand(w, []interface{}{"SET", "k", 42, 500 * time.Microsecond}); err != nil {
+		t.Fatalf("writeRedisCommand: %v", err)
+	}
+	w.Flush()
+	if want := "*4\r\n$3\r\nSET\r\n$1\r\nk\r\n$2\r\n42\r\n$1\r\n1\r\n"; buf.String() != want {
+		t.Errorf("wrote %q, want %q", buf.String(), want)
+	}
+
+	// An argument that cannot be encoded is reported before anything is written
+	buf.Reset()
+	err := writeRedisCommand(w, []interface{}{"SET", "k", struct{}{}})
+	var writeErr redisWriteError
+	if err == nil || errors.As(err, &writeErr) {
+		t.Errorf("unsupported argument = %v, want a plain error", err)
+	}
+	if w.Buffered() != 0 {
+		t.Errorf("%d bytes were buffered for a rejected command", w.Buffered())
+	}
+
+	// A write failure marks the command as never sent, so it can be retried
+	w = bufio.NewWriterSize(failingWriter{}, 16)
+	err = writeRedisCommand(w, []interface{}{"SET", "key", strings.Repeat("v", 64)})
+	if !errors.As(err, &writeErr) {
+		t.Errorf("failed write = %v, want a redisWriteError", err)
+	}
+}
+
+func contains(items []string, item string) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Prefixes of secret references in config values
+	secretFilePrefix  = "file:"
+	secretEnvPrefix   = "env:"
+	secretStorePrefix = "secret:"
+
+	// secretStoreVersion is written to new secrets files
+	secretStoreVersion = 1
+)
+
+var (
+	ErrSecretStoreKey     = errors.New("wrong master key or corrupted secrets file")
+	ErrMissingMasterKey   = errors.New("no master key configured for the secrets file")
+	ErrSecretStoreVersion = errors.New("unsupported secrets file version")
+)
+
+// SecretStore is a local file of named secrets encrypted with a master key.
+// The key is stretched with scrypt and the contents sealed with AES-256-GCM.
+type SecretStore struct {
+	path      string
+	masterKey string
+	values    map[string]string
+}
+
+// secretFile is the on-disk form of a SecretStore
+type secretFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// OpenSecretStore decrypts the secrets file at path. A missing file opens
+// as an empty store that is created on Save.
+func OpenSecretStore(path, masterKey string) (*SecretStore, error) {
+	if masterKey == "" {
+		return nil, ErrMissingMasterKey
+	}
+	store := &SecretStore{
+		path:      path,
+		masterKey: masterKey,
+		values:    make(map[string]string),
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading secrets file: %w", err)
+	}
+
+	var file secretFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("error decoding secrets file: %w", err)
+	}
+	if file.Version != secretStoreVersion {
+		return nil, ErrSecretStoreVersion
+	}
+
+	aead, err := secretCipher(masterKey, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrSecretStoreKey
+	}
+	if err := json.Unmarshal(plaintext, &store.values); err != nil {
+		return nil, fmt.Errorf("error decoding secrets: %w", err)
+	}
+	return store, nil
+}
+
+// Get returns a secret by name
+func (s *SecretStore) Get(name string) (string, bool) {
+	value, exists := s.values[name]
+	return value, exists
+}
+
+// Set adds or replaces a secret
+func (s *SecretStore) Set(name, value string) {
+	s.values[name] = value
+}
+
+// Delete removes a secret and reports whether it existed
+func (s *SecretStore) Delete(name string) bool {
+	_, exists := s.values[name]
+	delete(s.values, name)
+	return exists
+}
+
+// Names returns the sorted secret names
+func (s *SecretStore) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Values returns a copy of all secrets
+func (s *SecretStore) Values() map[string]string {
+	values := make(map[string]string, len(s.values))
+	for name, value := range s.values {
+		values[name] = value
+	}
+	return values
+}
+
+// Replace swaps all secrets for the given ones
+func (s *SecretStore) Replace(values map[string]string) {
+	s.values = make(map[string]string, len(values))
+	for name, value := range values {
+		s.values[name] = value
+	}
+}
+
+// Save encrypts the secrets with a fresh salt and nonce and atomically
+// replaces the file
+func (s *SecretStore) Save() error {
+	plaintext, err := json.Marshal(s.values)
+	if err != nil {
+		return fmt.Errorf("error encoding secrets: %w", err)
+	}
+
+	file := secretFile{
+		Version: secretStoreVersion,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return fmt.Errorf("error generating salt: %w", err)
+	}
+	aead, err := secretCipher(s.masterKey, file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %w", err)
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, nil)
+
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding secrets file: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(s.path), ".secrets-*")
+	if err != nil {
+		return fmt.Errorf("error writing secrets file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return fmt.Errorf("error writing secrets file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("error writing secrets file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), s.path); err != nil {
+		return fmt.Errorf("error replacing secrets file: %w", err)
+	}
+	return nil
+}
+
+// secretCipher derives the AES-256-GCM cipher for a master key and salt
+func secretCipher(masterKey string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(masterKey), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// resolveSecrets replaces secret references in every field tagged
+// secret:"true": "file:/path" reads a file, "env:NAME" reads an environment
+// variable and "secret:name" reads the encrypted secrets file. Resolved
+// values are registered for log redaction.
+func resolveSecrets(cfg *Config) []string {
+	var problems []string
+
+	// The master key may itself come from a file or the environment
+	masterKey, err := resolveSecretRef(cfg.Secrets.MasterKey, nil)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("secrets.master_key: %v", err))
+	}
+	cfg.Secrets.MasterKey = masterKey
+
+	var store *SecretStore
+	openStore := func() (*SecretStore, error) {
+		if store == nil {
+			opened, err := OpenSecretStore(cfg.Secrets.File, masterKey)
+			if err != nil {
+				return nil, err
+			}
+			store = opened
+		}
+		return store, nil
+	}
+
+	walkSecretFields(reflect.ValueOf(cfg).Elem(), "", func(key string, field reflect.Value) {
+		if key != "secrets.master_key" {
+			value, err := resolveSecretRef(field.String(), openStore)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+				return
+			}
+			field.SetString(value)
+		}
+		RedactValue(field.String())
+	})
+	return problems
+}
+
+// resolveSecretRef returns the value a reference points to, or the value
+// itself when it is not a reference. openStore may be nil when references
+// into the secrets file are not allowed.
+func resolveSecretRef(value string, openStore func() (*SecretStore, error)) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretFilePrefix):
+		content, err := os.ReadFile(strings.TrimPrefix(value, secretFilePrefix))
+		if err != nil {
+			return "", fmt.Errorf("error reading secret file: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		resolved, exists := os.LookupEnv(name)
+		if !exists {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return resolved, nil
+	case strings.HasPrefix(value, secretStorePrefix):
+		if openStore == nil {
+			return "", fmt.Errorf("secrets file references are not allowed here")
+		}
+		store, err := openStore()
+		if err != nil {
+			return "", err
+		}
+		name := strings.TrimPrefix(value, secretStorePrefix)
+		resolved, exists := store.Get(name)
+		if !exists {
+			return "", fmt.Errorf("secret %q not found in %s", name, store.path)
+		}
+		return resolved, nil
+	}
+	return value, nil
+}
+
+// walkSecretFields calls fn for every string field tagged secret:"true",
+// including those inside lists such as the OIDC providers
+func walkSecretFields(v reflect.Value, prefix string, fn func(key string, field reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			key := prefix + jsonName(field)
+			if field.Tag.Get("secret") == "true" && field.Type.Kind() == reflect.String {
+				fn(key, v.Field(i))
+				continue
+			}
+			walkSecretFields(v.Field(i), key+".", fn)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			walkSecretFields(v.Index(i), fmt.Sprintf("%s[%d].", strings.TrimSuffix(prefix, "."), i), fn)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"sync"
+)
+
+// SingleFlight collapses concurrent calls for the same key into one: while
+// a call is running, later callers wait for it and share its result. The
+// zero value is ready to use.
+type SingleFlight struct {
+	mutex sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is a call in progress
+type flightCall struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+// Do runs fn once for all concurrent callers with the same key. Callers
+// share the returned slice and must not modify it.
+func (g *SingleFlight) Do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mutex.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fn()
+	return call.value, call.err
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/migrations"
+)
+
+const migrateUsage = `Usage: goapp migrate <command>
+
+Apply and roll back versioned schema migrations. Applied migrations are
+recorded in the schema_migrations table.
+
+Commands:
+  up                          apply all pending migrations
+  down [N]                    roll back the newest N migrations (default 1)
+  status                      list migrations and whether they are applied
+  create [--dir path] <name>  write a new migration file
+`
+
+// migrationNamePattern restricts migration names to what is safe in a file name
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// migrationTemplate is the content of a new migration file
+const migrationTemplate = `package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(%d, %q, func(tx *gorm.DB) error {
+		return nil
+	}, func(tx *gorm.DB) error {
+		return nil
+	})
+}
+`
+
+// RunMigrate runs the migrate subcommand against the configured database
+func RunMigrate(args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Print(migrateUsage)
+		return flag.ErrHelp
+	}
+
+	command, args := args[0], args[1:]
+	if command == "create" {
+		return createMigration(args)
+	}
+
+	steps := 1
+	if command == "down" && len(args) > 0 {
+		var err error
+		if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+			return fmt.Errorf("usage: goapp migrate down [N], with N a positive number")
+		}
+	}
+
+	db, err := app.OpenDatabase(app.GetConfig().Database)
+	if err != nil {
+		return err
+	}
+	migrator := migrations.NewMigrator(db)
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		return err
+	case "down":
+		rolledBack, err := migrator.Down(steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("Rolled back %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(rolledBack) == 0 {
+			fmt.Println("No applied migrations")
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		printMigrationStatus(statuses)
+		return nil
+	default:
+		fmt.Print(migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+}
+
+// printMigrationStatus prints the migrations as a table
+func printMigrationStatus(statuses []migrations.MigrationStatus) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", ""
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format(time.RFC3339)
+		}
+		if status.Missing {
+			state = "applied, missing from build"
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	writer.Flush()
+}
+
+// createMigration writes an empty migration named after the current time
+func createMigration(args []string) error {
+	flagSet := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := flagSet.String("dir", filepath.Join("internal", "migrations"), "directory of the migrations package")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 1 {
+		return fmt.Errorf("usage: goapp migrate create [--dir path] <name>")
+	}
+
+	name := flagSet.Arg(0)
+	if !migrationNamePattern.MatchString(name) {
+		return fmt.Errorf("migration name %q may only contain lowercase letters, digits and underscores", name)
+	}
+
+	version, _ := strconv.ParseInt(time.Now().UTC().Format("20060102150405"), 10, 64)
+	path := filepath.Join(*dir, fmt.Sprintf("%d_%s.go", version, name))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating migration: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, migrationTemplate, version, name); err != nil {
+		return fmt.Errorf("error writing migration: %w", err)
+	}
+	fmt.Printf("Created %s\n", path)
+	return nil
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"goapp/internal/app"
+)
+
+const secretsUsage = `Usage: goapp secrets [--file path] <command>
+
+Manage the encrypted secrets file. The master key is read from
+GOAPP_SECRETS_MASTER_KEY and the file defaults to GOAPP_SECRETS_FILE or
+secrets.enc. Config values reference secrets as "secret:<name>".
+
+Commands:
+  list                  list secret names
+  get <name>            print a secret
+  set <name> [value]    add or replace a secret, reading stdin without a value
+  delete <name>         remove a secret
+  edit                  edit all secrets as JSON in $EDITOR
+  encrypt <file>        replace all secrets with a plaintext JSON object
+`
+
+// RunSecrets runs the secrets subcommand. It runs before the configuration
+// is loaded so a secret the configuration needs can always be fixed.
+func RunSecrets(args []string) error {
+	flagSet := flag.NewFlagSet("secrets", flag.ContinueOnError)
+	flagSet.Usage = func() { fmt.Fprint(flagSet.Output(), secretsUsage) }
+	file := flagSet.String("file", defaultSecretsFile(), "path to the secrets file")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	args = flagSet.Args()
+	if len(args) == 0 {
+		flagSet.Usage()
+		return flag.ErrHelp
+	}
+
+	store, err := app.OpenSecretStore(*file, os.Getenv(app.EnvPrefix+"SECRETS_MASTER_KEY"))
+	if err != nil {
+		return err
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "list":
+		for _, name := range store.Names() {
+			fmt.Println(name)
+		}
+		return nil
+	case "get":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: goapp secrets get <name>")
+		}
+		value, exists := store.Get(args[0])
+		if !exists {
+			return fmt.Errorf("secret %q not found", args[0])
+		}
+		fmt.Println(value)
+		return nil
+	case "set":
+		if len(args) != 1 && len(args) != 2 {
+			return fmt.Errorf("usage: goapp secrets set <name> [value]")
+		}
+		value := ""
+		if len(args) == 2 {
+			value = args[1]
+		} else if value, err = readSecretValue(os.Stdin); err != nil {
+			return err
+		}
+		store.Set(args[0], value)
+		return saveSecrets(store, fmt.Sprintf("Secret %q saved", args[0]))
+	case "delete":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: goapp secrets delete <name>")
+		}
+		if !store.Delete(args[0]) {
+			return fmt.Errorf("secret %q not found", args[0])
+		}
+		return saveSecrets(store, fmt.Sprintf("Secret %q deleted", args[0]))
+	case "edit":
+		values, err := editSecrets(store.Values())
+		if err != nil {
+			return err
+		}
+		store.Replace(values)
+		return saveSecrets(store, fmt.Sprintf("%d secrets saved", len(values)))
+	case "encrypt":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: goapp secrets encrypt <file>")
+		}
+		values, err := readSecretsJSON(args[0])
+		if err != nil {
+			return err
+		}
+		store.Replace(values)
+		return saveSecrets(store, fmt.Sprintf("%d secrets encrypted into %s", len(values), *file))
+	default:
+		flagSet.Usage()
+		return fmt.Errorf("unknown secrets command %q", command)
+	}
+}
+
+// defaultSecretsFile returns the secrets file from the environment or the
+// default configuration
+func defaultSecretsFile() string {
+	if file := os.Getenv(app.EnvPrefix + "SECRETS_FILE"); file != "" {
+		return file
+	}
+	return app.DefaultConfig().Secrets.File
+}
+
+// saveSecrets writes the store and reports success
+func saveSecrets(store *app.SecretStore, message string) error {
+	if err := store.Save(); err != nil {
+		return err
+	}
+	fmt.Println(message)
+	return nil
+}
+
+// readSecretValue reads a single secret value from r, without the trailing newline
+func readSecretValue(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("error reading secret value: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readSecretsJSON reads a JSON object of secret names to values
+func readSecretsJSON(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading secrets: %w", err)
+	}
+	values := make(map[string]string)
+	if err := json.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("secrets must be a JSON object of strings: %w", err)
+	}
+	return values, nil
+}
+
+// editSecrets opens the secrets as JSON in $EDITOR and returns the result.
+// The plaintext copy is only readable by the current user and removed after.
+func editSecrets(values map[string]string) (map[string]string, error) {
+	temp, err := os.CreateTemp("", "goapp-secrets-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	encoder := json.NewEncoder(temp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(values); err != nil {
+		temp.Close()
+		return nil, fmt.Errorf("error writing temporary file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return nil, fmt.Errorf("error writing temporary file: %w", err)
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.Command(editor[0], append(editor[1:], temp.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+
+	return readSecretsJSON(temp.Name())
+}
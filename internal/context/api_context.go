@@ -76,9 +76,9 @@ func errorToHTTPStatus(code errors.ErrorCode) int {
 		switch code {
 		case errors.BadRequest:
 			return http.StatusBadRequest
-		case errors.Unauthorized:
+		case errors.Unauthorized, errors.InvalidToken, errors.ExpiredToken:
 			return http.StatusUnauthorized
-		case errors.Forbidden:
+		case errors.Forbidden, errors.EmailNotVerified:
 			return http.StatusForbidden
 		case errors.NotFound:
 			return http.StatusNotFound
@@ -0,0 +1,53 @@
+package context
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// UserIDKey is the context key holding the authenticated user's ID
+	UserIDKey = "user_id"
+
+	authUserKey = "auth_user"
+)
+
+// AuthUser is the authenticated principal attached to a request
+type AuthUser struct {
+	ID        int64
+	Username  string
+	IsAdmin   bool     // as claimed by the credential; permission checks read it fresh
+	SessionID string   // set when authenticated with an access token
+	APIKeyID  int64    // set when authenticated with an API key
+	Scopes    []string // permissions the API key is limited to
+}
+
+// AllowsScope reports whether the credential used may exercise a permission.
+// Access tokens carry the user's full permissions; API keys only their scopes.
+func (u *AuthUser) AllowsScope(permission string) bool {
+	if u.APIKeyID == 0 {
+		return true
+	}
+	for _, scope := range u.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAuthUser stores the authenticated user in the context
+func SetAuthUser(c *gin.Context, user *AuthUser) {
+	c.Set(authUserKey, user)
+	c.Set(UserIDKey, user.ID)
+}
+
+// GetAuthUser returns the authenticated user from the context
+func GetAuthUser(c *gin.Context) (*AuthUser, bool) {
+	value, exists := c.Get(authUserKey)
+	if !exists {
+		return nil, false
+	}
+
+	user, ok := value.(*AuthUser)
+	return user, ok
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"strconv"
+
+	"goapp/internal/app"
+	"goapp/internal/app/errors"
+	"goapp/internal/context"
+	"goapp/internal/dto"
+	"goapp/internal/middleware"
+	"goapp/internal/models"
+	"goapp/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyController handles HTTP requests for a user's API keys
+type APIKeyController struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyController creates a new APIKeyController
+func NewAPIKeyController() *APIKeyController {
+	return &APIKeyController{
+		apiKeyService: services.NewAPIKeyService(),
+	}
+}
+
+// Register adds API key routes to the router group
+func (c *APIKeyController) Register(router *gin.RouterGroup) {
+	keys := router.Group("/users/me/api-keys", middleware.AuthMiddleware(), middleware.RejectAPIKeys())
+	{
+		keys.GET("", c.ListAPIKeys)
+		keys.POST("", c.CreateAPIKey)
+		keys.DELETE("/:key_id", c.RevokeAPIKey)
+	}
+}
+
+// ListAPIKeys handles requests to list the caller's API keys
+func (c *APIKeyController) ListAPIKeys(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	authUser, _ := context.GetAuthUser(ctx)
+
+	keys, err := c.apiKeyService.ListKeys(authUser.ID)
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to list API keys", "error", err, "user_id", authUser.ID)
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to retrieve API keys")
+		return
+	}
+
+	responses := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = toAPIKeyResponse(key)
+	}
+
+	apiCtx.Success(gin.H{"api_keys": responses})
+}
+
+// CreateAPIKey handles requests to issue a new API key
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.APIKeyCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	authUser, _ := context.GetAuthUser(ctx)
+	key, plaintext, err := c.apiKeyService.CreateKey(authUser.ID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		app.WarnContext(ctx, "Failed to create API key", "error", err, "user_id", authUser.ID)
+		switch {
+		case stderrors.Is(err, services.ErrUnknownPermission),
+			stderrors.Is(err, services.ErrAPIKeyExpiryInPast):
+			apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to create API key")
+		}
+		return
+	}
+
+	apiCtx.Success(dto.APIKeyCreateResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            plaintext,
+	})
+}
+
+// RevokeAPIKey handles requests to revoke one of the caller's API keys
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	keyID, err := strconv.ParseInt(ctx.Param("key_id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid API key ID")
+		return
+	}
+
+	authUser, _ := context.GetAuthUser(ctx)
+	if err := c.apiKeyService.RevokeKey(authUser.ID, keyID); err != nil {
+		app.WarnContext(ctx, "Failed to revoke API key", "error", err, "user_id", authUser.ID, "api_key_id", keyID)
+		switch {
+		case stderrors.Is(err, services.ErrAPIKeyNotFound):
+			apiCtx.ErrorWithCode(errors.NotFound, "API key not found")
+		case stderrors.Is(err, services.ErrAPIKeyAlreadyRevoked):
+			apiCtx.ErrorWithCode(errors.Conflict, err.Error())
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to revoke API key")
+		}
+		return
+	}
+
+	apiCtx.Success(gin.H{"message": "API key revoked"})
+}
+
+// toAPIKeyResponse converts an API key model to its response DTO
+func toAPIKeyResponse(key *models.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"goapp/internal/app/errors"
 	"goapp/internal/context"
 	"goapp/internal/dto"
+	"goapp/internal/middleware"
 	"goapp/internal/models"
 	"goapp/internal/services"
 
@@ -31,11 +32,14 @@ func (c *ProductController) Register(router *gin.RouterGroup) {
 	{
 		products.GET("", c.ListProducts)
 		products.GET("/:id", c.GetProduct)
-		products.POST("", c.CreateProduct)
-		products.PUT("/:id", c.UpdateProduct)
-		products.DELETE("/:id", c.DeleteProduct)
-		products.PUT("/:id/stock", c.UpdateProductStock)
 		products.GET("/category/:id", c.ListProductsByCategory)
+
+		// Mutations require the product:write permission
+		writes := products.Group("", middleware.AuthMiddleware(), middleware.RequirePermission(models.PermProductWrite))
+		writes.POST("", c.CreateProduct)
+		writes.PUT("/:id", c.UpdateProduct)
+		writes.DELETE("/:id", c.DeleteProduct)
+		writes.PUT("/:id/stock", c.UpdateProductStock)
 	}
 }
 
@@ -48,7 +52,7 @@ func (c *ProductController) ListProducts(ctx *gin.Context) {
 		return
 	}
 
-	products, err := c.productService.ListProducts(pagination.Page, pagination.PageSize)
+	products, err := c.productService.ListProducts(ctx.Request.Context(), pagination.Page, pagination.PageSize)
 	if err != nil {
 		app.ErrorContext(ctx, "Failed to list products", "error", err)
 		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to retrieve products")
@@ -90,7 +94,7 @@ func (c *ProductController) GetProduct(ctx *gin.Context) {
 		return
 	}
 
-	product, err := c.productService.GetProduct(id)
+	product, err := c.productService.GetProduct(ctx.Request.Context(), id)
 	if err != nil {
 		app.ErrorContext(ctx, "Failed to get product", "error", err, "id", id)
 		apiCtx.ErrorWithCode(errors.NotFound, "Product not found")
@@ -129,7 +133,7 @@ func (c *ProductController) CreateProduct(ctx *gin.Context) {
 		CategoryID:  req.CategoryID,
 	}
 
-	if err := c.productService.CreateProduct(product); err != nil {
+	if err := c.productService.CreateProduct(ctx.Request.Context(), product); err != nil {
 		app.ErrorContext(ctx, "Failed to create product", "error", err)
 		apiCtx.ErrorWithCode(errors.BadRequest, err.Error())
 		return
@@ -165,36 +169,44 @@ func (c *ProductController) UpdateProduct(ctx *gin.Context) {
 	}
 
 	// Get existing product
-	product, err := c.productService.GetProduct(id)
+	product, err := c.productService.GetProductForUpdate(ctx.Request.Context(), id)
 	if err != nil {
 		apiCtx.ErrorWithCode(errors.NotFound, "Product not found")
 		return
 	}
 
-	// Update fields if provided
+	// Update fields if provided, writing only those back
+	var columns []string
 	if req.Name != nil {
 		product.Name = *req.Name
+		columns = append(columns, "name")
 	}
 	if req.Description != nil {
 		product.Description = *req.Description
+		columns = append(columns, "description")
 	}
 	if req.Price != nil {
 		product.Price = *req.Price
+		columns = append(columns, "price")
 	}
 	if req.SKU != nil {
 		product.SKU = *req.SKU
+		columns = append(columns, "sku")
 	}
 	if req.Stock != nil {
 		product.Stock = *req.Stock
+		columns = append(columns, "stock")
 	}
 	if req.CategoryID != nil {
 		product.CategoryID = *req.CategoryID
+		columns = append(columns, "category_id")
 	}
 	if req.IsActive != nil {
 		product.IsActive = *req.IsActive
+		columns = append(columns, "is_active")
 	}
 
-	if err := c.productService.UpdateProduct(product); err != nil {
+	if err := c.productService.UpdateProduct(ctx.Request.Context(), product, columns...); err != nil {
 		app.ErrorContext(ctx, "Failed to update product", "error", err, "id", id)
 		apiCtx.ErrorWithCode(errors.BadRequest, err.Error())
 		return
@@ -223,7 +235,7 @@ func (c *ProductController) DeleteProduct(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.productService.DeleteProduct(id); err != nil {
+	if err := c.productService.DeleteProduct(ctx.Request.Context(), id); err != nil {
 		app.ErrorContext(ctx, "Failed to delete product", "error", err, "id", id)
 		apiCtx.ErrorWithCode(errors.NotFound, "Product not found")
 		return
@@ -247,7 +259,7 @@ func (c *ProductController) UpdateProductStock(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.productService.UpdateProductStock(id, req.Quantity); err != nil {
+	if err := c.productService.UpdateProductStock(ctx.Request.Context(), id, req.Quantity); err != nil {
 		app.ErrorContext(ctx, "Failed to update product stock", "error", err, "id", id)
 		apiCtx.ErrorWithCode(errors.BadRequest, err.Error())
 		return
@@ -265,7 +277,7 @@ func (c *ProductController) ListProductsByCategory(ctx *gin.Context) {
 		return
 	}
 
-	products, err := c.productService.ListProductsByCategory(id)
+	products, err := c.productService.ListProductsByCategory(ctx.Request.Context(), id)
 	if err != nil {
 		app.ErrorContext(ctx, "Failed to list products by category", "error", err, "category_id", id)
 		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to retrieve products")
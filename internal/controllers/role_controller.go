@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"strconv"
+
+	"goapp/internal/app"
+	"goapp/internal/app/errors"
+	"goapp/internal/context"
+	"goapp/internal/dto"
+	"goapp/internal/models"
+	"goapp/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleController handles HTTP requests for role management
+type RoleController struct {
+	roleService *services.RoleService
+}
+
+// NewRoleController creates a new RoleController
+func NewRoleController() *RoleController {
+	return &RoleController{
+		roleService: services.NewRoleService(),
+	}
+}
+
+// Register adds role management routes to the router group
+func (c *RoleController) Register(router *gin.RouterGroup) {
+	roles := router.Group("/roles")
+	{
+		roles.GET("", c.ListRoles)
+		roles.POST("", c.CreateRole)
+		roles.GET("/:id", c.GetRole)
+		roles.PUT("/:id", c.UpdateRole)
+		roles.DELETE("/:id", c.DeleteRole)
+	}
+
+	router.GET("/permissions", c.ListPermissions)
+
+	users := router.Group("/users/:id/roles")
+	{
+		users.GET("", c.GetUserRoles)
+		users.POST("", c.AssignRole)
+		users.DELETE("/:role_id", c.RevokeRole)
+	}
+}
+
+// ListRoles handles requests to list all roles
+func (c *RoleController) ListRoles(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+
+	roles, err := c.roleService.ListRoles()
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to list roles", "error", err)
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to retrieve roles")
+		return
+	}
+
+	apiCtx.Success(gin.H{"roles": toRoleResponses(roles)})
+}
+
+// GetRole handles requests to get a specific role
+func (c *RoleController) GetRole(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid role ID")
+		return
+	}
+
+	role, err := c.roleService.GetRole(id)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.NotFound, "Role not found")
+		return
+	}
+
+	apiCtx.Success(toRoleResponse(role))
+}
+
+// CreateRole handles role creation requests
+func (c *RoleController) CreateRole(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.RoleCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	role, err := c.roleService.CreateRole(req.Name, req.Description, req.Permissions)
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to create role", "error", err, "name", req.Name)
+		if stderrors.Is(err, services.ErrRoleNameTaken) {
+			apiCtx.ErrorWithCode(errors.Conflict, err.Error())
+			return
+		}
+		apiCtx.ErrorWithCode(errors.BadRequest, err.Error())
+		return
+	}
+
+	app.InfoContext(ctx, "Role created", "role_id", role.ID, "name", role.Name)
+	apiCtx.Success(toRoleResponse(role))
+}
+
+// UpdateRole handles requests to update a role
+func (c *RoleController) UpdateRole(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid role ID")
+		return
+	}
+
+	var req dto.RoleUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	role, err := c.roleService.UpdateRole(id, req.Description, req.Permissions)
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to update role", "error", err, "id", id)
+		if stderrors.Is(err, services.ErrRoleNotFound) {
+			apiCtx.ErrorWithCode(errors.NotFound, "Role not found")
+			return
+		}
+		apiCtx.ErrorWithCode(errors.BadRequest, err.Error())
+		return
+	}
+
+	app.InfoContext(ctx, "Role updated", "role_id", role.ID, "permissions", role.PermissionNames())
+	apiCtx.Success(toRoleResponse(role))
+}
+
+// DeleteRole handles requests to delete a role
+func (c *RoleController) DeleteRole(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid role ID")
+		return
+	}
+
+	if err := c.roleService.DeleteRole(id); err != nil {
+		app.ErrorContext(ctx, "Failed to delete role", "error", err, "id", id)
+		apiCtx.ErrorWithCode(errors.NotFound, "Role not found")
+		return
+	}
+
+	app.InfoContext(ctx, "Role deleted", "role_id", id)
+	apiCtx.Success(gin.H{"message": "Role deleted successfully"})
+}
+
+// ListPermissions handles requests to list the available permissions
+func (c *RoleController) ListPermissions(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	apiCtx.Success(gin.H{"permissions": c.roleService.ListPermissions()})
+}
+
+// GetUserRoles handles requests to list the roles of a user
+func (c *RoleController) GetUserRoles(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid user ID")
+		return
+	}
+
+	roles, err := c.roleService.GetUserRoles(userID)
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to get user roles", "error", err, "user_id", userID)
+		apiCtx.ErrorWithCode(errors.NotFound, "User not found")
+		return
+	}
+
+	apiCtx.Success(gin.H{"roles": toRoleResponses(roles)})
+}
+
+// AssignRole handles requests to assign a role to a user
+func (c *RoleController) AssignRole(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid user ID")
+		return
+	}
+
+	var req dto.UserRoleAssignRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	if err := c.roleService.AssignRole(userID, req.RoleID); err != nil {
+		app.ErrorContext(ctx, "Failed to assign role", "error", err, "user_id", userID, "role_id", req.RoleID)
+		if stderrors.Is(err, services.ErrUserNotFound) || stderrors.Is(err, services.ErrRoleNotFound) {
+			apiCtx.ErrorWithCode(errors.NotFound, err.Error())
+			return
+		}
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to assign role")
+		return
+	}
+
+	app.InfoContext(ctx, "Role assigned", "user_id", userID, "role_id", req.RoleID)
+	apiCtx.Success(gin.H{"message": "Role assigned successfully"})
+}
+
+// RevokeRole handles requests to remove a role from a user
+func (c *RoleController) RevokeRole(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid user ID")
+		return
+	}
+	roleID, err := strconv.ParseInt(ctx.Param("role_id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid role ID")
+		return
+	}
+
+	if err := c.roleService.RevokeRole(userID, roleID); err != nil {
+		app.ErrorContext(ctx, "Failed to revoke role", "error", err, "user_id", userID, "role_id", roleID)
+		apiCtx.ErrorWithCode(errors.NotFound, "Role assignment not found")
+		return
+	}
+
+	app.InfoContext(ctx, "Role revoked", "user_id", userID, "role_id", roleID)
+	apiCtx.Success(gin.H{"message": "Role revoked successfully"})
+}
+
+// toRoleResponse converts a role model to its response DTO
+func toRoleResponse(role *models.Role) dto.RoleResponse {
+	return dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.PermissionNames(),
+	}
+}
+
+// toRoleResponses converts role models to response DTOs
+func toRoleResponses(roles []*models.Role) []dto.RoleResponse {
+	responses := make([]dto.RoleResponse, len(roles))
+	for i, role := range roles {
+		responses[i] = toRoleResponse(role)
+	}
+	return responses
+}
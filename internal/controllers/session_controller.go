@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	stderrors "errors"
+	"strconv"
+
+	"goapp/internal/app"
+	"goapp/internal/app/errors"
+	"goapp/internal/context"
+	"goapp/internal/dto"
+	"goapp/internal/middleware"
+	"goapp/internal/models"
+	"goapp/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionController handles HTTP requests for user sessions
+type SessionController struct {
+	sessionService *services.SessionService
+}
+
+// NewSessionController creates a new SessionController
+func NewSessionController() *SessionController {
+	return &SessionController{
+		sessionService: services.NewSessionService(),
+	}
+}
+
+// Register adds session routes to the router group
+func (c *SessionController) Register(router *gin.RouterGroup) {
+	sessions := router.Group("/users/me/sessions", middleware.AuthMiddleware(), middleware.RejectAPIKeys())
+	{
+		sessions.GET("", c.ListSessions)
+		sessions.DELETE("/:session_id", c.RevokeSession)
+	}
+
+	router.DELETE("/users/:id/sessions",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission(models.PermUserWrite),
+		c.RevokeUserSessions,
+	)
+}
+
+// ListSessions handles requests to list the caller's active sessions
+func (c *SessionController) ListSessions(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	authUser, _ := context.GetAuthUser(ctx)
+
+	sessions, err := c.sessionService.ListSessions(authUser.ID)
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to list sessions", "error", err, "user_id", authUser.ID)
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to retrieve sessions")
+		return
+	}
+
+	responses := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = dto.SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			Current:    session.ID == authUser.SessionID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		}
+	}
+
+	apiCtx.Success(gin.H{"sessions": responses})
+}
+
+// RevokeSession handles requests to sign one of the caller's sessions out
+func (c *SessionController) RevokeSession(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	authUser, _ := context.GetAuthUser(ctx)
+	sessionID := ctx.Param("session_id")
+
+	if err := c.sessionService.RevokeSession(authUser.ID, sessionID); err != nil {
+		app.WarnContext(ctx, "Failed to revoke session", "error", err, "user_id", authUser.ID, "session_id", sessionID)
+		if stderrors.Is(err, services.ErrSessionNotFound) {
+			apiCtx.ErrorWithCode(errors.NotFound, "Session not found")
+			return
+		}
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to revoke session")
+		return
+	}
+
+	apiCtx.Success(gin.H{"message": "Session revoked"})
+}
+
+// RevokeUserSessions handles admin requests to sign a user out everywhere
+func (c *SessionController) RevokeUserSessions(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Invalid user ID")
+		return
+	}
+
+	count, err := c.sessionService.RevokeAllSessions(id)
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to revoke user sessions", "error", err, "user_id", id)
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to revoke sessions")
+		return
+	}
+
+	authUser, _ := context.GetAuthUser(ctx)
+	app.WarnContext(ctx, "User force-logged out", "user_id", id, "by", authUser.ID, "sessions", count)
+	apiCtx.Success(gin.H{
+		"message":  "Sessions revoked",
+		"sessions": count,
+	})
+}
@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	stderrors "errors"
+	"net/http"
 	"strconv"
 
 	"goapp/internal/app"
 	"goapp/internal/app/errors"
 	"goapp/internal/context"
 	"goapp/internal/dto"
+	"goapp/internal/middleware"
 	"goapp/internal/models"
 	"goapp/internal/services"
 
@@ -15,13 +18,23 @@ import (
 
 // UserController handles HTTP requests for user operations
 type UserController struct {
-	userService *services.UserService
+	userService         *services.UserService
+	authService         *services.AuthService
+	resetService        *services.PasswordResetService
+	verificationService *services.EmailVerificationService
+	mfaService          *services.MFAService
+	oidcService         *services.OIDCService
 }
 
 // NewUserController creates a new UserController
 func NewUserController() *UserController {
 	return &UserController{
-		userService: services.NewUserService(),
+		userService:         services.NewUserService(),
+		authService:         services.NewAuthService(),
+		resetService:        services.NewPasswordResetService(),
+		verificationService: services.NewEmailVerificationService(),
+		mfaService:          services.NewMFAService(),
+		oidcService:         services.NewOIDCService(),
 	}
 }
 
@@ -29,13 +42,31 @@ func NewUserController() *UserController {
 func (c *UserController) Register(router *gin.RouterGroup) {
 	users := router.Group("/users")
 	{
-		users.POST("", c.CreateUser)
-		users.GET("", c.ListUsers)
-		users.GET("/:id", c.GetUser)
-		users.PUT("/:id", c.UpdateUser)
-		users.DELETE("/:id", c.DeleteUser)
+		// Public user endpoints
+		users.POST("/register", c.CreateUser)
 		users.POST("/login", c.Login)
-		users.PUT("/:id/password", c.UpdatePassword)
+		users.POST("/login/mfa", c.LoginMFA)
+		users.GET("/oidc/:provider/login", c.OIDCLogin)
+		users.GET("/oidc/:provider/callback", c.OIDCCallback)
+		users.POST("/token/refresh", c.RefreshToken)
+		users.POST("/password/forgot", c.ForgotPassword)
+		users.POST("/password/reset", c.ResetPassword)
+		users.GET("/verify", c.VerifyEmail)
+		users.POST("/verify/resend", c.ResendVerification)
+
+		// Protected user endpoints
+		protected := users.Group("", middleware.AuthMiddleware())
+		{
+			protected.POST("/logout", c.Logout)
+			protected.POST("/me/mfa/enroll", middleware.RejectAPIKeys(), c.EnrollMFA)
+			protected.POST("/me/mfa/confirm", middleware.RejectAPIKeys(), c.ConfirmMFA)
+			protected.POST("/me/mfa/disable", middleware.RejectAPIKeys(), c.DisableMFA)
+			protected.GET("", middleware.RequirePermission(models.PermUserRead), c.ListUsers)
+			protected.GET("/:id", middleware.RequireOwnerOrPermission("id", models.PermUserRead), c.GetUser)
+			protected.PUT("/:id", middleware.RequireOwnerOrPermission("id", models.PermUserWrite), c.UpdateUser)
+			protected.PUT("/:id/password", middleware.RequireOwnerOrPermission("id", models.PermUserWrite), c.UpdatePassword)
+			protected.DELETE("/:id", middleware.RequirePermission(models.PermUserDelete), c.DeleteUser)
+		}
 	}
 }
 
@@ -62,14 +93,21 @@ func (c *UserController) CreateUser(ctx *gin.Context) {
 		return
 	}
 
+	// The account exists either way; a failed email can be resent later
+	if err := c.verificationService.SendVerification(user); err != nil {
+		app.ErrorContext(ctx, "Failed to send verification email", "error", err, "user_id", user.ID)
+	}
+
 	response := dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  user.IsActive,
-		IsAdmin:   user.IsAdmin,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		IsActive:      user.IsActive,
+		IsAdmin:       user.IsAdmin,
+		EmailVerified: user.EmailVerified,
+		MFAEnabled:    user.MFAEnabled,
 	}
 
 	apiCtx.Success(response)
@@ -84,7 +122,7 @@ func (c *UserController) ListUsers(ctx *gin.Context) {
 		return
 	}
 
-	users, err := c.userService.ListUsers(pagination.Page, pagination.PageSize)
+	users, err := c.userService.ListUsers(ctx.Request.Context(), pagination.Page, pagination.PageSize)
 	if err != nil {
 		app.ErrorContext(ctx, "Failed to list users", "error", err)
 		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to retrieve users")
@@ -95,13 +133,15 @@ func (c *UserController) ListUsers(ctx *gin.Context) {
 	userResponses := make([]dto.UserResponse, len(users))
 	for i, user := range users {
 		userResponses[i] = dto.UserResponse{
-			ID:        user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			IsActive:  user.IsActive,
-			IsAdmin:   user.IsAdmin,
+			ID:            user.ID,
+			Username:      user.Username,
+			Email:         user.Email,
+			FirstName:     user.FirstName,
+			LastName:      user.LastName,
+			IsActive:      user.IsActive,
+			IsAdmin:       user.IsAdmin,
+			EmailVerified: user.EmailVerified,
+			MFAEnabled:    user.MFAEnabled,
 		}
 	}
 
@@ -125,7 +165,7 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := c.userService.GetUser(id)
+	user, err := c.userService.GetUser(ctx.Request.Context(), id)
 	if err != nil {
 		app.ErrorContext(ctx, "Failed to get user", "error", err, "id", id)
 		apiCtx.ErrorWithCode(errors.NotFound, "User not found")
@@ -133,13 +173,15 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 	}
 
 	response := dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  user.IsActive,
-		IsAdmin:   user.IsAdmin,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		IsActive:      user.IsActive,
+		IsAdmin:       user.IsAdmin,
+		EmailVerified: user.EmailVerified,
+		MFAEnabled:    user.MFAEnabled,
 	}
 
 	apiCtx.Success(response)
@@ -160,13 +202,21 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
+	// Only admins may activate or deactivate accounts
+	if req.IsActive != nil && !middleware.HasPermission(ctx, models.PermUserWrite) {
+		middleware.DenyAccess(ctx, "is_active is admin-only", "target_id", id)
+		return
+	}
+
 	// Get existing user
-	user, err := c.userService.GetUser(id)
+	user, err := c.userService.GetUser(ctx.Request.Context(), id)
 	if err != nil {
 		apiCtx.ErrorWithCode(errors.NotFound, "User not found")
 		return
 	}
 
+	wasVerified := user.EmailVerified
+
 	// Update fields if provided
 	if req.Username != nil {
 		user.Username = *req.Username
@@ -190,14 +240,22 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
+	if wasVerified && !user.EmailVerified {
+		if err := c.verificationService.SendVerification(user); err != nil {
+			app.ErrorContext(ctx, "Failed to send verification email", "error", err, "user_id", user.ID)
+		}
+	}
+
 	response := dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		IsActive:  user.IsActive,
-		IsAdmin:   user.IsAdmin,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		IsActive:      user.IsActive,
+		IsAdmin:       user.IsAdmin,
+		EmailVerified: user.EmailVerified,
+		MFAEnabled:    user.MFAEnabled,
 	}
 
 	apiCtx.Success(response)
@@ -232,32 +290,211 @@ func (c *UserController) Login(ctx *gin.Context) {
 		return
 	}
 
-	user, err := c.userService.AuthenticateUser(req.Login, req.Password)
+	user, err := c.userService.AuthenticateUser(req.Login, req.Password, ctx.ClientIP())
 	if err != nil {
-		app.ErrorContext(ctx, "Login failed", "error", err, "login", req.Login)
-		apiCtx.ErrorWithCode(errors.Unauthorized, "Invalid credentials")
+		app.WarnContext(ctx, "Login failed", "error", err, "login", req.Login, "ip", ctx.ClientIP())
+		switch {
+		case stderrors.Is(err, services.ErrEmailNotVerified):
+			apiCtx.ErrorWithCode(errors.EmailNotVerified, "Please verify your email address before logging in")
+		case stderrors.Is(err, services.ErrLoginLocked):
+			apiCtx.ErrorWithCode(errors.TooManyReq, "Too many failed login attempts, please try again later")
+		case stderrors.Is(err, services.ErrInvalidCredentials):
+			apiCtx.ErrorWithCode(errors.Unauthorized, "Invalid credentials")
+		default:
+			app.ErrorContext(ctx, "Login error", "error", err)
+			apiCtx.ErrorWithCode(errors.InternalServer, "Login failed")
+		}
 		return
 	}
 
-	// In a real application, you would generate a JWT token here
-	token := "dummy-jwt-token"
+	c.beginSession(ctx, user)
+}
+
+// OIDCLogin handles requests to sign in through an external identity provider
+func (c *UserController) OIDCLogin(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	providerName := ctx.Param("provider")
+
+	authURL, err := c.oidcService.BeginLogin(ctx.Request.Context(), providerName)
+	if err != nil {
+		if stderrors.Is(err, services.ErrUnknownOIDCProvider) {
+			apiCtx.ErrorWithCode(errors.NotFound, err.Error())
+			return
+		}
+		app.ErrorContext(ctx, "Failed to start OIDC login", "error", err, "provider", providerName)
+		apiCtx.ErrorWithCode(errors.ThirdParty, "Identity provider is unavailable")
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback handles the identity provider's redirect back after sign-in
+func (c *UserController) OIDCCallback(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	providerName := ctx.Param("provider")
+
+	if providerError := ctx.Query("error"); providerError != "" {
+		app.WarnContext(ctx, "Identity provider returned an error", "provider", providerName, "error", providerError, "description", ctx.Query("error_description"))
+		apiCtx.ErrorWithCode(errors.Unauthorized, "Sign-in was not completed at the identity provider")
+		return
+	}
+
+	user, err := c.oidcService.CompleteLogin(ctx.Request.Context(), providerName, ctx.Query("state"), ctx.Query("code"))
+	if err != nil {
+		app.WarnContext(ctx, "OIDC login failed", "error", err, "provider", providerName, "ip", ctx.ClientIP())
+		switch {
+		case stderrors.Is(err, services.ErrUnknownOIDCProvider):
+			apiCtx.ErrorWithCode(errors.NotFound, err.Error())
+		case stderrors.Is(err, services.ErrInvalidOIDCState):
+			apiCtx.ErrorWithCode(errors.BadRequest, err.Error())
+		case stderrors.Is(err, services.ErrOIDCLoginFailed),
+			stderrors.Is(err, services.ErrOIDCNoAccount),
+			stderrors.Is(err, services.ErrOIDCEmailUnverified):
+			apiCtx.ErrorWithCode(errors.Unauthorized, services.ErrOIDCLoginFailed.Error())
+		case stderrors.Is(err, services.ErrOIDCAccountDisabled):
+			apiCtx.ErrorWithCode(errors.Forbidden, err.Error())
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to complete sign-in")
+		}
+		return
+	}
+
+	c.beginSession(ctx, user)
+}
+
+// beginSession finishes the first login step: accounts with MFA get a
+// challenge, everyone else gets tokens
+func (c *UserController) beginSession(ctx *gin.Context, user *models.User) {
+	apiCtx := context.GetAPIContext(ctx)
+	if user.MFAEnabled {
+		challengeToken, expiresAt, err := c.mfaService.Challenge(user)
+		if err != nil {
+			app.ErrorContext(ctx, "Failed to issue MFA challenge", "error", err, "user_id", user.ID)
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to issue login challenge")
+			return
+		}
+
+		apiCtx.Success(dto.MFAChallengeResponse{
+			MFARequired:    true,
+			ChallengeToken: challengeToken,
+			ExpiresAt:      expiresAt.Unix(),
+		})
+		return
+	}
+
+	c.completeLogin(ctx, user)
+}
+
+// LoginMFA handles the second login step for accounts with MFA enabled
+func (c *UserController) LoginMFA(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.MFALoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	user, err := c.mfaService.VerifyChallenge(req.ChallengeToken, req.Code)
+	if err != nil {
+		app.WarnContext(ctx, "MFA login failed", "error", err, "ip", ctx.ClientIP())
+		switch {
+		case stderrors.Is(err, services.ErrInvalidMFAChallenge):
+			apiCtx.ErrorWithCode(errors.InvalidToken, err.Error())
+		case stderrors.Is(err, services.ErrTooManyMFAAttempts):
+			apiCtx.ErrorWithCode(errors.TooManyReq, "Too many attempts, please try again later")
+		case stderrors.Is(err, services.ErrInvalidMFACode):
+			apiCtx.ErrorWithCode(errors.Unauthorized, err.Error())
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to verify authentication code")
+		}
+		return
+	}
+
+	c.completeLogin(ctx, user)
+}
+
+// completeLogin issues tokens to a fully authenticated user
+func (c *UserController) completeLogin(ctx *gin.Context, user *models.User) {
+	apiCtx := context.GetAPIContext(ctx)
+	tokens, err := c.authService.IssueTokens(user, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		app.ErrorContext(ctx, "Failed to issue tokens", "error", err, "user_id", user.ID)
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to issue tokens")
+		return
+	}
 
 	response := dto.UserLoginResponse{
 		User: dto.UserResponse{
-			ID:        user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			IsActive:  user.IsActive,
-			IsAdmin:   user.IsAdmin,
+			ID:            user.ID,
+			Username:      user.Username,
+			Email:         user.Email,
+			FirstName:     user.FirstName,
+			LastName:      user.LastName,
+			IsActive:      user.IsActive,
+			IsAdmin:       user.IsAdmin,
+			EmailVerified: user.EmailVerified,
+			MFAEnabled:    user.MFAEnabled,
 		},
-		Token: token,
+		Token:                 tokens.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresAt:             tokens.AccessExpiresAt.Unix(),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshExpiresAt.Unix(),
 	}
 
 	apiCtx.Success(response)
 }
 
+// RefreshToken handles requests to rotate a refresh token
+func (c *UserController) RefreshToken(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	tokens, err := c.authService.Refresh(req.RefreshToken, ctx.ClientIP())
+	if err != nil {
+		app.WarnContext(ctx, "Token refresh failed", "error", err)
+		apiCtx.ErrorWithCode(errors.InvalidToken, "Invalid refresh token")
+		return
+	}
+
+	apiCtx.Success(dto.TokenResponse{
+		Token:                 tokens.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresAt:             tokens.AccessExpiresAt.Unix(),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: tokens.RefreshExpiresAt.Unix(),
+	})
+}
+
+// Logout handles requests to revoke the caller's refresh token
+func (c *UserController) Logout(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	authUser, ok := context.GetAuthUser(ctx)
+	if !ok {
+		apiCtx.ErrorWithCode(errors.Unauthorized, "Authentication required")
+		return
+	}
+
+	if err := c.authService.Logout(authUser.ID, req.RefreshToken); err != nil {
+		app.WarnContext(ctx, "Logout failed", "error", err, "user_id", authUser.ID)
+		apiCtx.ErrorWithCode(errors.InvalidToken, "Invalid refresh token")
+		return
+	}
+
+	apiCtx.Success(gin.H{"message": "Logged out successfully"})
+}
+
 // UpdatePassword handles password update requests
 func (c *UserController) UpdatePassword(ctx *gin.Context) {
 	apiCtx := context.GetAPIContext(ctx)
@@ -273,15 +510,222 @@ func (c *UserController) UpdatePassword(ctx *gin.Context) {
 		return
 	}
 
-	// Get user
-	user, err := c.userService.GetUser(id)
-	if err != nil {
-		apiCtx.ErrorWithCode(errors.NotFound, "User not found")
+	if err := c.userService.ChangePassword(id, req.CurrentPassword, req.NewPassword); err != nil {
+		app.WarnContext(ctx, "Password update failed", "error", err, "user_id", id)
+		switch {
+		case stderrors.Is(err, services.ErrUserNotFound):
+			apiCtx.ErrorWithCode(errors.NotFound, "User not found")
+		case stderrors.Is(err, services.ErrIncorrectPassword),
+			stderrors.Is(err, services.ErrWeakPassword),
+			stderrors.Is(err, services.ErrPasswordReused):
+			apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to update password")
+		}
 		return
 	}
 
-	// Verify current password and update with new password
-	// In a real application, you would implement this in the service layer
-	app.InfoContext(ctx, "Password update requested", "user_id", user.ID)
+	app.InfoContext(ctx, "Password updated", "user_id", id)
 	apiCtx.Success(gin.H{"message": "Password updated successfully"})
 }
+
+// ForgotPassword handles requests to send a password reset token
+func (c *UserController) ForgotPassword(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	if err := c.resetService.RequestReset(req.Email); err != nil {
+		if stderrors.Is(err, services.ErrTooManyResetRequests) {
+			app.WarnContext(ctx, "Password reset rate limited", "ip", ctx.ClientIP())
+			apiCtx.ErrorWithCode(errors.TooManyReq, "Too many password reset requests, please try again later")
+			return
+		}
+		app.ErrorContext(ctx, "Failed to request password reset", "error", err)
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to request password reset")
+		return
+	}
+
+	// Same response whether or not the address is registered
+	apiCtx.Success(gin.H{"message": "If the address is registered, a reset link has been sent"})
+}
+
+// ResetPassword handles requests to set a new password with a reset token
+func (c *UserController) ResetPassword(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	if err := c.resetService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		app.WarnContext(ctx, "Password reset failed", "error", err)
+		switch {
+		case stderrors.Is(err, services.ErrInvalidResetToken):
+			apiCtx.ErrorWithCode(errors.InvalidToken, err.Error())
+		case stderrors.Is(err, services.ErrWeakPassword),
+			stderrors.Is(err, services.ErrPasswordReused):
+			apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to reset password")
+		}
+		return
+	}
+
+	apiCtx.Success(gin.H{"message": "Password has been reset"})
+}
+
+// VerifyEmail handles email verification links
+func (c *UserController) VerifyEmail(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	token := ctx.Query("token")
+	if token == "" {
+		apiCtx.ErrorWithCode(errors.BadRequest, "Verification token is required")
+		return
+	}
+
+	user, err := c.verificationService.Verify(token)
+	if err != nil {
+		app.WarnContext(ctx, "Email verification failed", "error", err)
+		if stderrors.Is(err, services.ErrInvalidVerificationToken) {
+			apiCtx.ErrorWithCode(errors.InvalidToken, err.Error())
+			return
+		}
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to verify email address")
+		return
+	}
+
+	apiCtx.Success(gin.H{
+		"message": "Email address verified",
+		"user_id": user.ID,
+	})
+}
+
+// ResendVerification handles requests to send a new verification email
+func (c *UserController) ResendVerification(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	if err := c.verificationService.ResendVerification(req.Email); err != nil {
+		if stderrors.Is(err, services.ErrTooManyVerifyRequests) {
+			apiCtx.ErrorWithCode(errors.TooManyReq, "Too many verification requests, please try again later")
+			return
+		}
+		app.ErrorContext(ctx, "Failed to resend verification email", "error", err)
+		apiCtx.ErrorWithCode(errors.InternalServer, "Failed to resend verification email")
+		return
+	}
+
+	// Same response whether or not the address is registered
+	apiCtx.Success(gin.H{"message": "If the address needs verification, a new link has been sent"})
+}
+
+// EnrollMFA handles requests to start TOTP enrollment for the caller
+func (c *UserController) EnrollMFA(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	authUser, ok := context.GetAuthUser(ctx)
+	if !ok {
+		apiCtx.ErrorWithCode(errors.Unauthorized, "Authentication required")
+		return
+	}
+
+	enrollment, err := c.mfaService.BeginEnrollment(authUser.ID)
+	if err != nil {
+		app.WarnContext(ctx, "MFA enrollment failed", "error", err, "user_id", authUser.ID)
+		switch {
+		case stderrors.Is(err, services.ErrMFAAlreadyEnabled):
+			apiCtx.ErrorWithCode(errors.Conflict, err.Error())
+		case stderrors.Is(err, services.ErrUserNotFound):
+			apiCtx.ErrorWithCode(errors.NotFound, "User not found")
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to start MFA enrollment")
+		}
+		return
+	}
+
+	apiCtx.Success(dto.MFAEnrollResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+// ConfirmMFA handles requests to finish TOTP enrollment with a code
+func (c *UserController) ConfirmMFA(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.MFAConfirmRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	authUser, ok := context.GetAuthUser(ctx)
+	if !ok {
+		apiCtx.ErrorWithCode(errors.Unauthorized, "Authentication required")
+		return
+	}
+
+	codes, err := c.mfaService.ConfirmEnrollment(authUser.ID, req.Code)
+	if err != nil {
+		app.WarnContext(ctx, "MFA confirmation failed", "error", err, "user_id", authUser.ID)
+		switch {
+		case stderrors.Is(err, services.ErrMFAAlreadyEnabled):
+			apiCtx.ErrorWithCode(errors.Conflict, err.Error())
+		case stderrors.Is(err, services.ErrMFANotEnrolled),
+			stderrors.Is(err, services.ErrInvalidMFACode):
+			apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		case stderrors.Is(err, services.ErrTooManyMFAAttempts):
+			apiCtx.ErrorWithCode(errors.TooManyReq, "Too many attempts, please try again later")
+		case stderrors.Is(err, services.ErrUserNotFound):
+			apiCtx.ErrorWithCode(errors.NotFound, "User not found")
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to confirm MFA enrollment")
+		}
+		return
+	}
+
+	apiCtx.Success(dto.MFAConfirmResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA handles requests to turn off MFA for the caller
+func (c *UserController) DisableMFA(ctx *gin.Context) {
+	apiCtx := context.GetAPIContext(ctx)
+	var req dto.MFADisableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		apiCtx.ErrorWithCode(errors.Validation, err.Error())
+		return
+	}
+
+	authUser, ok := context.GetAuthUser(ctx)
+	if !ok {
+		apiCtx.ErrorWithCode(errors.Unauthorized, "Authentication required")
+		return
+	}
+
+	if err := c.mfaService.Disable(authUser.ID, req.Password, req.Code); err != nil {
+		app.WarnContext(ctx, "Disabling MFA failed", "error", err, "user_id", authUser.ID)
+		switch {
+		case stderrors.Is(err, services.ErrMFANotEnabled):
+			apiCtx.ErrorWithCode(errors.BadRequest, err.Error())
+		case stderrors.Is(err, services.ErrIncorrectPassword),
+			stderrors.Is(err, services.ErrInvalidMFACode):
+			apiCtx.ErrorWithCode(errors.Unauthorized, "Invalid credentials")
+		case stderrors.Is(err, services.ErrTooManyMFAAttempts):
+			apiCtx.ErrorWithCode(errors.TooManyReq, "Too many attempts, please try again later")
+		case stderrors.Is(err, services.ErrUserNotFound):
+			apiCtx.ErrorWithCode(errors.NotFound, "User not found")
+		default:
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to disable MFA")
+		}
+		return
+	}
+
+	apiCtx.Success(gin.H{"message": "Two-factor authentication disabled"})
+}
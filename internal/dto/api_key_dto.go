@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// APIKeyCreateRequest represents the data needed to create an API key
+type APIKeyCreateRequest struct {
+	Name      string     `json:"name" binding:"required,min=1,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"` // omit for a key that never expires
+}
+
+// APIKeyResponse represents the API key data to be returned in API responses
+type APIKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreateResponse includes the plaintext key, which is only shown once
+type APIKeyCreateResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
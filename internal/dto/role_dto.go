@@ -0,0 +1,28 @@
+package dto
+
+// RoleCreateRequest represents the data needed to create a new role
+type RoleCreateRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=100"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleUpdateRequest represents the data needed to update a role.
+// Omitting permissions leaves them unchanged; an empty list clears them.
+type RoleUpdateRequest struct {
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleResponse represents the role data to be returned in API responses
+type RoleResponse struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserRoleAssignRequest represents the data needed to assign a role to a user
+type UserRoleAssignRequest struct {
+	RoleID int64 `json:"role_id" binding:"required"`
+}
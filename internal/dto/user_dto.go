@@ -24,6 +24,22 @@ type UserUpdatePasswordRequest struct {
 	NewPassword     string `json:"new_password" binding:"required,min=8"`
 }
 
+// ForgotPasswordRequest represents the data needed to request a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest represents the data needed to reset a forgotten password
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// ResendVerificationRequest represents the data needed to resend a verification email
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
 // UserLoginRequest represents the data needed for user login
 type UserLoginRequest struct {
 	Login    string `json:"login" binding:"required"` // Can be email or username
@@ -32,13 +48,15 @@ type UserLoginRequest struct {
 
 // UserResponse represents the user data to be returned in API responses
 type UserResponse struct {
-	ID        int64  `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	IsActive  bool   `json:"is_active"`
-	IsAdmin   bool   `json:"is_admin"`
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	IsActive      bool   `json:"is_active"`
+	IsAdmin       bool   `json:"is_admin"`
+	EmailVerified bool   `json:"email_verified"`
+	MFAEnabled    bool   `json:"mfa_enabled"`
 }
 
 // UsersListResponse represents a paginated list of users
@@ -52,8 +70,61 @@ type UsersListResponse struct {
 
 // UserLoginResponse represents the response after successful login
 type UserLoginResponse struct {
-	User  UserResponse `json:"user"`
-	Token string       `json:"token"`
+	User                  UserResponse `json:"user"`
+	Token                 string       `json:"token"`
+	TokenType             string       `json:"token_type"`
+	ExpiresAt             int64        `json:"expires_at"`
+	RefreshToken          string       `json:"refresh_token"`
+	RefreshTokenExpiresAt int64        `json:"refresh_token_expires_at"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is required
+type MFAChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresAt      int64  `json:"expires_at"`
+}
+
+// MFALoginRequest represents the data needed to complete an MFA login
+type MFALoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+// MFAEnrollResponse carries a new TOTP secret for the user's authenticator app
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFAConfirmRequest represents the data needed to confirm MFA enrollment
+type MFAConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFAConfirmResponse carries the recovery codes issued on enrollment
+type MFAConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFADisableRequest represents the data needed to disable MFA
+type MFADisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP or recovery code
+}
+
+// RefreshTokenRequest carries a refresh token to be rotated or revoked
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse represents a newly issued token pair
+type TokenResponse struct {
+	Token                 string `json:"token"`
+	TokenType             string `json:"token_type"`
+	ExpiresAt             int64  `json:"expires_at"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresAt int64  `json:"refresh_token_expires_at"`
 }
 
 // PaginationRequest represents pagination parameters
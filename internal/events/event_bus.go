@@ -39,7 +39,7 @@ func (eb *EventBus) Subscribe(eventType EventType, handler Handler) {
 		eb.subscribers[eventType] = []Handler{}
 	}
 	eb.subscribers[eventType] = append(eb.subscribers[eventType], handler)
-	app.Info("Subscribed to event type: %s", "event_type", eventType)
+	app.Info("Subscribed to event type", "event_type", eventType)
 }
 
 // SubscribeMany registers a handler function for multiple event types
@@ -56,7 +56,7 @@ func (eb *EventBus) Publish(event Event) {
 	eb.mu.RUnlock()
 
 	if !exists {
-		app.Debug("No subscribers for event type: %s", "event_type", event.Type)
+		app.Debug("No subscribers for event type", "event_type", event.Type)
 		return
 	}
 
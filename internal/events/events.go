@@ -7,11 +7,12 @@ import (
 // Common event types
 const (
 	// User events
-	UserCreated   EventType = "user.created"
-	UserUpdated   EventType = "user.updated"
-	UserDeleted   EventType = "user.deleted"
-	UserLoggedIn  EventType = "user.logged_in"
-	UserLoggedOut EventType = "user.logged_out"
+	UserCreated         EventType = "user.created"
+	UserUpdated         EventType = "user.updated"
+	UserDeleted         EventType = "user.deleted"
+	UserLoggedIn        EventType = "user.logged_in"
+	UserLoggedOut       EventType = "user.logged_out"
+	UserPasswordChanged EventType = "user.password_changed"
 
 	// Product events
 	ProductCreated EventType = "product.created"
@@ -60,6 +61,16 @@ func InitEventBus() {
 	DefaultBus.Subscribe(SecurityAlert, func(e Event) {
 		app.Warn("Security alert", "details", e.Payload)
 	})
+
+	// Announce configuration reloads; the payload is an app.ConfigReload
+	app.OnConfigReload(func(reload app.ConfigReload) {
+		Publish(ConfigReloaded, reload)
+	})
+
+	// Announce cache failures; the payload is an app.CacheFailure
+	app.OnCacheError(func(failure app.CacheFailure) {
+		Publish(CacheError, failure)
+	})
 }
 
 func init() {
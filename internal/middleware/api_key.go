@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/app/errors"
+	"goapp/internal/context"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the request header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// RejectAPIKeys limits a route to users who logged in interactively, so a
+// narrowly scoped API key cannot manage credentials. It must run after AuthMiddleware.
+func RejectAPIKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user, ok := context.GetAuthUser(c); ok && user.APIKeyID != 0 {
+			DenyAccess(c, "route not available to api keys", "api_key_id", user.APIKeyID)
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticateAPIKey resolves an API key to the principal that owns it
+func authenticateAPIKey(c *gin.Context, key string) (*context.AuthUser, *errors.AppError) {
+	prefix, ok := models.SplitAPIKey(key)
+	if !ok {
+		return nil, errors.NewError(errors.InvalidToken, "Invalid API key")
+	}
+
+	keyRepo := repositories.NewAPIKeyRepository()
+	stored, err := keyRepo.FindByPrefix(prefix)
+	if err != nil || subtle.ConstantTimeCompare([]byte(stored.KeyHash), []byte(utils.SHA256(key))) != 1 {
+		return nil, errors.NewError(errors.InvalidToken, "Invalid API key")
+	}
+
+	now := time.Now()
+	if stored.RevokedAt != nil {
+		return nil, errors.NewError(errors.InvalidToken, "API key has been revoked")
+	}
+	if stored.ExpiresAt != nil && now.After(*stored.ExpiresAt) {
+		return nil, errors.NewError(errors.ExpiredToken, "API key has expired")
+	}
+
+	user, err := repositories.NewUserRepository().Find(stored.UserID)
+	if err != nil || !user.IsActive {
+		return nil, errors.NewError(errors.InvalidToken, "Invalid API key")
+	}
+
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) > activityResolution {
+		if err := keyRepo.TouchLastUsed(stored.ID, now); err != nil {
+			app.ErrorContext(c, "Failed to record API key use", "error", err, "api_key_id", stored.ID)
+		}
+	}
+
+	return &context.AuthUser{
+		ID:       user.ID,
+		Username: user.Username,
+		IsAdmin:  user.IsAdmin,
+		APIKeyID: stored.ID,
+		Scopes:   stored.Scopes,
+	}, nil
+}
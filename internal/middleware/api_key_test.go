@@ -0,0 +1,113 @@
+package middleware_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"goapp/internal/middleware"
+	"goapp/internal/models"
+	"goapp/internal/services"
+	"goapp/internal/testutil"
+
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyScopesAndPermissions(t *testing.T) {
+	db := setupMiddlewareTest(t)
+	writer := testutil.CreateUser(t, db, "writer", "Password1")
+	grantRole(t, writer, "writers", models.PermProductWrite)
+	nobody := testutil.CreateUser(t, db, "nobody", "Password1")
+	admin := testutil.CreateUser(t, db, "admin", "Password1")
+	db.Model(admin).Update("is_admin", true)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{"scope and permission", apiKey(t, writer, models.PermProductWrite), http.StatusOK},
+		{"permission without scope", apiKey(t, writer, models.PermUserRead), http.StatusForbidden},
+		{"scope without permission", apiKey(t, nobody, models.PermProductWrite), http.StatusForbidden},
+		{"admin key with scope", apiKey(t, admin, models.PermProductWrite), http.StatusOK},
+		{"admin key without scope", apiKey(t, admin), http.StatusForbidden},
+		{"unknown key", map[string]string{middleware.APIKeyHeader: models.APIKeyPrefix + "abc_def"}, http.StatusUnauthorized},
+		{"malformed key", map[string]string{middleware.APIKeyHeader: "garbage"}, http.StatusUnauthorized},
+	}
+	router := newRouter(middleware.RequirePermission(models.PermProductWrite))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := get(router, "/users/1", tt.header); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRejectedWhenUnusable(t *testing.T) {
+	tests := []struct {
+		name    string
+		disable func(t *testing.T, db *gorm.DB, user *models.User, key *models.APIKey)
+	}{
+		{"revoked", func(t *testing.T, db *gorm.DB, user *models.User, key *models.APIKey) {
+			if err := services.NewAPIKeyService().RevokeKey(user.ID, key.ID); err != nil {
+				t.Fatalf("RevokeKey: %v", err)
+			}
+		}},
+		{"expired", func(t *testing.T, db *gorm.DB, user *models.User, key *models.APIKey) {
+			db.Model(key).Update("expires_at", time.Now().Add(-time.Minute))
+		}},
+		{"owner deactivated", func(t *testing.T, db *gorm.DB, user *models.User, key *models.APIKey) {
+			db.Model(user).Update("is_active", false)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupMiddlewareTest(t)
+			user := testutil.CreateUser(t, db, "alice", "Password1")
+			key, plaintext, err := services.NewAPIKeyService().CreateKey(user.ID, "test", []string{models.PermUserRead}, nil)
+			if err != nil {
+				t.Fatalf("CreateKey: %v", err)
+			}
+			header := map[string]string{middleware.APIKeyHeader: plaintext}
+			router := newRouter()
+
+			if got := get(router, "/users/1", header); got != http.StatusOK {
+				t.Fatalf("status = %d before the key was disabled", got)
+			}
+			tt.disable(t, db, user, key)
+			if got := get(router, "/users/1", header); got != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", got)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRecordsUse(t *testing.T) {
+	db := setupMiddlewareTest(t)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	key, plaintext, err := services.NewAPIKeyService().CreateKey(user.ID, "test", nil, nil)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	get(newRouter(), "/users/1", map[string]string{middleware.APIKeyHeader: plaintext})
+	var stored models.APIKey
+	db.First(&stored, key.ID)
+	if stored.LastUsedAt == nil {
+		t.Error("last use was not recorded")
+	}
+}
+
+func TestRejectAPIKeys(t *testing.T) {
+	db := setupMiddlewareTest(t)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	router := newRouter(middleware.RejectAPIKeys())
+
+	if got := get(router, "/users/1", bearer(t, user, false)); got != http.StatusOK {
+		t.Errorf("access token: status = %d, want 200", got)
+	}
+	if got := get(router, "/users/1", apiKey(t, user, models.AllPermissions...)); got != http.StatusForbidden {
+		t.Errorf("API key: status = %d, want 403", got)
+	}
+}
@@ -6,15 +6,31 @@ import (
 	"goapp/internal/app"
 	"goapp/internal/app/errors"
 	"goapp/internal/context"
+	"goapp/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware handles authentication for protected routes
+// AuthMiddleware handles authentication for protected routes.
+// Requests authenticate with a Bearer access token or an X-API-Key header.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiCtx := context.GetAPIContext(c)
 
+		if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+			user, appErr := authenticateAPIKey(c, apiKey)
+			if appErr != nil {
+				app.WarnContext(c, "Invalid API key", "error", appErr.Message)
+				apiCtx.ErrorWithAppError(appErr)
+				c.Abort()
+				return
+			}
+
+			context.SetAuthUser(c, user)
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			app.WarnContext(c, "Missing authorization header")
@@ -34,33 +50,54 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		token := parts[1]
 
-		// In a real application, you would validate the JWT token here
-		// For now, we'll just check if it's our dummy token
-		if token != "dummy-jwt-token" {
-			app.ErrorContext(c, "Invalid token", "token", token)
-			apiCtx.ErrorWithCode(errors.Unauthorized, "Invalid token")
+		claims, appErr := app.JWT.ParseAccessToken(token)
+		if appErr != nil {
+			app.WarnContext(c, "Invalid token", "error", appErr.Message)
+			apiCtx.ErrorWithAppError(appErr)
 			c.Abort()
 			return
 		}
 
-		// You could set user information in the context here
-		// c.Set("user_id", claims.UserID)
+		if claims.SessionID != "" {
+			if appErr := checkSession(c, claims.SessionID); appErr != nil {
+				app.WarnContext(c, "Rejected token of inactive session", "session_id", claims.SessionID, "user_id", claims.UserID)
+				apiCtx.ErrorWithAppError(appErr)
+				c.Abort()
+				return
+			}
+		}
+
+		context.SetAuthUser(c, &context.AuthUser{
+			ID:        claims.UserID,
+			Username:  claims.Username,
+			IsAdmin:   claims.IsAdmin,
+			SessionID: claims.SessionID,
+		})
 
 		c.Next()
 	}
 }
 
-// AdminMiddleware ensures the user is an admin
+// AdminMiddleware ensures the authenticated user is an admin. Admin status
+// is read from the database, not the token. It must run after AuthMiddleware.
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiCtx := context.GetAPIContext(c)
 
-		// In a real application, you would get the user from the JWT claims
-		// and check their admin status
-
-		// For demonstration purposes, we'll just check a header
-		isAdmin := c.GetHeader("X-Is-Admin")
-		if isAdmin != "true" {
+		user, ok := context.GetAuthUser(c)
+		if !ok {
+			apiCtx.ErrorWithCode(errors.Unauthorized, "Authentication required")
+			c.Abort()
+			return
+		}
+		granted, err := loadGrants(c, user.ID)
+		if err != nil {
+			app.ErrorContext(c, "Failed to load permissions", "error", err, "user_id", user.ID)
+			apiCtx.ErrorWithCode(errors.InternalServer, "Failed to check permissions")
+			c.Abort()
+			return
+		}
+		if !granted.isAdmin {
 			app.ErrorContext(c, "Unauthorized admin access attempt")
 			apiCtx.ErrorWithCode(errors.Forbidden, "Admin access required")
 			c.Abort()
@@ -71,12 +108,19 @@ func AdminMiddleware() gin.HandlerFunc {
 	}
 }
 
-// CORSMiddleware handles Cross-Origin Resource Sharing (CORS)
+// CORSMiddleware handles Cross-Origin Resource Sharing (CORS). Allowed
+// origins are read per request so configuration reloads apply immediately.
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		origins := app.GetConfig().Server.CORSOrigins
+		if origin := c.Request.Header.Get("Origin"); utils.InArray(origin, origins) {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			c.Writer.Header().Add("Vary", "Origin")
+		} else if utils.InArray("*", origins) {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -46,7 +46,7 @@ func EventsMiddleware() gin.HandlerFunc {
 
 		// Get user ID if available
 		var userID interface{}
-		if id, exists := c.Get("user_id"); exists {
+		if id, exists := c.Get(context.UserIDKey); exists {
 			userID = id
 		}
 
@@ -69,6 +69,11 @@ func EventsMiddleware() gin.HandlerFunc {
 		// Get response status
 		statusCode := c.Writer.Status()
 
+		// Authentication runs inside the route group, so the user is only known now
+		if id, exists := c.Get(context.UserIDKey); exists {
+			userID = id
+		}
+
 		// Create event payload
 		payload := EventPayload{
 			Method:     c.Request.Method,
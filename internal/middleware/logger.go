@@ -72,7 +72,7 @@ func LoggerMiddleware() gin.HandlerFunc {
 		}
 
 		// Also log to console for development environment
-		if app.ConfigData.Server.Mode == "debug" {
+		if app.GetConfig().Server.Mode == "debug" {
 			fmt.Printf("%s | %3d | %13v | %15s | %-7s %s\n",
 				time.Now().Format("2006/01/02 15:04:05"),
 				statusCode,
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"strconv"
+
+	"goapp/internal/app"
+	"goapp/internal/app/errors"
+	"goapp/internal/context"
+	"goapp/internal/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOwnerOrPermission ensures the route's resource belongs to the
+// authenticated user, identified by the given URL parameter, unless the
+// user holds the permission. API keys need the permission as a scope even
+// for their owner's resources. It must run after AuthMiddleware.
+func RequireOwnerOrPermission(param string, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiCtx := context.GetAPIContext(c)
+
+		user, ok := context.GetAuthUser(c)
+		if !ok {
+			apiCtx.ErrorWithCode(errors.Unauthorized, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		ownerID, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if err != nil {
+			apiCtx.ErrorWithCode(errors.BadRequest, "Invalid resource ID")
+			c.Abort()
+			return
+		}
+
+		if !user.AllowsScope(permission) {
+			DenyAccess(c, "api key scope missing", "api_key_id", user.APIKeyID, "permission", permission)
+			return
+		}
+		if ownerID != user.ID && !HasPermission(c, permission) {
+			DenyAccess(c, "not resource owner", "owner_id", ownerID, "permission", permission)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DenyAccess rejects the request with Forbidden and raises a security alert
+func DenyAccess(c *gin.Context, reason string, details ...interface{}) {
+	var userID interface{}
+	if user, ok := context.GetAuthUser(c); ok {
+		userID = user.ID
+	}
+
+	payload := map[string]interface{}{
+		"type":       "access_denied",
+		"reason":     reason,
+		"user_id":    userID,
+		"method":     c.Request.Method,
+		"path":       c.Request.URL.Path,
+		"ip":         c.ClientIP(),
+		"request_id": context.GetRequestID(c),
+	}
+	for i := 0; i+1 < len(details); i += 2 {
+		if key, ok := details[i].(string); ok {
+			payload[key] = details[i+1]
+		}
+	}
+
+	logArgs := append([]interface{}{"reason", reason, "user_id", userID, "path", c.Request.URL.Path}, details...)
+	app.WarnContext(c, "Access denied", logArgs...)
+	events.Publish(events.SecurityAlert, payload)
+
+	context.GetAPIContext(c).ErrorWithCode(errors.Forbidden, "Permission denied")
+	c.Abort()
+}
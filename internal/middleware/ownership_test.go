@@ -0,0 +1,57 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"goapp/internal/middleware"
+	"goapp/internal/models"
+	"goapp/internal/services"
+	"goapp/internal/testutil"
+)
+
+// apiKey returns the header of a new API key of user with the scopes
+func apiKey(t *testing.T, user *models.User, scopes ...string) map[string]string {
+	t.Helper()
+	_, plaintext, err := services.NewAPIKeyService().CreateKey(user.ID, "test", scopes, nil)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	return map[string]string{middleware.APIKeyHeader: plaintext}
+}
+
+func TestRequireOwnerOrPermission(t *testing.T) {
+	db := setupMiddlewareTest(t)
+	owner := testutil.CreateUser(t, db, "owner", "Password1")
+	other := testutil.CreateUser(t, db, "other", "Password1")
+	reader := testutil.CreateUser(t, db, "reader", "Password1")
+	grantRole(t, reader, "readers", models.PermUserRead)
+	ownPath := fmt.Sprintf("/users/%d", owner.ID)
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		want   int
+	}{
+		{"no credentials", ownPath, nil, http.StatusUnauthorized},
+		{"owner", ownPath, bearer(t, owner, false), http.StatusOK},
+		{"other user", ownPath, bearer(t, other, false), http.StatusForbidden},
+		{"other user with permission", ownPath, bearer(t, reader, false), http.StatusOK},
+		{"invalid id", "/users/me", bearer(t, owner, false), http.StatusBadRequest},
+		{"owner's key with scope", ownPath, apiKey(t, owner, models.PermUserRead), http.StatusOK},
+		{"owner's key without scope", ownPath, apiKey(t, owner, models.PermProductWrite), http.StatusForbidden},
+		{"other user's key with scope", ownPath, apiKey(t, other, models.PermUserRead), http.StatusForbidden},
+		{"permitted user's key with scope", ownPath, apiKey(t, reader, models.PermUserRead), http.StatusOK},
+		{"permitted user's key without scope", ownPath, apiKey(t, reader), http.StatusForbidden},
+	}
+	router := newRouter(middleware.RequireOwnerOrPermission("id", models.PermUserRead))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := get(router, tt.path, tt.header); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"goapp/internal/app"
+	"goapp/internal/middleware"
+	"goapp/internal/models"
+	"goapp/internal/services"
+	"goapp/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// setupMiddlewareTest installs the test configuration, an in-memory
+// database and a token manager
+func setupMiddlewareTest(t *testing.T) *gorm.DB {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := testutil.Config()
+	db := testutil.SetupDB(t, cfg)
+	testutil.SetupJWT(t, cfg)
+	return db
+}
+
+// newRouter serves GET /users/:id through AuthMiddleware and the guards
+func newRouter(guards ...gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	handlers := append([]gin.HandlerFunc{middleware.AuthMiddleware()}, guards...)
+	handlers = append(handlers, func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/users/:id", handlers...)
+	return router
+}
+
+// get sends a GET request with the given headers and returns the status
+func get(router *gin.Engine, path string, header map[string]string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// bearer returns the Authorization header of a sessionless access token
+// claiming the given admin status
+func bearer(t *testing.T, user *models.User, isAdmin bool) map[string]string {
+	t.Helper()
+	token, _, err := app.JWT.GenerateAccessToken(user.ID, user.Username, isAdmin, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// grantRole creates a role with the permissions and assigns it to user
+func grantRole(t *testing.T, user *models.User, name string, permissions ...string) {
+	t.Helper()
+	roles := services.NewRoleService()
+	role, err := roles.CreateRole(name, "", permissions)
+	if err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+	if err := roles.AssignRole(user.ID, role.ID); err != nil {
+		t.Fatalf("AssignRole: %v", err)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	db := setupMiddlewareTest(t)
+	reader := testutil.CreateUser(t, db, "reader", "Password1")
+	grantRole(t, reader, "readers", models.PermUserRead)
+	editor := testutil.CreateUser(t, db, "editor", "Password1")
+	grantRole(t, editor, "editors", models.PermUserRead, models.PermUserWrite)
+	nobody := testutil.CreateUser(t, db, "nobody", "Password1")
+	admin := testutil.CreateUser(t, db, "admin", "Password1")
+	db.Model(admin).Update("is_admin", true)
+
+	tests := []struct {
+		name        string
+		header      map[string]string
+		permissions []string
+		want        int
+	}{
+		{"no credentials", nil, []string{models.PermUserRead}, http.StatusUnauthorized},
+		{"granted", bearer(t, reader, false), []string{models.PermUserRead}, http.StatusOK},
+		{"not granted", bearer(t, reader, false), []string{models.PermUserWrite}, http.StatusForbidden},
+		{"all of several", bearer(t, editor, false), []string{models.PermUserRead, models.PermUserWrite}, http.StatusOK},
+		{"one of several missing", bearer(t, reader, false), []string{models.PermUserRead, models.PermUserWrite}, http.StatusForbidden},
+		{"no roles", bearer(t, nobody, false), []string{models.PermUserRead}, http.StatusForbidden},
+		{"admin", bearer(t, admin, true), []string{models.PermRoleManage}, http.StatusOK},
+		{"admin status not in token", bearer(t, admin, false), []string{models.PermRoleManage}, http.StatusOK},
+		{"admin claim of a non-admin", bearer(t, nobody, true), []string{models.PermUserRead}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRouter(middleware.RequirePermission(tt.permissions...))
+			if got := get(router, "/users/1", tt.header); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRevokedAdminLosesAccessImmediately(t *testing.T) {
+	db := setupMiddlewareTest(t)
+	admin := testutil.CreateUser(t, db, "admin", "Password1")
+	db.Model(admin).Update("is_admin", true)
+	header := bearer(t, admin, true)
+
+	router := newRouter(middleware.RequirePermission(models.PermUserDelete))
+	adminOnly := newRouter(middleware.AdminMiddleware())
+	if got := get(router, "/users/1", header); got != http.StatusOK {
+		t.Fatalf("admin: status = %d", got)
+	}
+	if got := get(adminOnly, "/users/1", header); got != http.StatusOK {
+		t.Fatalf("admin route: status = %d", got)
+	}
+
+	db.Model(admin).Update("is_admin", false)
+	if got := get(router, "/users/1", header); got != http.StatusForbidden {
+		t.Errorf("revoked admin: status = %d, want 403", got)
+	}
+	if got := get(adminOnly, "/users/1", header); got != http.StatusForbidden {
+		t.Errorf("revoked admin on admin route: status = %d, want 403", got)
+	}
+}
+
+func TestRevokedRoleLosesAccessImmediately(t *testing.T) {
+	db := setupMiddlewareTest(t)
+	user := testutil.CreateUser(t, db, "reader", "Password1")
+	grantRole(t, user, "readers", models.PermUserRead)
+	header := bearer(t, user, false)
+	router := newRouter(middleware.RequirePermission(models.PermUserRead))
+
+	if got := get(router, "/users/1", header); got != http.StatusOK {
+		t.Fatalf("status = %d before the role was revoked", got)
+	}
+	roles := services.NewRoleService()
+	assigned, _ := roles.GetUserRoles(user.ID)
+	if err := roles.RevokeRole(user.ID, assigned[0].ID); err != nil {
+		t.Fatalf("RevokeRole: %v", err)
+	}
+	if got := get(router, "/users/1", header); got != http.StatusForbidden {
+		t.Errorf("status = %d after the role was revoked, want 403", got)
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"goapp/internal/app"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ReadYourWritesHeader lets a client ask for reads from the primary
+	ReadYourWritesHeader = "X-Read-Your-Writes"
+
+	// readYourWritesCookie holds the time until which a client that wrote
+	// reads from the primary
+	readYourWritesCookie = "goapp_ryw"
+)
+
+// ReadYourWritesMiddleware sends a request's reads to the primary database
+// instead of a replica when the request writes, when the client sets the
+// X-Read-Your-Writes header, or for read_your_writes_window after the
+// client's last write. It does nothing without replicas.
+func ReadYourWritesMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := app.GetConfig().Database
+		if len(cfg.Replicas) == 0 {
+			c.Next()
+			return
+		}
+
+		write := !isReadOnlyMethod(c.Request.Method)
+		if write || readYourWritesRequested(c) {
+			c.Request = c.Request.WithContext(app.WithPrimaryReads(c.Request.Context()))
+		}
+
+		// The cookie is set up front because the response is written by the handler
+		if window := cfg.ReadYourWritesWindow.Duration(); write && window > 0 {
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     readYourWritesCookie,
+				Value:    strconv.FormatInt(time.Now().Add(window).Unix(), 10),
+				Path:     "/",
+				MaxAge:   int(window.Seconds()) + 1,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		c.Next()
+	}
+}
+
+// readYourWritesRequested checks the override header and the window cookie
+func readYourWritesRequested(c *gin.Context) bool {
+	if value := c.GetHeader(ReadYourWritesHeader); value != "" {
+		requested, _ := strconv.ParseBool(value)
+		return requested
+	}
+	cookie, err := c.Cookie(readYourWritesCookie)
+	if err != nil {
+		return false
+	}
+	until, err := strconv.ParseInt(cookie, 10, 64)
+	return err == nil && time.Now().Unix() <= until
+}
+
+// isReadOnlyMethod reports whether an HTTP method does not change state
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
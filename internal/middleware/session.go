@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/app/errors"
+	"goapp/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activityResolution limits how often last-seen and last-used timestamps are written
+const activityResolution = time.Minute
+
+// checkSession rejects access tokens whose session was revoked and records activity
+func checkSession(c *gin.Context, sessionID string) *errors.AppError {
+	sessionRepo := repositories.NewSessionRepository()
+	session, err := sessionRepo.Find(sessionID)
+	if err != nil || session.RevokedAt != nil {
+		return errors.NewError(errors.InvalidToken, "Session has been revoked")
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) > activityResolution {
+		if err := sessionRepo.Touch(session.ID, c.ClientIP(), now); err != nil {
+			app.ErrorContext(c, "Failed to update session", "error", err, "session_id", session.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(20261017090000, "create_users", func(tx *gorm.DB) error {
+		type User struct {
+			ID            int64  `gorm:"primaryKey"`
+			Username      string `gorm:"uniqueIndex;size:100;not null"`
+			Email         string `gorm:"uniqueIndex;size:255;not null"`
+			Password      string `gorm:"size:255;not null"`
+			FirstName     string `gorm:"size:100"`
+			LastName      string `gorm:"size:100"`
+			IsActive      bool   `gorm:"default:true"`
+			IsAdmin       bool   `gorm:"default:false"`
+			EmailVerified bool   `gorm:"default:false"`
+			VerifiedAt    *time.Time
+			MFAEnabled    bool   `gorm:"default:false"`
+			MFASecret     string `gorm:"size:64"`
+			MFALastStep   int64
+			CreatedAt     time.Time
+			UpdatedAt     time.Time
+			DeletedAt     gorm.DeletedAt `gorm:"index"`
+		}
+		return tx.Migrator().CreateTable(&User{})
+	}, func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("users")
+	})
+}
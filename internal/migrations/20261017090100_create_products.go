@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(20261017090100, "create_products", func(tx *gorm.DB) error {
+		type Product struct {
+			ID          int64   `gorm:"primaryKey"`
+			Name        string  `gorm:"size:255;not null"`
+			Description string  `gorm:"type:text"`
+			Price       float64 `gorm:"type:decimal(10,2);not null"`
+			SKU         string  `gorm:"uniqueIndex;size:100;not null"`
+			Stock       int     `gorm:"not null;default:0"`
+			CategoryID  int64   `gorm:"index"`
+			IsActive    bool    `gorm:"default:true"`
+			CreatedAt   time.Time
+			UpdatedAt   time.Time
+			DeletedAt   gorm.DeletedAt `gorm:"index"`
+		}
+		return tx.Migrator().CreateTable(&Product{})
+	}, func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("products")
+	})
+}
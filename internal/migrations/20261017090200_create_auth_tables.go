@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(20261017090200, "create_auth_tables", func(tx *gorm.DB) error {
+		type RefreshToken struct {
+			ID        int64     `gorm:"primaryKey"`
+			UserID    int64     `gorm:"index;not null"`
+			FamilyID  string    `gorm:"index;size:36;not null"`
+			TokenHash string    `gorm:"uniqueIndex;size:64;not null"`
+			ExpiresAt time.Time `gorm:"not null"`
+			UsedAt    *time.Time
+			RevokedAt *time.Time
+			CreatedAt time.Time
+		}
+		type Session struct {
+			ID         string    `gorm:"primaryKey;size:36"`
+			UserID     int64     `gorm:"index;not null"`
+			UserAgent  string    `gorm:"size:512"`
+			IP         string    `gorm:"size:45"`
+			ExpiresAt  time.Time `gorm:"not null"`
+			LastSeenAt time.Time
+			RevokedAt  *time.Time
+			CreatedAt  time.Time
+		}
+		type PasswordHistory struct {
+			ID           int64  `gorm:"primaryKey"`
+			UserID       int64  `gorm:"index:idx_password_history_user_id;not null"`
+			PasswordHash string `gorm:"size:255;not null"`
+			CreatedAt    time.Time
+		}
+		type PasswordResetToken struct {
+			ID        int64     `gorm:"primaryKey"`
+			UserID    int64     `gorm:"index;not null"`
+			TokenHash string    `gorm:"uniqueIndex;size:64;not null"`
+			ExpiresAt time.Time `gorm:"not null"`
+			UsedAt    *time.Time
+			CreatedAt time.Time
+		}
+		type EmailVerificationToken struct {
+			ID        int64     `gorm:"primaryKey"`
+			UserID    int64     `gorm:"index;not null"`
+			TokenHash string    `gorm:"uniqueIndex;size:64;not null"`
+			ExpiresAt time.Time `gorm:"not null"`
+			UsedAt    *time.Time
+			CreatedAt time.Time
+		}
+		type MFARecoveryCode struct {
+			ID        int64  `gorm:"primaryKey"`
+			UserID    int64  `gorm:"index;not null"`
+			CodeHash  string `gorm:"size:64;not null"`
+			UsedAt    *time.Time
+			CreatedAt time.Time
+		}
+
+		if err := tx.Migrator().CreateTable(&RefreshToken{}, &Session{}, &PasswordResetToken{}, &EmailVerificationToken{}, &MFARecoveryCode{}); err != nil {
+			return err
+		}
+		return tx.Table("password_history").Migrator().CreateTable(&PasswordHistory{})
+	}, func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("mfa_recovery_codes", "email_verification_tokens", "password_reset_tokens", "password_history", "sessions", "refresh_tokens")
+	})
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(20261017090300, "create_rbac_tables", func(tx *gorm.DB) error {
+		type Role struct {
+			ID          int64  `gorm:"primaryKey"`
+			Name        string `gorm:"uniqueIndex;size:100;not null"`
+			Description string `gorm:"size:255"`
+			CreatedAt   time.Time
+			UpdatedAt   time.Time
+		}
+		type Permission struct {
+			ID          int64  `gorm:"primaryKey"`
+			Name        string `gorm:"uniqueIndex;size:100;not null"`
+			Description string `gorm:"size:255"`
+			CreatedAt   time.Time
+		}
+		type RolePermission struct {
+			RoleID       int64 `gorm:"primaryKey;autoIncrement:false"`
+			PermissionID int64 `gorm:"primaryKey;autoIncrement:false"`
+		}
+		type UserRole struct {
+			UserID    int64 `gorm:"primaryKey;autoIncrement:false"`
+			RoleID    int64 `gorm:"primaryKey;autoIncrement:false;index"`
+			CreatedAt time.Time
+		}
+		return tx.Migrator().CreateTable(&Role{}, &Permission{}, &RolePermission{}, &UserRole{})
+	}, func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("user_roles", "role_permissions", "permissions", "roles")
+	})
+}
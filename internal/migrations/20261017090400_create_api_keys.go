@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(20261017090400, "create_api_keys", func(tx *gorm.DB) error {
+		type APIKey struct {
+			ID         int64  `gorm:"primaryKey"`
+			UserID     int64  `gorm:"index;not null"`
+			Name       string `gorm:"size:100;not null"`
+			Prefix     string `gorm:"uniqueIndex;size:16;not null"`
+			KeyHash    string `gorm:"size:64;not null"`
+			Scopes     string `gorm:"type:text"`
+			ExpiresAt  *time.Time
+			LastUsedAt *time.Time
+			RevokedAt  *time.Time
+			CreatedAt  time.Time
+		}
+		return tx.Migrator().CreateTable(&APIKey{})
+	}, func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("api_keys")
+	})
+}
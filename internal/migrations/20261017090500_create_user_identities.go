@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(20261017090500, "create_user_identities", func(tx *gorm.DB) error {
+		type UserIdentity struct {
+			ID          int64  `gorm:"primaryKey"`
+			UserID      int64  `gorm:"index;not null"`
+			Provider    string `gorm:"uniqueIndex:idx_identity_provider_subject;size:50;not null"`
+			Subject     string `gorm:"uniqueIndex:idx_identity_provider_subject;size:255;not null"`
+			Email       string `gorm:"size:255"`
+			LastLoginAt time.Time
+			CreatedAt   time.Time
+		}
+		return tx.Migrator().CreateTable(&UserIdentity{})
+	}, func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable("user_identities")
+	})
+}
@@ -0,0 +1,301 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"goapp/internal/app"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// lockWaitTimeout is how long a runner waits for another one to finish
+	lockWaitTimeout = time.Minute
+	// lockPollInterval is how often a waiting runner retries the lock
+	lockPollInterval = 500 * time.Millisecond
+	// staleLockAge is when a lock left behind by a crashed runner is taken over
+	staleLockAge = 15 * time.Minute
+)
+
+var (
+	ErrMigrationLocked  = errors.New("another migration runner holds the lock")
+	ErrUnknownMigration = errors.New("applied migration is not known to this build")
+)
+
+// Migration is one versioned schema change. Versions are UTC timestamps
+// (20060102150405) so migrations from different branches sort sensibly.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// registry holds every migration registered by the files of this package
+var registry = make(map[int64]*Migration)
+
+// register adds a migration; it is called from the init function of each
+// migration file
+func register(version int64, name string, up, down func(tx *gorm.DB) error) {
+	if existing, exists := registry[version]; exists {
+		panic(fmt.Sprintf("migration %d registered twice (%s and %s)", version, existing.Name, name))
+	}
+	registry[version] = &Migration{Version: version, Name: name, Up: up, Down: down}
+}
+
+// All returns the registered migrations in version order
+func All() []*Migration {
+	migrations := make([]*Migration, 0, len(registry))
+	for _, migration := range registry {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for SchemaMigration
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrationLock is the single row that marks a runner as active
+type migrationLock struct {
+	ID       int       `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string    `gorm:"size:255;not null"`
+	LockedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for migrationLock
+func (migrationLock) TableName() string {
+	return "schema_migrations_lock"
+}
+
+// MigrationStatus describes one migration for the status command
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Missing   bool // applied but not part of this build
+}
+
+// Migrator applies and rolls back migrations against a database
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+	owner      string
+}
+
+// NewMigrator creates a Migrator for the registered migrations
+func NewMigrator(db *gorm.DB) *Migrator {
+	hostname, _ := os.Hostname()
+	return &Migrator{
+		db:         db,
+		migrations: All(),
+		owner:      fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}
+}
+
+// Up applies every pending migration in version order and returns the
+// ones it applied. Each migration runs in its own transaction; note that
+// MySQL commits DDL statements implicitly.
+func (m *Migrator) Up() ([]*Migration, error) {
+	var applied []*Migration
+	err := m.withLock(func() error {
+		done, err := m.appliedVersions()
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, exists := done[migration.Version]; exists {
+				continue
+			}
+			if err := m.apply(migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the newest steps applied migrations and returns them
+func (m *Migrator) Down(steps int) ([]*Migration, error) {
+	var rolledBack []*Migration
+	err := m.withLock(func() error {
+		var records []SchemaMigration
+		if err := m.db.Order("version DESC").Limit(steps).Find(&records).Error; err != nil {
+			return fmt.Errorf("error loading applied migrations: %w", err)
+		}
+		for _, record := range records {
+			migration, exists := registry[record.Version]
+			if !exists {
+				return fmt.Errorf("%w: %d_%s", ErrUnknownMigration, record.Version, record.Name)
+			}
+			if err := m.rollback(migration); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known or applied migration in version order
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.ensureTables(); err != nil {
+		return nil, err
+	}
+	done, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, exists := done[migration.Version]; exists {
+			status.Applied = true
+			status.AppliedAt = &record.AppliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range done {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   record.Version,
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// apply runs a migration and records it
+func (m *Migrator) apply(migration *Migration) error {
+	start := time.Now()
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error applying migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	app.Info("Migration applied", "version", migration.Version, "name", migration.Name, "duration", time.Since(start))
+	return nil
+}
+
+// rollback reverts a migration and removes its record
+func (m *Migrator) rollback(migration *Migration) error {
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if err := migration.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error rolling back migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	app.Info("Migration rolled back", "version", migration.Version, "name", migration.Name)
+	return nil
+}
+
+// appliedVersions returns the applied migrations by version
+func (m *Migrator) appliedVersions() (map[int64]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("error loading applied migrations: %w", err)
+	}
+	done := make(map[int64]SchemaMigration, len(records))
+	for _, record := range records {
+		done[record.Version] = record
+	}
+	return done, nil
+}
+
+// ensureTables creates the bookkeeping tables
+func (m *Migrator) ensureTables() error {
+	err := m.db.AutoMigrate(&SchemaMigration{}, &migrationLock{})
+	if err != nil && !(m.db.Migrator().HasTable(&SchemaMigration{}) && m.db.Migrator().HasTable(&migrationLock{})) {
+		return fmt.Errorf("error creating migration tables: %w", err)
+	}
+	// A concurrent runner may have created the tables first
+	return nil
+}
+
+// withLock runs fn while holding the migration lock, so concurrent runners
+// (for example several instances auto-migrating on start) apply each
+// migration once
+func (m *Migrator) withLock(fn func() error) error {
+	if err := m.ensureTables(); err != nil {
+		return err
+	}
+	if err := m.acquireLock(); err != nil {
+		return err
+	}
+	defer m.releaseLock()
+
+	return fn()
+}
+
+// acquireLock inserts the lock row, waiting while another runner holds it
+// and taking over a lock that has gone stale
+func (m *Migrator) acquireLock() error {
+	deadline := time.Now().Add(lockWaitTimeout)
+	for {
+		err := m.db.Create(&migrationLock{ID: 1, Owner: m.owner, LockedAt: time.Now()}).Error
+		if err == nil {
+			return nil
+		}
+
+		var holder migrationLock
+		if result := m.db.Limit(1).Find(&holder, 1); result.Error != nil || result.RowsAffected == 0 {
+			// The insert failed for another reason than a held lock
+			return fmt.Errorf("error acquiring migration lock: %w", err)
+		}
+
+		if time.Since(holder.LockedAt) > staleLockAge {
+			app.Warn("Taking over stale migration lock", "owner", holder.Owner, "locked_at", holder.LockedAt)
+			m.db.Where("id = ? AND owner = ?", 1, holder.Owner).Delete(&migrationLock{})
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w (%s since %s)", ErrMigrationLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// releaseLock removes the lock row if this runner still owns it
+func (m *Migrator) releaseLock() {
+	if err := m.db.Where("id = ? AND owner = ?", 1, m.owner).Delete(&migrationLock{}).Error; err != nil {
+		app.Error("Failed to release migration lock", "error", err)
+	}
+}
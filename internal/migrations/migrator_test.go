@@ -0,0 +1,102 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"goapp/internal/migrations"
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+
+	"gorm.io/gorm"
+)
+
+// schemaModels are the models whose tables the migrations create
+var schemaModels = []interface{}{
+	&models.User{},
+	&models.Product{},
+	&models.RefreshToken{},
+	&models.Session{},
+	&models.PasswordHistory{},
+	&models.PasswordResetToken{},
+	&models.EmailVerificationToken{},
+	&models.MFARecoveryCode{},
+	&models.Role{},
+	&models.Permission{},
+	&models.UserRole{},
+	&models.APIKey{},
+	&models.UserIdentity{},
+}
+
+func TestMigrationsMatchModels(t *testing.T) {
+	db := testutil.SetupDB(t, testutil.Config())
+
+	for _, model := range schemaModels {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		table := statement.Schema.Table
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("table %s of %T is not created by any migration", table, model)
+			continue
+		}
+		for _, field := range statement.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("column %s.%s of %T.%s is not created by any migration", table, field.DBName, model, field.Name)
+			}
+		}
+	}
+}
+
+func TestMigratorUpIsIdempotent(t *testing.T) {
+	db := testutil.SetupDB(t, testutil.Config())
+
+	applied, err := migrations.NewMigrator(db).Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Up applied %d migrations, want 0", len(applied))
+	}
+
+	statuses, err := migrations.NewMigrator(db).Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if len(statuses) != len(migrations.All()) {
+		t.Fatalf("Status returned %d migrations, want %d", len(statuses), len(migrations.All()))
+	}
+	for _, status := range statuses {
+		if !status.Applied || status.Missing {
+			t.Errorf("migration %d %s: applied=%v missing=%v", status.Version, status.Name, status.Applied, status.Missing)
+		}
+	}
+}
+
+func TestMigratorDownAndUpAgain(t *testing.T) {
+	db := testutil.SetupDB(t, testutil.Config())
+	migrator := migrations.NewMigrator(db)
+	all := migrations.All()
+
+	rolledBack, err := migrator.Down(len(all))
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(rolledBack) != len(all) {
+		t.Fatalf("Down rolled back %d migrations, want %d", len(rolledBack), len(all))
+	}
+	if db.Migrator().HasTable(&models.User{}) {
+		t.Error("users table still exists after rolling everything back")
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != len(all) {
+		t.Errorf("Up applied %d migrations, want %d", len(applied), len(all))
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognize
+const APIKeyPrefix = "gak_"
+
+// APIKey is a long-lived credential a user issues to a machine client
+type APIKey struct {
+	ID         int64      `json:"id" gorm:"primaryKey"`
+	UserID     int64      `json:"user_id" gorm:"index;not null"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex;size:16;not null"` // public part used to look the key up
+	KeyHash    string     `json:"-" gorm:"size:64;not null"`                  // SHA-256 of the key, never the key itself
+	Scopes     []string   `json:"scopes" gorm:"serializer:json;type:text"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the database table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// SplitAPIKey returns the lookup prefix of a key formatted as gak_<prefix>_<secret>
+func SplitAPIKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return parts[0], true
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordHistory records a password hash a user has used before
+type PasswordHistory struct {
+	ID           int64     `json:"id" gorm:"primaryKey"`
+	UserID       int64     `json:"user_id" gorm:"index;not null"`
+	PasswordHash string    `json:"-" gorm:"size:255;not null"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the database table name for the PasswordHistory model
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is a server-side record of an issued refresh token.
+// Tokens issued by rotating one another share a FamilyID.
+type RefreshToken struct {
+	ID        int64      `json:"id" gorm:"primaryKey"`
+	UserID    int64      `json:"user_id" gorm:"index;not null"`
+	FamilyID  string     `json:"family_id" gorm:"index;size:36;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;size:64;not null"` // SHA-256 of the token, never the token itself
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the database table name for the RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
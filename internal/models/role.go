@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+)
+
+// Permission names checked by the access control middleware
+const (
+	PermProductWrite = "product:write"
+	PermUserRead     = "user:read"
+	PermUserWrite    = "user:write"
+	PermUserDelete   = "user:delete"
+	PermMetricsRead  = "metrics:read"
+	PermRoleManage   = "role:manage"
+)
+
+// AllPermissions lists every permission known to the application
+var AllPermissions = []string{
+	PermProductWrite,
+	PermUserRead,
+	PermUserWrite,
+	PermUserDelete,
+	PermMetricsRead,
+	PermRoleManage,
+}
+
+// Permission represents a single grantable capability
+type Permission struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"uniqueIndex;size:100;not null"`
+	Description string    `json:"description" gorm:"size:255"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the database table name for the Permission model
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// Role groups permissions that can be assigned to users
+type Role struct {
+	ID          int64         `json:"id" gorm:"primaryKey"`
+	Name        string        `json:"name" gorm:"uniqueIndex;size:100;not null"`
+	Description string        `json:"description" gorm:"size:255"`
+	Permissions []*Permission `json:"permissions" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the database table name for the Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionNames returns the names of the role's permissions
+func (r *Role) PermissionNames() []string {
+	names := make([]string, len(r.Permissions))
+	for i, permission := range r.Permissions {
+		names[i] = permission.Name
+	}
+	return names
+}
+
+// UserRole assigns a role to a user
+type UserRole struct {
+	UserID    int64     `json:"user_id" gorm:"primaryKey"`
+	RoleID    int64     `json:"role_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the database table name for the UserRole model
+func (UserRole) TableName() string {
+	return "user_roles"
+}
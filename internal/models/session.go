@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is one login of a user on a device. Its ID is also the family ID
+// of the refresh tokens issued for the login.
+type Session struct {
+	ID         string     `json:"id" gorm:"primaryKey;size:36"`
+	UserID     int64      `json:"user_id" gorm:"index;not null"`
+	UserAgent  string     `json:"user_agent" gorm:"size:512"`
+	IP         string     `json:"ip" gorm:"size:45"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"` // follows the newest refresh token
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the database table name for the Session model
+func (Session) TableName() string {
+	return "sessions"
+}
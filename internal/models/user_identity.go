@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to an account at an external identity provider
+type UserIdentity struct {
+	ID          int64     `json:"id" gorm:"primaryKey"`
+	UserID      int64     `json:"user_id" gorm:"index;not null"`
+	Provider    string    `json:"provider" gorm:"uniqueIndex:idx_identity_provider_subject;size:50;not null"`
+	Subject     string    `json:"subject" gorm:"uniqueIndex:idx_identity_provider_subject;size:255;not null"`
+	Email       string    `json:"email" gorm:"size:255"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the database table name for the UserIdentity model
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
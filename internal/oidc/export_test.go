@@ -0,0 +1,11 @@
+package oidc
+
+import "time"
+
+// SetJWKSRefreshInterval changes how often unknown key IDs may refetch the
+// JWKS and returns a function that restores it
+func SetJWKSRefreshInterval(interval time.Duration) func() {
+	previous := jwksRefreshInterval
+	jwksRefreshInterval = interval
+	return func() { jwksRefreshInterval = previous }
+}
@@ -0,0 +1,298 @@
+// Package oidctest provides an in-process OpenID Connect provider for
+// tests, to be served with net/http/httptest. It is only imported from
+// _test.go files, so none of it ends up in the binary.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"goapp/internal/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// User is the identity the provider signs in
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Provider is a minimal OpenID Connect provider. Every authorization
+// request is approved immediately as User. Set the exported fields before
+// starting a login; they are read when the login is authorized.
+type Provider struct {
+	ClientID     string
+	ClientSecret string
+	User         User
+	// Nonce, when set, replaces the nonce requested by the client in ID
+	// tokens, to exercise nonce checks
+	Nonce string
+
+	mux *http.ServeMux
+
+	mutex        sync.Mutex
+	key          *rsa.PrivateKey
+	keyID        string
+	keyCount     int
+	codes        map[string]*authorization
+	jwksRequests int
+}
+
+// authorization is an issued authorization code awaiting exchange
+type authorization struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	user          User
+	expiresAt     time.Time
+}
+
+// jsonWebKey is a single RSA key of the JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// NewProvider creates a Provider with a fresh signing key
+func NewProvider(clientID, clientSecret string) (*Provider, error) {
+	f := &Provider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		User: User{
+			Subject:           "test-user-1",
+			Email:             "sso.user@example.com",
+			EmailVerified:     true,
+			Name:              "SSO User",
+			PreferredUsername: "sso.user",
+		},
+		mux:   http.NewServeMux(),
+		codes: make(map[string]*authorization),
+	}
+	if err := f.RotateKey(); err != nil {
+		return nil, err
+	}
+
+	f.mux.HandleFunc("/.well-known/openid-configuration", f.handleDiscovery)
+	f.mux.HandleFunc("/authorize", f.handleAuthorize)
+	f.mux.HandleFunc("/token", f.handleToken)
+	f.mux.HandleFunc("/jwks", f.handleJWKS)
+	return f, nil
+}
+
+// RotateKey replaces the signing key with a new one under a new key ID.
+// Only the new key is published, as after a provider retires a key.
+func (f *Provider) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return fmt.Errorf("error generating signing key: %w", err)
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.keyCount++
+	f.key = key
+	f.keyID = fmt.Sprintf("test-key-%d", f.keyCount)
+	return nil
+}
+
+// JWKSRequests returns how often the JWKS document was fetched
+func (f *Provider) JWKSRequests() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.jwksRequests
+}
+
+// ServeHTTP implements http.Handler
+func (f *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mux.ServeHTTP(w, r)
+}
+
+// issuer derives the issuer from the request so the provider works on any address
+func (f *Provider) issuer(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// handleDiscovery serves the discovery document
+func (f *Provider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	issuer := f.issuer(r)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/authorize",
+		"token_endpoint":                        issuer + "/token",
+		"jwks_uri":                              issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// handleAuthorize approves the request and redirects back with a code
+func (f *Provider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != f.ClientID {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "authorization code with S256 PKCE required", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code, err := oidc.RandomString()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	nonce := query.Get("nonce")
+	if f.Nonce != "" {
+		nonce = f.Nonce
+	}
+
+	f.mutex.Lock()
+	f.codes[code] = &authorization{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         nonce,
+		user:          f.User,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	f.mutex.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// handleToken exchanges a code for an ID token after checking the client and PKCE verifier
+func (f *Provider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != f.ClientID || clientSecret != f.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	// Codes are single use, even when the exchange fails
+	code := r.PostForm.Get("code")
+	f.mutex.Lock()
+	authorization, exists := f.codes[code]
+	delete(f.codes, code)
+	f.mutex.Unlock()
+
+	if !exists || time.Now().After(authorization.expiresAt) ||
+		authorization.redirectURI != r.PostForm.Get("redirect_uri") ||
+		oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != authorization.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	idToken, err := f.SignIDToken(f.issuer(r), authorization.user, authorization.nonce, time.Hour)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken, _ := oidc.RandomString()
+	writeJSON(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   3600,
+	})
+}
+
+// handleJWKS publishes the public signing key
+func (f *Provider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	f.jwksRequests++
+	publicKey, keyID := f.key.PublicKey, f.keyID
+	f.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []jsonWebKey{{
+			Kty: "RSA",
+			Kid: keyID,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+// SignIDToken issues an ID token for a user, as the token endpoint does
+func (f *Provider) SignIDToken(issuer string, user User, nonce string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := oidc.IDTokenClaims{
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerified,
+		Name:              user.Name,
+		PreferredUsername: user.PreferredUsername,
+		Nonce:             nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   user.Subject,
+			Audience:  jwt.ClaimStrings{f.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	f.mutex.Lock()
+	key, keyID := f.key, f.keyID
+	f.mutex.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(key)
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
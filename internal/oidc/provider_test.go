@@ -0,0 +1,162 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/oidc"
+	"goapp/internal/oidc/oidctest"
+)
+
+const (
+	testClientID     = "goapp"
+	testClientSecret = "goapp-secret"
+	testRedirectURL  = "http://app.test/callback"
+)
+
+// startProvider serves a test provider and returns it with a client Provider
+func startProvider(t *testing.T) (*oidctest.Provider, *oidc.Provider, string) {
+	t.Helper()
+	fake, err := oidctest.NewProvider(testClientID, testClientSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	provider := oidc.NewProvider(app.OIDCProviderConfig{
+		Name:         "test",
+		Issuer:       server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	})
+	return fake, provider, server.URL
+}
+
+// authorize runs the authorization step and returns the issued code
+func authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location.Query().Get("code")
+}
+
+func TestProviderCodeFlow(t *testing.T) {
+	_, provider, _ := startProvider(t)
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if !strings.Contains(authURL, "code_challenge="+oidc.CodeChallenge("verifier-1")) {
+		t.Errorf("AuthCodeURL has no S256 challenge: %s", authURL)
+	}
+
+	tokens, err := provider.Exchange(ctx, authorize(t, authURL), "verifier-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Subject != "test-user-1" || claims.Email != "sso.user@example.com" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestProviderRejectsWrongVerifier(t *testing.T) {
+	_, provider, _ := startProvider(t)
+	ctx := context.Background()
+
+	authURL, _ := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if _, err := provider.Exchange(ctx, authorize(t, authURL), "verifier-2"); err == nil {
+		t.Fatal("Exchange accepted a code with the wrong PKCE verifier")
+	}
+}
+
+func TestProviderRejectsWrongNonce(t *testing.T) {
+	_, provider, _ := startProvider(t)
+	ctx := context.Background()
+
+	authURL, _ := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	tokens, err := provider.Exchange(ctx, authorize(t, authURL), "verifier-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := provider.VerifyIDToken(ctx, tokens.IDToken, "nonce-2"); !errors.Is(err, oidc.ErrNonceMismatch) {
+		t.Fatalf("VerifyIDToken = %v, want ErrNonceMismatch", err)
+	}
+}
+
+func TestProviderRejectsUnknownSigningKeys(t *testing.T) {
+	fake, provider, issuer := startProvider(t)
+	other, err := oidctest.NewProvider(testClientID, testClientSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Load the real keys first, then present a token signed by another key
+	// under the same issuer
+	valid, _ := fake.SignIDToken(issuer, fake.User, "n", time.Hour)
+	if _, err := provider.VerifyIDToken(ctx, valid, "n"); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	forged, _ := other.SignIDToken(issuer, other.User, "n", time.Hour)
+	if _, err := provider.VerifyIDToken(ctx, forged, "n"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Fatalf("VerifyIDToken of a forged token = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestProviderPicksUpRotatedKeys(t *testing.T) {
+	fake, provider, issuer := startProvider(t)
+	ctx := context.Background()
+
+	first, _ := fake.SignIDToken(issuer, fake.User, "n", time.Hour)
+	if _, err := provider.VerifyIDToken(ctx, first, "n"); err != nil {
+		t.Fatalf("VerifyIDToken before rotation: %v", err)
+	}
+	if err := fake.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	rotated, _ := fake.SignIDToken(issuer, fake.User, "n", time.Hour)
+
+	// Within the refresh interval an unknown key does not refetch the JWKS
+	if _, err := provider.VerifyIDToken(ctx, rotated, "n"); err == nil {
+		t.Fatal("VerifyIDToken refetched the JWKS within the refresh interval")
+	}
+	if got := fake.JWKSRequests(); got != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", got)
+	}
+
+	defer oidc.SetJWKSRefreshInterval(0)()
+	if _, err := provider.VerifyIDToken(ctx, rotated, "n"); err != nil {
+		t.Fatalf("VerifyIDToken after rotation: %v", err)
+	}
+	if got := fake.JWKSRequests(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", got)
+	}
+}
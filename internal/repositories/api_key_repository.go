@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines the interface for API key data operations
+type APIKeyRepository interface {
+	Find(id int64) (*models.APIKey, error)
+	FindByPrefix(prefix string) (*models.APIKey, error)
+	FindByUser(userID int64) ([]*models.APIKey, error)
+	Create(key *models.APIKey) error
+	Revoke(id int64) error
+	TouchLastUsed(id int64, usedAt time.Time) error
+}
+
+// GormAPIKeyRepository implements APIKeyRepository interface using GORM
+type GormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository
+func NewAPIKeyRepository() APIKeyRepository {
+	return &GormAPIKeyRepository{
+		db: app.GetDB(),
+	}
+}
+
+// Find retrieves an API key by ID
+func (r *GormAPIKeyRepository) Find(id int64) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.First(&key, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, fmt.Errorf("error finding api key: %w", result.Error)
+	}
+	return &key, nil
+}
+
+// FindByPrefix retrieves an API key by its public prefix
+func (r *GormAPIKeyRepository) FindByPrefix(prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.Where("prefix = ?", prefix).First(&key)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, fmt.Errorf("error finding api key: %w", result.Error)
+	}
+	return &key, nil
+}
+
+// FindByUser retrieves every API key of a user, newest first
+func (r *GormAPIKeyRepository) FindByUser(userID int64) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	result := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&keys)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error finding api keys: %w", result.Error)
+	}
+	return keys, nil
+}
+
+// Create inserts a new API key
+func (r *GormAPIKeyRepository) Create(key *models.APIKey) error {
+	result := r.db.Create(key)
+	if result.Error != nil {
+		return fmt.Errorf("error creating api key: %w", result.Error)
+	}
+	return nil
+}
+
+// Revoke marks an API key revoked
+func (r *GormAPIKeyRepository) Revoke(id int64) error {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("error revoking api key: %w", result.Error)
+	}
+	return nil
+}
+
+// TouchLastUsed records when an API key was last used
+func (r *GormAPIKeyRepository) TouchLastUsed(id int64, usedAt time.Time) error {
+	result := r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt)
+	if result.Error != nil {
+		return fmt.Errorf("error updating api key last use: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EmailVerificationRepository defines the interface for email verification token data operations
+type EmailVerificationRepository interface {
+	FindByHash(tokenHash string) (*models.EmailVerificationToken, error)
+	Create(token *models.EmailVerificationToken) error
+	MarkUsed(id int64) (bool, error)
+	InvalidateByUser(userID int64) error
+}
+
+// GormEmailVerificationRepository implements EmailVerificationRepository interface using GORM
+type GormEmailVerificationRepository struct {
+	db *gorm.DB
+}
+
+// NewEmailVerificationRepository creates a new EmailVerificationRepository
+func NewEmailVerificationRepository() EmailVerificationRepository {
+	return &GormEmailVerificationRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindByHash retrieves a verification token by the hash of its value
+func (r *GormEmailVerificationRepository) FindByHash(tokenHash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	result := r.db.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("email verification token not found")
+		}
+		return nil, fmt.Errorf("error finding email verification token: %w", result.Error)
+	}
+	return &token, nil
+}
+
+// Create inserts a new verification token
+func (r *GormEmailVerificationRepository) Create(token *models.EmailVerificationToken) error {
+	result := r.db.Create(token)
+	if result.Error != nil {
+		return fmt.Errorf("error creating email verification token: %w", result.Error)
+	}
+	return nil
+}
+
+// MarkUsed consumes a token. It reports false when the token was already used.
+func (r *GormEmailVerificationRepository) MarkUsed(id int64) (bool, error) {
+	result := r.db.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("error marking email verification token used: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateByUser consumes every outstanding verification token of a user
+func (r *GormEmailVerificationRepository) InvalidateByUser(userID int64) error {
+	result := r.db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("error invalidating email verification tokens: %w", result.Error)
+	}
+	return nil
+}
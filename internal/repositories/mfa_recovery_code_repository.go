@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MFARecoveryCodeRepository defines the interface for MFA recovery code data operations
+type MFARecoveryCodeRepository interface {
+	FindUnused(userID int64, codeHash string) (*models.MFARecoveryCode, error)
+	Replace(userID int64, codes []*models.MFARecoveryCode) error
+	MarkUsed(id int64) (bool, error)
+	DeleteByUser(userID int64) error
+}
+
+// GormMFARecoveryCodeRepository implements MFARecoveryCodeRepository interface using GORM
+type GormMFARecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+// NewMFARecoveryCodeRepository creates a new MFARecoveryCodeRepository
+func NewMFARecoveryCodeRepository() MFARecoveryCodeRepository {
+	return &GormMFARecoveryCodeRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindUnused retrieves a user's unused recovery code by the hash of its value
+func (r *GormMFARecoveryCodeRepository) FindUnused(userID int64, codeHash string) (*models.MFARecoveryCode, error) {
+	var code models.MFARecoveryCode
+	result := r.db.Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).First(&code)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("recovery code not found")
+		}
+		return nil, fmt.Errorf("error finding recovery code: %w", result.Error)
+	}
+	return &code, nil
+}
+
+// Replace discards a user's recovery codes and stores a new set
+func (r *GormMFARecoveryCodeRepository) Replace(userID int64, codes []*models.MFARecoveryCode) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(codes).Error
+	})
+	if err != nil {
+		return fmt.Errorf("error replacing recovery codes: %w", err)
+	}
+	return nil
+}
+
+// MarkUsed consumes a code. It reports false when the code was already used.
+func (r *GormMFARecoveryCodeRepository) MarkUsed(id int64) (bool, error) {
+	result := r.db.Model(&models.MFARecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("error marking recovery code used: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUser removes every recovery code of a user
+func (r *GormMFARecoveryCodeRepository) DeleteByUser(userID int64) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{})
+	if result.Error != nil {
+		return fmt.Errorf("error deleting recovery codes: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"fmt"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasswordHistoryRepository defines the interface for password history data operations
+type PasswordHistoryRepository interface {
+	FindRecentByUser(userID int64, limit int) ([]*models.PasswordHistory, error)
+	Create(entry *models.PasswordHistory) error
+}
+
+// GormPasswordHistoryRepository implements PasswordHistoryRepository interface using GORM
+type GormPasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository creates a new PasswordHistoryRepository
+func NewPasswordHistoryRepository() PasswordHistoryRepository {
+	return &GormPasswordHistoryRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindRecentByUser retrieves a user's most recent previous passwords
+func (r *GormPasswordHistoryRepository) FindRecentByUser(userID int64, limit int) ([]*models.PasswordHistory, error) {
+	var entries []*models.PasswordHistory
+	result := r.db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&entries)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error finding password history: %w", result.Error)
+	}
+	return entries, nil
+}
+
+// Create inserts a new password history entry
+func (r *GormPasswordHistoryRepository) Create(entry *models.PasswordHistory) error {
+	result := r.db.Create(entry)
+	if result.Error != nil {
+		return fmt.Errorf("error creating password history: %w", result.Error)
+	}
+	return nil
+}
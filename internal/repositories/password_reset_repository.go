@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository defines the interface for password reset token data operations
+type PasswordResetRepository interface {
+	FindByHash(tokenHash string) (*models.PasswordResetToken, error)
+	Create(token *models.PasswordResetToken) error
+	MarkUsed(id int64) (bool, error)
+	InvalidateByUser(userID int64) error
+}
+
+// GormPasswordResetRepository implements PasswordResetRepository interface using GORM
+type GormPasswordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository creates a new PasswordResetRepository
+func NewPasswordResetRepository() PasswordResetRepository {
+	return &GormPasswordResetRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindByHash retrieves a reset token by the hash of its value
+func (r *GormPasswordResetRepository) FindByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	result := r.db.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("password reset token not found")
+		}
+		return nil, fmt.Errorf("error finding password reset token: %w", result.Error)
+	}
+	return &token, nil
+}
+
+// Create inserts a new reset token
+func (r *GormPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	result := r.db.Create(token)
+	if result.Error != nil {
+		return fmt.Errorf("error creating password reset token: %w", result.Error)
+	}
+	return nil
+}
+
+// MarkUsed consumes a token. It reports false when the token was already used.
+func (r *GormPasswordResetRepository) MarkUsed(id int64) (bool, error) {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("error marking password reset token used: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateByUser consumes every outstanding reset token of a user
+func (r *GormPasswordResetRepository) InvalidateByUser(userID int64) error {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("error invalidating password reset tokens: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"fmt"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PermissionRepository defines the interface for permission data operations
+type PermissionRepository interface {
+	FindOrCreateByNames(names []string) ([]*models.Permission, error)
+}
+
+// GormPermissionRepository implements PermissionRepository interface using GORM
+type GormPermissionRepository struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository creates a new PermissionRepository
+func NewPermissionRepository() PermissionRepository {
+	return &GormPermissionRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindOrCreateByNames retrieves permissions by name, creating missing rows
+func (r *GormPermissionRepository) FindOrCreateByNames(names []string) ([]*models.Permission, error) {
+	permissions := make([]*models.Permission, 0, len(names))
+	for _, name := range names {
+		var permission models.Permission
+		result := r.db.Where(models.Permission{Name: name}).FirstOrCreate(&permission)
+		if result.Error != nil {
+			return nil, fmt.Errorf("error finding permission %s: %w", name, result.Error)
+		}
+		permissions = append(permissions, &permission)
+	}
+	return permissions, nil
+}
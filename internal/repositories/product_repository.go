@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -12,11 +13,11 @@ import (
 
 // ProductRepository defines the interface for product data operations
 type ProductRepository interface {
-	Find(id int64) (*models.Product, error)
-	FindByCategory(categoryID int64) ([]*models.Product, error)
-	FindAll(limit, offset int) ([]*models.Product, error)
+	Find(ctx context.Context, id int64) (*models.Product, error)
+	FindByCategory(ctx context.Context, categoryID int64) ([]*models.Product, error)
+	FindAll(ctx context.Context, limit, offset int) ([]*models.Product, error)
 	Create(product *models.Product) error
-	Update(product *models.Product) error
+	Update(product *models.Product, columns ...string) error
 	Delete(id int64) error
 }
 
@@ -32,10 +33,10 @@ func NewProductRepository() ProductRepository {
 	}
 }
 
-// Find retrieves a product by ID
-func (r *GormProductRepository) Find(id int64) (*models.Product, error) {
+// Find retrieves a product by ID, from a read replica when configured
+func (r *GormProductRepository) Find(ctx context.Context, id int64) (*models.Product, error) {
 	var product models.Product
-	result := r.db.First(&product, id)
+	result := r.db.WithContext(ctx).Clauses(app.ReplicaRead).First(&product, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("product with ID %d not found", id)
@@ -45,20 +46,20 @@ func (r *GormProductRepository) Find(id int64) (*models.Product, error) {
 	return &product, nil
 }
 
-// FindByCategory retrieves products by category ID
-func (r *GormProductRepository) FindByCategory(categoryID int64) ([]*models.Product, error) {
+// FindByCategory retrieves products by category ID, from a read replica when configured
+func (r *GormProductRepository) FindByCategory(ctx context.Context, categoryID int64) ([]*models.Product, error) {
 	var products []*models.Product
-	result := r.db.Where("category_id = ?", categoryID).Order("name").Find(&products)
+	result := r.db.WithContext(ctx).Clauses(app.ReplicaRead).Where("category_id = ?", categoryID).Order("name").Find(&products)
 	if result.Error != nil {
 		return nil, fmt.Errorf("error finding products by category: %w", result.Error)
 	}
 	return products, nil
 }
 
-// FindAll retrieves products with pagination
-func (r *GormProductRepository) FindAll(limit, offset int) ([]*models.Product, error) {
+// FindAll retrieves products with pagination, from a read replica when configured
+func (r *GormProductRepository) FindAll(ctx context.Context, limit, offset int) ([]*models.Product, error) {
 	var products []*models.Product
-	result := r.db.Offset(offset).Limit(limit).Order("name").Find(&products)
+	result := r.db.WithContext(ctx).Clauses(app.ReplicaRead).Offset(offset).Limit(limit).Order("name").Find(&products)
 	if result.Error != nil {
 		return nil, fmt.Errorf("error finding products: %w", result.Error)
 	}
@@ -74,9 +75,10 @@ func (r *GormProductRepository) Create(product *models.Product) error {
 	return nil
 }
 
-// Update updates an existing product
-func (r *GormProductRepository) Update(product *models.Product) error {
-	result := r.db.Save(product)
+// Update writes the given columns of an existing product, leaving the
+// others as they are in the database
+func (r *GormProductRepository) Update(product *models.Product, columns ...string) error {
+	result := r.db.Model(product).Select(append(columns[:len(columns):len(columns)], "updated_at")).Updates(product)
 	if result.Error != nil {
 		return fmt.Errorf("error updating product: %w", result.Error)
 	}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+)
+
+func TestProductRepositoryCRUD(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewProductRepository()
+	ctx := context.Background()
+
+	product := &models.Product{Name: "Widget", SKU: "W-1", Price: 9.99, Stock: 5, CategoryID: 7, IsActive: true}
+	if err := repo.Create(product); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	found, err := repo.Find(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if found.Name != "Widget" || found.Price != 9.99 || found.Stock != 5 {
+		t.Errorf("Find = %+v", found)
+	}
+
+	found.Stock = 3
+	if err := repo.Update(found, "stock"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	updated, _ := repo.Find(ctx, product.ID)
+	if updated.Stock != 3 {
+		t.Errorf("Stock = %d after Update, want 3", updated.Stock)
+	}
+
+	if err := repo.Delete(product.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.Find(ctx, product.ID); err == nil {
+		t.Error("Find returned a deleted product")
+	}
+}
+
+func TestProductRepositoryListsByCategory(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewProductRepository()
+	ctx := context.Background()
+
+	for _, product := range []*models.Product{
+		{Name: "Bolt", SKU: "B-1", Price: 1, CategoryID: 1},
+		{Name: "Anchor", SKU: "A-1", Price: 2, CategoryID: 1},
+		{Name: "Cable", SKU: "C-1", Price: 3, CategoryID: 2},
+	} {
+		if err := repo.Create(product); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	inCategory, err := repo.FindByCategory(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindByCategory: %v", err)
+	}
+	if len(inCategory) != 2 || inCategory[0].Name != "Anchor" || inCategory[1].Name != "Bolt" {
+		t.Errorf("FindByCategory(1) = %v", inCategory)
+	}
+
+	all, err := repo.FindAll(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("FindAll returned %d products, want 3", len(all))
+	}
+
+	if err := repo.Create(&models.Product{Name: "Copy", SKU: "B-1", Price: 1}); err == nil {
+		t.Error("Create accepted a duplicate SKU")
+	}
+}
+
+func TestProductRepositoryUpdateWritesOnlyGivenColumns(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewProductRepository()
+	ctx := context.Background()
+
+	product := &models.Product{Name: "Widget", SKU: "W-1", Price: 9.99, Stock: 5, IsActive: true}
+	if err := repo.Create(product); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	stale, _ := repo.Find(ctx, product.ID)
+
+	fresh, _ := repo.Find(ctx, product.ID)
+	fresh.Price = 12.5
+	if err := repo.Update(fresh, "price"); err != nil {
+		t.Fatalf("Update price: %v", err)
+	}
+
+	// A copy read before the price change must not write the old price back
+	stale.Stock = 0
+	stale.IsActive = false
+	if err := repo.Update(stale, "stock", "is_active"); err != nil {
+		t.Fatalf("Update stock: %v", err)
+	}
+
+	updated, _ := repo.Find(ctx, product.ID)
+	if updated.Price != 12.5 || updated.Stock != 0 || updated.IsActive || updated.Name != "Widget" {
+		t.Errorf("after both updates = %+v", updated)
+	}
+
+	if err := repo.Update(&models.Product{ID: product.ID + 1, Stock: 1}, "stock"); err == nil {
+		t.Error("Update succeeded for an unknown product")
+	}
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository defines the interface for refresh token data operations
+type RefreshTokenRepository interface {
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	Create(token *models.RefreshToken) error
+	MarkUsed(id int64) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeByUser(userID int64) error
+}
+
+// GormRefreshTokenRepository implements RefreshTokenRepository interface using GORM
+type GormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new RefreshTokenRepository
+func NewRefreshTokenRepository() RefreshTokenRepository {
+	return &GormRefreshTokenRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindByHash retrieves a refresh token by the hash of its value
+func (r *GormRefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.db.Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("refresh token not found")
+		}
+		return nil, fmt.Errorf("error finding refresh token: %w", result.Error)
+	}
+	return &token, nil
+}
+
+// Create inserts a new refresh token
+func (r *GormRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	result := r.db.Create(token)
+	if result.Error != nil {
+		return fmt.Errorf("error creating refresh token: %w", result.Error)
+	}
+	return nil
+}
+
+// MarkUsed flags a token as consumed by a rotation. It reports false when
+// the token had already been used, so concurrent rotations cannot both win.
+func (r *GormRefreshTokenRepository) MarkUsed(id int64) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("error marking refresh token used: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily revokes every token descended from the same login
+func (r *GormRefreshTokenRepository) RevokeFamily(familyID string) error {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("error revoking refresh token family: %w", result.Error)
+	}
+	return nil
+}
+
+// RevokeByUser revokes every outstanding refresh token of a user
+func (r *GormRefreshTokenRepository) RevokeByUser(userID int64) error {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("error revoking refresh tokens: %w", result.Error)
+	}
+	return nil
+}
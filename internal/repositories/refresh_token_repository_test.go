@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+)
+
+func TestRefreshTokenRepositoryMarkUsedOnce(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewRefreshTokenRepository()
+
+	token := &models.RefreshToken{UserID: 1, FamilyID: "family-1", TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.Create(token); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	found, err := repo.FindByHash("hash-1")
+	if err != nil || found.ID != token.ID {
+		t.Fatalf("FindByHash = %v, %v", found, err)
+	}
+	if _, err := repo.FindByHash("unknown"); err == nil {
+		t.Error("FindByHash found an unknown hash")
+	}
+
+	if used, err := repo.MarkUsed(token.ID); err != nil || !used {
+		t.Fatalf("first MarkUsed = %v, %v", used, err)
+	}
+	if used, err := repo.MarkUsed(token.ID); err != nil || used {
+		t.Errorf("second MarkUsed = %v, %v, want false", used, err)
+	}
+}
+
+func TestRefreshTokenRepositoryRevokes(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewRefreshTokenRepository()
+	expiresAt := time.Now().Add(time.Hour)
+
+	for _, token := range []*models.RefreshToken{
+		{UserID: 1, FamilyID: "family-1", TokenHash: "hash-1", ExpiresAt: expiresAt},
+		{UserID: 1, FamilyID: "family-1", TokenHash: "hash-2", ExpiresAt: expiresAt},
+		{UserID: 1, FamilyID: "family-2", TokenHash: "hash-3", ExpiresAt: expiresAt},
+		{UserID: 2, FamilyID: "family-3", TokenHash: "hash-4", ExpiresAt: expiresAt},
+	} {
+		if err := repo.Create(token); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	if err := repo.RevokeFamily("family-1"); err != nil {
+		t.Fatalf("RevokeFamily: %v", err)
+	}
+	assertRevoked(t, repo, map[string]bool{"hash-1": true, "hash-2": true, "hash-3": false, "hash-4": false})
+
+	if err := repo.RevokeByUser(1); err != nil {
+		t.Fatalf("RevokeByUser: %v", err)
+	}
+	assertRevoked(t, repo, map[string]bool{"hash-1": true, "hash-2": true, "hash-3": true, "hash-4": false})
+
+	if err := repo.Create(&models.RefreshToken{UserID: 3, FamilyID: "family-4", TokenHash: "hash-1", ExpiresAt: expiresAt}); err == nil {
+		t.Error("Create accepted a duplicate token hash")
+	}
+}
+
+// assertRevoked checks which tokens, by hash, are revoked
+func assertRevoked(t *testing.T, repo RefreshTokenRepository, want map[string]bool) {
+	t.Helper()
+	for hash, revoked := range want {
+		token, err := repo.FindByHash(hash)
+		if err != nil {
+			t.Fatalf("FindByHash(%s): %v", hash, err)
+		}
+		if (token.RevokedAt != nil) != revoked {
+			t.Errorf("token %s revoked = %v, want %v", hash, token.RevokedAt != nil, revoked)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository defines the interface for role data operations
+type RoleRepository interface {
+	Find(id int64) (*models.Role, error)
+	FindByName(name string) (*models.Role, error)
+	FindAll() ([]*models.Role, error)
+	Create(role *models.Role) error
+	Update(role *models.Role) error
+	Delete(id int64) error
+}
+
+// GormRoleRepository implements RoleRepository interface using GORM
+type GormRoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new RoleRepository
+func NewRoleRepository() RoleRepository {
+	return &GormRoleRepository{
+		db: app.GetDB(),
+	}
+}
+
+// Find retrieves a role with its permissions by ID
+func (r *GormRoleRepository) Find(id int64) (*models.Role, error) {
+	var role models.Role
+	result := r.db.Preload("Permissions").First(&role, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("role with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding role: %w", result.Error)
+	}
+	return &role, nil
+}
+
+// FindByName retrieves a role with its permissions by name
+func (r *GormRoleRepository) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+	result := r.db.Preload("Permissions").Where("name = ?", name).First(&role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("role %s not found", name)
+		}
+		return nil, fmt.Errorf("error finding role: %w", result.Error)
+	}
+	return &role, nil
+}
+
+// FindAll retrieves all roles with their permissions
+func (r *GormRoleRepository) FindAll() ([]*models.Role, error) {
+	var roles []*models.Role
+	result := r.db.Preload("Permissions").Order("name").Find(&roles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error finding roles: %w", result.Error)
+	}
+	return roles, nil
+}
+
+// Create inserts a new role together with its permission links
+func (r *GormRoleRepository) Create(role *models.Role) error {
+	result := r.db.Omit("Permissions.*").Create(role)
+	if result.Error != nil {
+		return fmt.Errorf("error creating role: %w", result.Error)
+	}
+	return nil
+}
+
+// Update updates a role and replaces its permission links
+func (r *GormRoleRepository) Update(role *models.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("Permissions").Save(role)
+		if result.Error != nil {
+			return fmt.Errorf("error updating role: %w", result.Error)
+		}
+		if err := tx.Model(role).Omit("Permissions.*").Association("Permissions").Replace(role.Permissions); err != nil {
+			return fmt.Errorf("error updating role permissions: %w", err)
+		}
+		return nil
+	})
+}
+
+// Delete removes a role and all of its assignments
+func (r *GormRoleRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		role := &models.Role{ID: id}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return fmt.Errorf("error deleting role permissions: %w", err)
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+			return fmt.Errorf("error deleting role assignments: %w", err)
+		}
+		result := tx.Delete(role)
+		if result.Error != nil {
+			return fmt.Errorf("error deleting role: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("role with ID %d not found", id)
+		}
+		return nil
+	})
+}
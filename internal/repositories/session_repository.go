@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository defines the interface for session data operations
+type SessionRepository interface {
+	Find(id string) (*models.Session, error)
+	FindActiveByUser(userID int64) ([]*models.Session, error)
+	Create(session *models.Session) error
+	Touch(id, ip string, seenAt time.Time) error
+	Extend(id string, expiresAt time.Time) error
+	Revoke(id string) error
+	RevokeByUser(userID int64) (int64, error)
+}
+
+// GormSessionRepository implements SessionRepository interface using GORM
+type GormSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository() SessionRepository {
+	return &GormSessionRepository{
+		db: app.GetDB(),
+	}
+}
+
+// Find retrieves a session by ID
+func (r *GormSessionRepository) Find(id string) (*models.Session, error) {
+	var session models.Session
+	result := r.db.Where("id = ?", id).First(&session)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("error finding session: %w", result.Error)
+	}
+	return &session, nil
+}
+
+// FindActiveByUser retrieves the user's sessions that are neither revoked nor expired
+func (r *GormSessionRepository) FindActiveByUser(userID int64) ([]*models.Session, error) {
+	var sessions []*models.Session
+	result := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error finding sessions: %w", result.Error)
+	}
+	return sessions, nil
+}
+
+// Create inserts a new session
+func (r *GormSessionRepository) Create(session *models.Session) error {
+	result := r.db.Create(session)
+	if result.Error != nil {
+		return fmt.Errorf("error creating session: %w", result.Error)
+	}
+	return nil
+}
+
+// Touch records activity on a session
+func (r *GormSessionRepository) Touch(id, ip string, seenAt time.Time) error {
+	result := r.db.Model(&models.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"ip":           ip,
+		"last_seen_at": seenAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("error updating session: %w", result.Error)
+	}
+	return nil
+}
+
+// Extend moves the expiry of a session
+func (r *GormSessionRepository) Extend(id string, expiresAt time.Time) error {
+	result := r.db.Model(&models.Session{}).Where("id = ?", id).Update("expires_at", expiresAt)
+	if result.Error != nil {
+		return fmt.Errorf("error extending session: %w", result.Error)
+	}
+	return nil
+}
+
+// Revoke marks a session revoked
+func (r *GormSessionRepository) Revoke(id string) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("error revoking session: %w", result.Error)
+	}
+	return nil
+}
+
+// RevokeByUser revokes every session of a user and returns how many were active
+func (r *GormSessionRepository) RevokeByUser(userID int64) (int64, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("error revoking sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
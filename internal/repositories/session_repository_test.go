@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+)
+
+func TestSessionRepositoryLifecycle(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewSessionRepository()
+	now := time.Now()
+
+	session := &models.Session{ID: "session-1", UserID: 1, IP: "10.0.0.1", ExpiresAt: now.Add(time.Hour), LastSeenAt: now}
+	if err := repo.Create(session); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Touch("session-1", "10.0.0.2", now.Add(time.Minute)); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	if err := repo.Extend("session-1", now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+	found, err := repo.Find("session-1")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if found.IP != "10.0.0.2" || !found.ExpiresAt.After(now.Add(time.Hour)) {
+		t.Errorf("Find = %+v after Touch and Extend", found)
+	}
+
+	if err := repo.Revoke("session-1"); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	found, _ = repo.Find("session-1")
+	if found.RevokedAt == nil {
+		t.Error("RevokedAt is not set after Revoke")
+	}
+}
+
+func TestSessionRepositoryActiveSessions(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewSessionRepository()
+	now := time.Now()
+
+	for _, session := range []*models.Session{
+		{ID: "active-old", UserID: 1, ExpiresAt: now.Add(time.Hour), LastSeenAt: now.Add(-time.Hour)},
+		{ID: "active-new", UserID: 1, ExpiresAt: now.Add(time.Hour), LastSeenAt: now},
+		{ID: "expired", UserID: 1, ExpiresAt: now.Add(-time.Minute), LastSeenAt: now},
+		{ID: "other-user", UserID: 2, ExpiresAt: now.Add(time.Hour), LastSeenAt: now},
+	} {
+		if err := repo.Create(session); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	active, err := repo.FindActiveByUser(1)
+	if err != nil {
+		t.Fatalf("FindActiveByUser: %v", err)
+	}
+	if len(active) != 2 || active[0].ID != "active-new" || active[1].ID != "active-old" {
+		t.Errorf("FindActiveByUser(1) = %v", active)
+	}
+
+	revoked, err := repo.RevokeByUser(1)
+	if err != nil {
+		t.Fatalf("RevokeByUser: %v", err)
+	}
+	if revoked != 3 {
+		t.Errorf("RevokeByUser revoked %d sessions, want 3", revoked)
+	}
+	if active, _ := repo.FindActiveByUser(1); len(active) != 0 {
+		t.Errorf("%d sessions still active after RevokeByUser", len(active))
+	}
+	if active, _ := repo.FindActiveByUser(2); len(active) != 1 {
+		t.Error("RevokeByUser revoked another user's session")
+	}
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository defines the interface for external identity data operations
+type UserIdentityRepository interface {
+	FindByProviderSubject(provider, subject string) (*models.UserIdentity, error)
+	Create(identity *models.UserIdentity) error
+	TouchLastLogin(id int64, loginAt time.Time) error
+}
+
+// GormUserIdentityRepository implements UserIdentityRepository interface using GORM
+type GormUserIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository creates a new UserIdentityRepository
+func NewUserIdentityRepository() UserIdentityRepository {
+	return &GormUserIdentityRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindByProviderSubject retrieves the identity a provider knows by the given subject
+func (r *GormUserIdentityRepository) FindByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	result := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user identity not found")
+		}
+		return nil, fmt.Errorf("error finding user identity: %w", result.Error)
+	}
+	return &identity, nil
+}
+
+// Create inserts a new identity link
+func (r *GormUserIdentityRepository) Create(identity *models.UserIdentity) error {
+	result := r.db.Create(identity)
+	if result.Error != nil {
+		return fmt.Errorf("error creating user identity: %w", result.Error)
+	}
+	return nil
+}
+
+// TouchLastLogin records when an identity was last used to sign in
+func (r *GormUserIdentityRepository) TouchLastLogin(id int64, loginAt time.Time) error {
+	result := r.db.Model(&models.UserIdentity{}).Where("id = ?", id).Update("last_login_at", loginAt)
+	if result.Error != nil {
+		return fmt.Errorf("error updating user identity: %w", result.Error)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -13,11 +14,13 @@ import (
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	Find(id int64) (*models.User, error)
+	Lookup(ctx context.Context, id int64) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
 	FindByUsername(username string) (*models.User, error)
-	FindAll(limit, offset int) ([]*models.User, error)
+	FindAll(ctx context.Context, limit, offset int) ([]*models.User, error)
 	Create(user *models.User) error
 	Update(user *models.User) error
+	ClaimMFAStep(id, step int64) (bool, error)
 	Delete(id int64) error
 }
 
@@ -46,6 +49,20 @@ func (r *GormUserRepository) Find(id int64) (*models.User, error) {
 	return &user, nil
 }
 
+// Lookup retrieves a user by ID for display, from a read replica when
+// configured. Authentication and updates use Find, which reads the primary.
+func (r *GormUserRepository) Lookup(ctx context.Context, id int64) (*models.User, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).Clauses(app.ReplicaRead).First(&user, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("error finding user: %w", result.Error)
+	}
+	return &user, nil
+}
+
 // FindByEmail retrieves a user by email
 func (r *GormUserRepository) FindByEmail(email string) (*models.User, error) {
 	var user models.User
@@ -72,10 +89,10 @@ func (r *GormUserRepository) FindByUsername(username string) (*models.User, erro
 	return &user, nil
 }
 
-// FindAll retrieves users with pagination
-func (r *GormUserRepository) FindAll(limit, offset int) ([]*models.User, error) {
+// FindAll retrieves users with pagination, from a read replica when configured
+func (r *GormUserRepository) FindAll(ctx context.Context, limit, offset int) ([]*models.User, error) {
 	var users []*models.User
-	result := r.db.Offset(offset).Limit(limit).Order("id").Find(&users)
+	result := r.db.WithContext(ctx).Clauses(app.ReplicaRead).Offset(offset).Limit(limit).Order("id").Find(&users)
 	if result.Error != nil {
 		return nil, fmt.Errorf("error finding users: %w", result.Error)
 	}
@@ -103,6 +120,19 @@ func (r *GormUserRepository) Update(user *models.User) error {
 	return nil
 }
 
+// ClaimMFAStep records step as the user's last accepted TOTP time step
+// unless it is not newer than the recorded one. It reports whether the
+// step was claimed, so of two logins racing with one code only one wins.
+func (r *GormUserRepository) ClaimMFAStep(id, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND mfa_last_step < ?", id, step).
+		Update("mfa_last_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("error claiming MFA step: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
 // Delete removes a user by ID
 func (r *GormUserRepository) Delete(id int64) error {
 	result := r.db.Delete(&models.User{}, id)
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+)
+
+// newTestUser returns a user that can be inserted as is
+func newTestUser(name string) *models.User {
+	return &models.User{
+		Username: name,
+		Email:    name + "@example.com",
+		Password: "hash",
+		IsActive: true,
+	}
+}
+
+func TestUserRepositoryCreateAndFind(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewUserRepository()
+
+	user := newTestUser("alice")
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if user.ID == 0 {
+		t.Fatal("Create did not assign an ID")
+	}
+
+	byID, err := repo.Find(user.ID)
+	if err != nil || byID.Username != "alice" {
+		t.Fatalf("Find = %v, %v", byID, err)
+	}
+	byEmail, err := repo.FindByEmail("alice@example.com")
+	if err != nil || byEmail.ID != user.ID {
+		t.Fatalf("FindByEmail = %v, %v", byEmail, err)
+	}
+	byName, err := repo.FindByUsername("alice")
+	if err != nil || byName.ID != user.ID {
+		t.Fatalf("FindByUsername = %v, %v", byName, err)
+	}
+	looked, err := repo.Lookup(context.Background(), user.ID)
+	if err != nil || looked.ID != user.ID {
+		t.Fatalf("Lookup = %v, %v", looked, err)
+	}
+
+	if _, err := repo.FindByEmail("nobody@example.com"); err == nil {
+		t.Error("FindByEmail found an unknown email")
+	}
+}
+
+func TestUserRepositoryRejectsDuplicates(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewUserRepository()
+
+	if err := repo.Create(newTestUser("bob")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Create(newTestUser("bob")); err == nil {
+		t.Error("Create accepted a duplicate username and email")
+	}
+}
+
+func TestUserRepositoryUpdateAndDelete(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewUserRepository()
+
+	user := newTestUser("carol")
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	user.FirstName = "Carol"
+	if err := repo.Update(user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	updated, _ := repo.Find(user.ID)
+	if updated.FirstName != "Carol" {
+		t.Errorf("FirstName = %q after Update", updated.FirstName)
+	}
+
+	if err := repo.Delete(user.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.Find(user.ID); err == nil {
+		t.Error("Find returned a deleted user")
+	}
+	if err := repo.Delete(user.ID); err == nil {
+		t.Error("Delete of a deleted user succeeded")
+	}
+}
+
+func TestUserRepositoryFindAllPages(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewUserRepository()
+
+	for _, name := range []string{"user1", "user2", "user3"} {
+		if err := repo.Create(newTestUser(name)); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	page, err := repo.FindAll(context.Background(), 2, 1)
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	if len(page) != 2 || page[0].Username != "user2" || page[1].Username != "user3" {
+		t.Errorf("FindAll(2, 1) = %d users starting with %v", len(page), page)
+	}
+}
+
+func TestUserRepositoryClaimMFAStep(t *testing.T) {
+	testutil.SetupDB(t, testutil.Config())
+	repo := NewUserRepository()
+
+	user := newTestUser("dave")
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	tests := []struct {
+		step int64
+		want bool
+	}{
+		{100, true},
+		{100, false},
+		{99, false},
+		{101, true},
+	}
+	for _, tt := range tests {
+		claimed, err := repo.ClaimMFAStep(user.ID, tt.step)
+		if err != nil || claimed != tt.want {
+			t.Errorf("ClaimMFAStep(%d) = %v, %v, want %v", tt.step, claimed, err, tt.want)
+		}
+	}
+	if stored, _ := repo.Find(user.ID); stored.MFALastStep != 101 {
+		t.Errorf("MFALastStep = %d, want 101", stored.MFALastStep)
+	}
+	if claimed, _ := repo.ClaimMFAStep(user.ID+1, 200); claimed {
+		t.Error("ClaimMFAStep claimed a step for an unknown user")
+	}
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"fmt"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserRoleRepository defines the interface for user-role assignment operations
+type UserRoleRepository interface {
+	FindRolesByUser(userID int64) ([]*models.Role, error)
+	FindPermissionNamesByUser(userID int64) ([]string, error)
+	Assign(userID, roleID int64) error
+	Revoke(userID, roleID int64) error
+}
+
+// GormUserRoleRepository implements UserRoleRepository interface using GORM
+type GormUserRoleRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRoleRepository creates a new UserRoleRepository
+func NewUserRoleRepository() UserRoleRepository {
+	return &GormUserRoleRepository{
+		db: app.GetDB(),
+	}
+}
+
+// FindRolesByUser retrieves the roles assigned to a user
+func (r *GormUserRoleRepository) FindRolesByUser(userID int64) ([]*models.Role, error) {
+	var roles []*models.Role
+	result := r.db.Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Find(&roles)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error finding user roles: %w", result.Error)
+	}
+	return roles, nil
+}
+
+// FindPermissionNamesByUser retrieves the distinct permissions granted to a user through roles
+func (r *GormUserRoleRepository) FindPermissionNamesByUser(userID int64) ([]string, error) {
+	var names []string
+	result := r.db.Model(&models.Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permissions.name", &names)
+	if result.Error != nil {
+		return nil, fmt.Errorf("error finding user permissions: %w", result.Error)
+	}
+	return names, nil
+}
+
+// Assign grants a role to a user; assigning an existing role is a no-op
+func (r *GormUserRoleRepository) Assign(userID, roleID int64) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserRole{
+		UserID: userID,
+		RoleID: roleID,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("error assigning role: %w", result.Error)
+	}
+	return nil
+}
+
+// Revoke removes a role from a user
+func (r *GormUserRoleRepository) Revoke(userID, roleID int64) error {
+	result := r.db.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{})
+	if result.Error != nil {
+		return fmt.Errorf("error revoking role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("role %d is not assigned to user %d", roleID, userID)
+	}
+	return nil
+}
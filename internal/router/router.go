@@ -5,6 +5,7 @@ import (
 	"goapp/internal/context"
 	"goapp/internal/controllers"
 	"goapp/internal/middleware"
+	"goapp/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,17 +13,18 @@ import (
 // SetupRouter initializes the router and registers all routes
 func SetupRouter() *gin.Engine {
 	// Set Gin mode based on config
-	gin.SetMode(app.ConfigData.Server.Mode)
+	gin.SetMode(app.GetConfig().Server.Mode)
 
 	// Create router with default middleware
 	router := gin.New()
 
 	// Add custom middleware in correct order
-	router.Use(middleware.LoggerMiddleware())   // First to set request ID
-	router.Use(middleware.RecoveryMiddleware()) // Then recovery
-	router.Use(middleware.CORSMiddleware())     // Then CORS
-	router.Use(middleware.EventsMiddleware())   // Then event tracking
-	router.Use(middleware.ResponseFormatter())  // Finally response formatting
+	router.Use(middleware.LoggerMiddleware())         // First to set request ID
+	router.Use(middleware.RecoveryMiddleware())       // Then recovery
+	router.Use(middleware.CORSMiddleware())           // Then CORS
+	router.Use(middleware.EventsMiddleware())         // Then event tracking
+	router.Use(middleware.ReadYourWritesMiddleware()) // Then replica routing
+	router.Use(middleware.ResponseFormatter())        // Finally response formatting
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -41,37 +43,29 @@ func SetupRouter() *gin.Engine {
 
 		// User routes
 		userController := controllers.NewUserController()
-		users := v1.Group("/users")
-		{
-			// Public user endpoints
-			users.POST("/register", userController.CreateUser)
-			users.POST("/login", userController.Login)
+		userController.Register(v1)
 
-			// Protected user endpoints
-			protected := users.Use(middleware.AuthMiddleware())
-			{
-				protected.GET("", userController.ListUsers)
-				protected.GET("/:id", userController.GetUser)
-				protected.PUT("/:id", userController.UpdateUser)
-				protected.PUT("/:id/password", userController.UpdatePassword)
+		// API key routes
+		apiKeyController := controllers.NewAPIKeyController()
+		apiKeyController.Register(v1)
 
-				// Admin-only endpoints
-				admin := protected.Use(middleware.AdminMiddleware())
-				{
-					admin.DELETE("/:id", userController.DeleteUser)
-				}
-			}
-		}
+		// Session routes
+		sessionController := controllers.NewSessionController()
+		sessionController.Register(v1)
 
 		// Product routes
 		productController := controllers.NewProductController()
 		productController.Register(v1)
 
-		// Monitoring routes (admin only)
-		monitorController := controllers.NewMonitorController()
-		admin := v1.Group("/admin")
-		adminProtected := admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
-		monitorController.Register(adminProtected.(*gin.RouterGroup))
+		// Admin routes, each guarded by its own permission
+		admin := v1.Group("/admin", middleware.AuthMiddleware())
+		{
+			monitorController := controllers.NewMonitorController()
+			monitorController.Register(admin.Group("", middleware.RequirePermission(models.PermMetricsRead)))
+
+			roleController := controllers.NewRoleController()
+			roleController.Register(admin.Group("", middleware.RequirePermission(models.PermRoleManage)))
+		}
 	}
 
 	return router
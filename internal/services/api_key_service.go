@@ -0,0 +1,100 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/utils"
+)
+
+var (
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrAPIKeyExpiryInPast   = errors.New("api key expiry must be in the future")
+	ErrAPIKeyAlreadyRevoked = errors.New("api key is already revoked")
+)
+
+// APIKeyService handles issuing and revoking user-owned API keys
+type APIKeyService struct {
+	keyRepo repositories.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new APIKeyService
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{
+		keyRepo: repositories.NewAPIKeyRepository(),
+	}
+}
+
+// CreateKey issues a new API key. The returned plaintext key is never stored
+// and cannot be retrieved again.
+func (s *APIKeyService) CreateKey(userID int64, name string, scopes []string, expiresAt *time.Time) (*models.APIKey, string, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", ErrAPIKeyExpiryInPast
+	}
+
+	scopes = utils.RemoveDuplicate(scopes)
+	for _, scope := range scopes {
+		if !utils.InArray(scope, models.AllPermissions) {
+			return nil, "", fmt.Errorf("%w: %s", ErrUnknownPermission, scope)
+		}
+	}
+
+	prefix, secret, err := generateAPIKeyParts()
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext := models.APIKeyPrefix + prefix + "_" + secret
+
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   utils.SHA256(plaintext),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.keyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	app.Info("API key created", "user_id", userID, "api_key_id", key.ID, "scopes", scopes)
+	return key, plaintext, nil
+}
+
+// ListKeys returns every API key of a user
+func (s *APIKeyService) ListKeys(userID int64) ([]*models.APIKey, error) {
+	return s.keyRepo.FindByUser(userID)
+}
+
+// RevokeKey revokes one of the user's API keys
+func (s *APIKeyService) RevokeKey(userID, keyID int64) error {
+	key, err := s.keyRepo.Find(keyID)
+	if err != nil || key.UserID != userID {
+		return ErrAPIKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return ErrAPIKeyAlreadyRevoked
+	}
+
+	if err := s.keyRepo.Revoke(key.ID); err != nil {
+		return err
+	}
+
+	app.Info("API key revoked", "user_id", userID, "api_key_id", key.ID)
+	return nil
+}
+
+// generateAPIKeyParts returns a random lookup prefix and secret
+func generateAPIKeyParts() (string, string, error) {
+	buf := make([]byte, 36)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("error generating api key: %w", err)
+	}
+	return hex.EncodeToString(buf[:4]), hex.EncodeToString(buf[4:]), nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"goapp/internal/app"
+)
+
+// AttemptStore keeps failed-attempt counters and lockouts by key
+type AttemptStore interface {
+	// Fail records a failure and returns the number of failures within the window
+	Fail(key string, window time.Duration) (int64, error)
+	// Lock blocks the key for the given duration and keeps its failures
+	// for a further window, so failures right after the lockout escalate it
+	Lock(key string, duration, window time.Duration) error
+	// LockedFor returns how long the key stays locked, or zero if it is not
+	LockedFor(key string) (time.Duration, error)
+	// Reset clears the failures and lockout of the key
+	Reset(key string) error
+}
+
+// MemoryAttemptStore implements AttemptStore in process memory
+type MemoryAttemptStore struct {
+	entries map[string]*attemptEntry
+	sweepAt time.Time
+	mutex   sync.Mutex
+}
+
+// attemptSweepInterval is how often MemoryAttemptStore drops expired keys
+const attemptSweepInterval = time.Minute
+
+// attemptEntry tracks the failures and lockout of one key
+type attemptEntry struct {
+	failures    int64
+	expiresAt   time.Time
+	lockedUntil time.Time
+}
+
+// RedisAttemptStore implements AttemptStore in Redis so counters are shared between instances
+type RedisAttemptStore struct {
+	client *app.RedisClient
+	prefix string
+}
+
+var (
+	sharedAttemptStore     AttemptStore
+	sharedAttemptStoreOnce sync.Once
+)
+
+// NewMemoryAttemptStore creates an empty MemoryAttemptStore
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{
+		entries: make(map[string]*attemptEntry),
+	}
+}
+
+// NewRedisAttemptStore creates a RedisAttemptStore using the given client
+func NewRedisAttemptStore(client *app.RedisClient) *RedisAttemptStore {
+	return &RedisAttemptStore{
+		client: client,
+		prefix: "login_attempts:",
+	}
+}
+
+// defaultAttemptStore returns the store selected in the configuration.
+// It is shared so every service instance sees the same counters.
+func defaultAttemptStore() AttemptStore {
+	sharedAttemptStoreOnce.Do(func() {
+		switch app.GetConfig().LoginProtection.Store {
+		case "redis":
+			sharedAttemptStore = NewRedisAttemptStore(app.Redis)
+		default:
+			sharedAttemptStore = NewMemoryAttemptStore()
+		}
+	})
+	return sharedAttemptStore
+}
+
+// Fail records a failure for the key
+func (s *MemoryAttemptStore) Fail(key string, window time.Duration) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.evictExpired(now)
+
+	entry, exists := s.entries[key]
+	if !exists || now.After(entry.expiresAt) {
+		entry = &attemptEntry{}
+		s.entries[key] = entry
+	}
+
+	entry.failures++
+	entry.expiresAt = now.Add(window)
+	return entry.failures, nil
+}
+
+// Lock blocks the key for the given duration
+func (s *MemoryAttemptStore) Lock(key string, duration, window time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, exists := s.entries[key]
+	if !exists {
+		entry = &attemptEntry{}
+		s.entries[key] = entry
+	}
+
+	entry.lockedUntil = time.Now().Add(duration)
+	if keepUntil := entry.lockedUntil.Add(window); entry.expiresAt.Before(keepUntil) {
+		entry.expiresAt = keepUntil
+	}
+	return nil
+}
+
+// LockedFor returns the remaining lockout of the key
+func (s *MemoryAttemptStore) LockedFor(key string) (time.Duration, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, exists := s.entries[key]
+	if !exists {
+		return 0, nil
+	}
+
+	remaining := time.Until(entry.lockedUntil)
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// Reset clears the key
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// evictExpired drops forgotten keys so the map does not grow without bound.
+// It sweeps at most once per attemptSweepInterval, keeping the cost per
+// failure constant. The caller holds the mutex.
+func (s *MemoryAttemptStore) evictExpired(now time.Time) {
+	if now.Before(s.sweepAt) {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.sweepAt = now.Add(attemptSweepInterval)
+}
+
+// Fail increments the failure counter and extends its expiry
+func (s *RedisAttemptStore) Fail(key string, window time.Duration) (int64, error) {
+	failKey := s.prefix + "fail:" + key
+	failures, err := s.client.Incr(failKey)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.client.Expire(failKey, window); err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
+// Lock stores a lock key that expires with the lockout, and keeps the
+// failure counter until a window after it so the next lockout escalates
+func (s *RedisAttemptStore) Lock(key string, duration, window time.Duration) error {
+	if err := s.client.Set(s.prefix+"lock:"+key, "1", duration); err != nil {
+		return err
+	}
+	return s.client.Expire(s.prefix+"fail:"+key, duration+window)
+}
+
+// LockedFor returns the remaining TTL of the lock key
+func (s *RedisAttemptStore) LockedFor(key string) (time.Duration, error) {
+	ttl, err := s.client.GetTTL(s.prefix + "lock:" + key)
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Reset deletes the failure counter and lock key
+func (s *RedisAttemptStore) Reset(key string) error {
+	if err := s.client.Delete(s.prefix + "fail:" + key); err != nil {
+		return err
+	}
+	return s.client.Delete(s.prefix + "lock:" + key)
+}
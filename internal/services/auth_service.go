@@ -0,0 +1,206 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/events"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// TokenPair is an access token together with the refresh token that renews it
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// AuthService handles issuing, rotating and revoking user tokens
+type AuthService struct {
+	userRepo    repositories.UserRepository
+	tokenRepo   repositories.RefreshTokenRepository
+	sessionRepo repositories.SessionRepository
+}
+
+// NewAuthService creates a new AuthService
+func NewAuthService() *AuthService {
+	return &AuthService{
+		userRepo:    repositories.NewUserRepository(),
+		tokenRepo:   repositories.NewRefreshTokenRepository(),
+		sessionRepo: repositories.NewSessionRepository(),
+	}
+}
+
+// IssueTokens starts a new session for a freshly authenticated user
+func (s *AuthService) IssueTokens(user *models.User, userAgent, clientIP string) (*TokenPair, error) {
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IP:         clientIP,
+		ExpiresAt:  now.Add(app.GetConfig().JWT.RefreshTokenTTL.Duration()),
+		LastSeenAt: now,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.issue(user, session.ID)
+}
+
+// Refresh rotates a refresh token, returning a new pair in the same family.
+// Presenting a token that was already rotated revokes the whole family.
+func (s *AuthService) Refresh(refreshToken, clientIP string) (*TokenPair, error) {
+	stored, err := s.tokenRepo.FindByHash(utils.SHA256(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		return nil, s.handleReuse(stored, clientIP)
+	}
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Claim the token before issuing its successor; losing the race means
+	// somebody else presented the same token at the same time
+	claimed, err := s.tokenRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, s.handleReuse(stored, clientIP)
+	}
+
+	session, err := s.sessionRepo.Find(stored.FamilyID)
+	if err != nil || session.RevokedAt != nil {
+		if err := s.tokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			app.Error("Failed to revoke tokens of an ended session", "error", err, "family_id", stored.FamilyID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.Find(stored.UserID)
+	if err != nil || !user.IsActive {
+		if err := s.revokeSession(stored.FamilyID); err != nil {
+			app.Error("Failed to revoke session of an inactive user", "error", err, "family_id", stored.FamilyID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if err := s.sessionRepo.Touch(session.ID, clientIP, time.Now()); err != nil {
+		app.Error("Failed to update session", "error", err, "session_id", session.ID)
+	}
+	return s.issue(user, stored.FamilyID)
+}
+
+// Logout ends the session of the given refresh token
+func (s *AuthService) Logout(userID int64, refreshToken string) error {
+	stored, err := s.tokenRepo.FindByHash(utils.SHA256(refreshToken))
+	if err != nil || stored.UserID != userID {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := s.revokeSession(stored.FamilyID); err != nil {
+		return err
+	}
+
+	events.Publish(events.UserLoggedOut, map[string]interface{}{
+		"user_id":   userID,
+		"family_id": stored.FamilyID,
+	})
+	return nil
+}
+
+// issue signs an access token and stores a new refresh token in the session's family
+func (s *AuthService) issue(user *models.User, familyID string) (*TokenPair, error) {
+	accessToken, accessExpiresAt, err := app.JWT.GenerateAccessToken(user.ID, user.Username, user.IsAdmin, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshExpiresAt := time.Now().Add(app.GetConfig().JWT.RefreshTokenTTL.Duration())
+	if err := s.tokenRepo.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.SHA256(refreshToken),
+		ExpiresAt: refreshExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.Extend(familyID, refreshExpiresAt); err != nil {
+		app.Error("Failed to extend session", "error", err, "session_id", familyID)
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// revokeSession revokes a session together with its refresh tokens
+func (s *AuthService) revokeSession(sessionID string) error {
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	return s.tokenRepo.RevokeFamily(sessionID)
+}
+
+// handleReuse revokes a compromised session and raises an alert. It returns
+// ErrRefreshTokenReused, or the revocation error if the family may still be
+// valid.
+func (s *AuthService) handleReuse(token *models.RefreshToken, clientIP string) error {
+	revokeErr := s.revokeSession(token.FamilyID)
+	if revokeErr != nil {
+		app.Error("Failed to revoke compromised session", "error", revokeErr, "family_id", token.FamilyID)
+	}
+
+	app.Warn("Refresh token reuse detected", "user_id", token.UserID, "family_id", token.FamilyID, "ip", clientIP)
+	events.Publish(events.SecurityAlert, map[string]interface{}{
+		"type":      "refresh_token_reuse",
+		"user_id":   token.UserID,
+		"family_id": token.FamilyID,
+		"ip":        clientIP,
+	})
+
+	if revokeErr != nil {
+		return fmt.Errorf("error revoking reused token family: %w", revokeErr)
+	}
+	return ErrRefreshTokenReused
+}
+
+// generateOpaqueToken returns a random token for refresh, reset and similar flows
+func generateOpaqueToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
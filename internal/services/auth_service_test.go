@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/internal/testutil"
+
+	"gorm.io/gorm"
+)
+
+// setupServiceTest installs the test configuration, changed by configure,
+// an in-memory database and a token manager
+func setupServiceTest(t *testing.T, configure func(cfg *app.Config)) *gorm.DB {
+	t.Helper()
+	cfg := testutil.Config()
+	if configure != nil {
+		configure(&cfg)
+	}
+	db := testutil.SetupDB(t, cfg)
+	testutil.SetupJWT(t, cfg)
+	return db
+}
+
+// failingTokenRepo is a RefreshTokenRepository that cannot revoke families
+type failingTokenRepo struct {
+	repositories.RefreshTokenRepository
+}
+
+func (failingTokenRepo) RevokeFamily(familyID string) error {
+	return errors.New("database is unavailable")
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewAuthService()
+
+	first, err := service.IssueTokens(user, "test-agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	claims, appErr := app.JWT.ParseAccessToken(first.AccessToken)
+	if appErr != nil || claims.UserID != user.ID {
+		t.Fatalf("access token claims = %+v, %v", claims, appErr)
+	}
+
+	second, err := service.Refresh(first.RefreshToken, "10.0.0.2")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("Refresh returned the same refresh token")
+	}
+	secondClaims, appErr := app.JWT.ParseAccessToken(second.AccessToken)
+	if appErr != nil || secondClaims.SessionID != claims.SessionID {
+		t.Errorf("rotated token left the session: %+v, %v", secondClaims, appErr)
+	}
+
+	var session models.Session
+	db.First(&session, "id = ?", claims.SessionID)
+	if session.IP != "10.0.0.2" {
+		t.Errorf("session IP = %q after refresh", session.IP)
+	}
+
+	if _, err := service.Refresh(second.RefreshToken, "10.0.0.2"); err != nil {
+		t.Errorf("refresh with the rotated token: %v", err)
+	}
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewAuthService()
+
+	stolen, err := service.IssueTokens(user, "test-agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	current, err := service.Refresh(stolen.RefreshToken, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	other, err := service.IssueTokens(user, "other-device", "10.0.0.3")
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+
+	if _, err := service.Refresh(stolen.RefreshToken, "10.0.0.9"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse: err = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := service.Refresh(current.RefreshToken, "10.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("token of the revoked family: err = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	claims, _ := app.JWT.ParseAccessToken(stolen.AccessToken)
+	var session models.Session
+	db.First(&session, "id = ?", claims.SessionID)
+	if session.RevokedAt == nil {
+		t.Error("session of the reused token not revoked")
+	}
+
+	// Other sessions of the user are left alone
+	if _, err := service.Refresh(other.RefreshToken, "10.0.0.3"); err != nil {
+		t.Errorf("refresh of another session: %v", err)
+	}
+}
+
+func TestRefreshReuseReportsFailedRevocation(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewAuthService()
+
+	tokens, err := service.IssueTokens(user, "test-agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	if _, err := service.Refresh(tokens.RefreshToken, "10.0.0.1"); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	service.tokenRepo = failingTokenRepo{service.tokenRepo}
+	_, err = service.Refresh(tokens.RefreshToken, "10.0.0.9")
+	if err == nil || errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("reuse with a failed revocation: err = %v, want the revocation error", err)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, db *gorm.DB, user *models.User, tokens *TokenPair)
+		token   func(tokens *TokenPair) string
+	}{
+		{
+			name:  "unknown token",
+			token: func(*TokenPair) string { return "unknown" },
+		},
+		{
+			name: "expired token",
+			prepare: func(_ *testing.T, db *gorm.DB, _ *models.User, _ *TokenPair) {
+				db.Model(&models.RefreshToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute))
+			},
+		},
+		{
+			name: "revoked session",
+			prepare: func(_ *testing.T, db *gorm.DB, _ *models.User, _ *TokenPair) {
+				db.Model(&models.Session{}).Where("1 = 1").Update("revoked_at", time.Now())
+			},
+		},
+		{
+			name: "inactive user",
+			prepare: func(_ *testing.T, db *gorm.DB, user *models.User, _ *TokenPair) {
+				db.Model(user).Update("is_active", false)
+			},
+		},
+		{
+			name: "logged out",
+			prepare: func(t *testing.T, _ *gorm.DB, user *models.User, tokens *TokenPair) {
+				if err := NewAuthService().Logout(user.ID, tokens.RefreshToken); err != nil {
+					t.Fatalf("Logout: %v", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTest(t, nil)
+			user := testutil.CreateUser(t, db, "alice", "Password1")
+			service := NewAuthService()
+
+			tokens, err := service.IssueTokens(user, "test-agent", "10.0.0.1")
+			if err != nil {
+				t.Fatalf("IssueTokens: %v", err)
+			}
+			if tt.prepare != nil {
+				tt.prepare(t, db, user, tokens)
+			}
+			token := tokens.RefreshToken
+			if tt.token != nil {
+				token = tt.token(tokens)
+			}
+
+			if _, err := service.Refresh(token, "10.0.0.1"); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Errorf("err = %v, want ErrInvalidRefreshToken", err)
+			}
+		})
+	}
+}
+
+func TestLogoutChecksOwner(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	alice := testutil.CreateUser(t, db, "alice", "Password1")
+	bob := testutil.CreateUser(t, db, "bob", "Password1")
+	service := NewAuthService()
+
+	tokens, err := service.IssueTokens(alice, "test-agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	if err := service.Logout(bob.ID, tokens.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("logout by another user: err = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, err := service.Refresh(tokens.RefreshToken, "10.0.0.1"); err != nil {
+		t.Errorf("refresh after a rejected logout: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/events"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/utils"
+)
+
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrTooManyVerifyRequests    = errors.New("too many verification email requests")
+)
+
+// EmailVerificationService handles verifying that users own their email address
+type EmailVerificationService struct {
+	userRepo  repositories.UserRepository
+	tokenRepo repositories.EmailVerificationRepository
+	limiter   *windowLimiter
+}
+
+// NewEmailVerificationService creates a new EmailVerificationService
+func NewEmailVerificationService() *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:  repositories.NewUserRepository(),
+		tokenRepo: repositories.NewEmailVerificationRepository(),
+		limiter:   newWindowLimiter(func() int { return app.GetConfig().EmailVerification.MaxResendsPerHour }, time.Hour),
+	}
+}
+
+// SendVerification issues a new verification token and mails it to the user
+func (s *EmailVerificationService) SendVerification(user *models.User) error {
+	// Only the most recently issued token stays valid
+	if err := s.tokenRepo.InvalidateByUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := app.GetConfig().EmailVerification.TokenTTL.Duration()
+	if err := s.tokenRepo.Create(&models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: utils.SHA256(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return err
+	}
+
+	notification := &app.Notification{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s%s\n",
+			user.Username, ttl, app.GetConfig().EmailVerification.VerifyURL, token),
+	}
+	if err := app.Mailer.Send(notification); err != nil {
+		return fmt.Errorf("error sending verification email: %w", err)
+	}
+
+	app.Info("Verification email sent", "user_id", user.ID)
+	return nil
+}
+
+// ResendVerification mails a fresh token to an unverified address.
+// The result is the same whether or not the address is known.
+func (s *EmailVerificationService) ResendVerification(email string) error {
+	email = strings.TrimSpace(email)
+	if !s.limiter.Allow(strings.ToLower(email)) {
+		return ErrTooManyVerifyRequests
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil || user.EmailVerified {
+		return nil
+	}
+
+	go func() {
+		if err := s.SendVerification(user); err != nil {
+			app.Error("Failed to resend verification email", "error", err, "user_id", user.ID)
+		}
+	}()
+	return nil
+}
+
+// Verify consumes a verification token and marks the user's address as verified
+func (s *EmailVerificationService) Verify(token string) (*models.User, error) {
+	stored, err := s.tokenRepo.FindByHash(utils.SHA256(token))
+	if err != nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	claimed, err := s.tokenRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.Find(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.VerifiedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	events.Publish(events.UserUpdated, map[string]interface{}{
+		"user_id":        user.ID,
+		"email_verified": true,
+	})
+	app.Info("Email address verified", "user_id", user.ID)
+	return user, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+
+	"gorm.io/gorm"
+)
+
+// createUnverifiedUser inserts a user whose address is not verified yet
+func createUnverifiedUser(t *testing.T, db *gorm.DB, username string) *models.User {
+	t.Helper()
+	user := testutil.CreateUser(t, db, username, "Password1")
+	db.Model(user).Update("email_verified", false)
+	user.EmailVerified = false
+	return user
+}
+
+// sendVerificationToken mails a verification token to user and returns it
+func sendVerificationToken(t *testing.T, service *EmailVerificationService, outbox *testutil.Outbox, user *models.User) string {
+	t.Helper()
+	if err := service.SendVerification(user); err != nil {
+		t.Fatalf("SendVerification: %v", err)
+	}
+	return linkToken(t, outbox.Next(t), app.GetConfig().EmailVerification.VerifyURL)
+}
+
+func TestVerifyTokenIsSingleUse(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	outbox := testutil.SetupMailer(t)
+	user := createUnverifiedUser(t, db, "alice")
+	service := NewEmailVerificationService()
+
+	token := sendVerificationToken(t, service, outbox, user)
+	verified, err := service.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if verified.ID != user.ID || !verified.EmailVerified || verified.VerifiedAt == nil {
+		t.Errorf("Verify returned %+v", verified)
+	}
+	var stored models.User
+	db.First(&stored, user.ID)
+	if !stored.EmailVerified {
+		t.Error("verification was not saved")
+	}
+
+	if _, err := service.Verify(token); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Errorf("second use = %v, want ErrInvalidVerificationToken", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, db *gorm.DB, user *models.User, token string) string
+	}{
+		{"unknown token", func(t *testing.T, db *gorm.DB, user *models.User, token string) string {
+			return "unknown"
+		}},
+		{"expired token", func(t *testing.T, db *gorm.DB, user *models.User, token string) string {
+			db.Model(&models.EmailVerificationToken{}).Where("user_id = ?", user.ID).
+				Update("expires_at", time.Now().Add(-time.Minute))
+			return token
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTest(t, nil)
+			outbox := testutil.SetupMailer(t)
+			user := createUnverifiedUser(t, db, "alice")
+			service := NewEmailVerificationService()
+
+			token := tt.prepare(t, db, user, sendVerificationToken(t, service, outbox, user))
+			if _, err := service.Verify(token); !errors.Is(err, ErrInvalidVerificationToken) {
+				t.Errorf("Verify = %v, want ErrInvalidVerificationToken", err)
+			}
+			var stored models.User
+			db.First(&stored, user.ID)
+			if stored.EmailVerified {
+				t.Error("address was marked verified")
+			}
+		})
+	}
+}
+
+func TestSendVerificationSupersedesEarlierTokens(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	outbox := testutil.SetupMailer(t)
+	user := createUnverifiedUser(t, db, "alice")
+	service := NewEmailVerificationService()
+
+	first := sendVerificationToken(t, service, outbox, user)
+	second := sendVerificationToken(t, service, outbox, user)
+	if _, err := service.Verify(first); !errors.Is(err, ErrInvalidVerificationToken) {
+		t.Errorf("superseded token = %v, want ErrInvalidVerificationToken", err)
+	}
+	if _, err := service.Verify(second); err != nil {
+		t.Errorf("latest token: %v", err)
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	db := setupServiceTest(t, func(cfg *app.Config) {
+		cfg.EmailVerification.MaxResendsPerHour = 1
+	})
+	outbox := testutil.SetupMailer(t)
+	user := createUnverifiedUser(t, db, "alice")
+	service := NewEmailVerificationService()
+
+	if err := service.ResendVerification(user.Email); err != nil {
+		t.Fatalf("ResendVerification: %v", err)
+	}
+	token := linkToken(t, outbox.Next(t), app.GetConfig().EmailVerification.VerifyURL)
+	if _, err := service.Verify(token); err != nil {
+		t.Errorf("Verify resent token: %v", err)
+	}
+
+	if err := service.ResendVerification(user.Email); !errors.Is(err, ErrTooManyVerifyRequests) {
+		t.Errorf("second resend = %v, want ErrTooManyVerifyRequests", err)
+	}
+}
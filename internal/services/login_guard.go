@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/events"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrLoginLocked        = errors.New("too many failed login attempts")
+)
+
+// LoginGuard throttles password guessing per account and per client IP
+type LoginGuard struct {
+	store AttemptStore
+}
+
+// NewLoginGuard creates a LoginGuard backed by the configured attempt store
+func NewLoginGuard() *LoginGuard {
+	return &LoginGuard{
+		store: defaultAttemptStore(),
+	}
+}
+
+// Check returns ErrLoginLocked while either the login or the IP is locked.
+// Logins are tracked whether or not they exist so lockouts reveal nothing.
+func (g *LoginGuard) Check(login, clientIP string) error {
+	for _, key := range []string{accountKey(login), ipKey(clientIP)} {
+		remaining, err := g.store.LockedFor(key)
+		if err != nil {
+			// Fail open: a store outage should not lock everybody out
+			app.Error("Failed to read login lockout", "error", err, "key", key)
+			continue
+		}
+		if remaining > 0 {
+			return ErrLoginLocked
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt and locks the login or IP once its
+// threshold is reached, doubling the lockout with every further failure
+func (g *LoginGuard) RecordFailure(login, clientIP string) {
+	cfg := app.GetConfig().LoginProtection
+	g.fail(accountKey(login), "account", cfg.AccountThreshold, login, clientIP)
+	g.fail(ipKey(clientIP), "ip", cfg.IPThreshold, login, clientIP)
+}
+
+// RecordSuccess clears the failures of the login. IP counters are kept so a
+// valid account cannot be used to reset them.
+func (g *LoginGuard) RecordSuccess(login string) {
+	if err := g.store.Reset(accountKey(login)); err != nil {
+		app.Error("Failed to reset login failures", "error", err)
+	}
+}
+
+// fail records one failure for a key and applies a lockout if needed
+func (g *LoginGuard) fail(key, scope string, threshold int, login, clientIP string) {
+	cfg := app.GetConfig().LoginProtection
+	if threshold <= 0 {
+		return
+	}
+
+	failures, err := g.store.Fail(key, cfg.FailureWindow.Duration())
+	if err != nil {
+		app.Error("Failed to record login failure", "error", err, "key", key)
+		return
+	}
+	if failures < int64(threshold) {
+		return
+	}
+
+	duration := lockoutDuration(failures-int64(threshold), cfg.BaseLockout.Duration(), cfg.MaxLockout.Duration())
+	if err := g.store.Lock(key, duration, cfg.FailureWindow.Duration()); err != nil {
+		app.Error("Failed to lock login", "error", err, "key", key)
+		return
+	}
+
+	app.Warn("Login locked after repeated failures", "scope", scope, "login", login, "ip", clientIP, "failures", failures, "locked_for", duration.String())
+	events.Publish(events.SecurityAlert, map[string]interface{}{
+		"type":       "login_lockout",
+		"scope":      scope,
+		"login":      login,
+		"ip":         clientIP,
+		"failures":   failures,
+		"locked_for": duration.Seconds(),
+	})
+}
+
+// lockoutDuration doubles the base lockout for each failure past the threshold
+func lockoutDuration(excess int64, base, limit time.Duration) time.Duration {
+	duration := base
+	for i := int64(0); i < excess && duration < limit; i++ {
+		duration *= 2
+	}
+	if duration > limit {
+		duration = limit
+	}
+	return duration
+}
+
+// accountKey normalizes a login so case variants share one counter
+func accountKey(login string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(login))
+}
+
+// ipKey returns the counter key of a client IP
+func ipKey(clientIP string) string {
+	return "ip:" + clientIP
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/testutil"
+)
+
+// setupGuardTest configures a lockout after three failures of 30 seconds,
+// doubling up to two minutes, and returns a guard with its own store
+func setupGuardTest(t *testing.T) (*LoginGuard, *MemoryAttemptStore) {
+	t.Helper()
+	setupServiceTest(t, func(cfg *app.Config) {
+		cfg.LoginProtection.AccountThreshold = 3
+		cfg.LoginProtection.IPThreshold = 5
+		cfg.LoginProtection.BaseLockout = app.Duration(30 * time.Second)
+		cfg.LoginProtection.MaxLockout = app.Duration(2 * time.Minute)
+	})
+	store := NewMemoryAttemptStore()
+	return &LoginGuard{store: store}, store
+}
+
+// lockedFor returns how long the key is locked, rounded up to the second
+func lockedFor(t *testing.T, store AttemptStore, key string) time.Duration {
+	t.Helper()
+	remaining, err := store.LockedFor(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return remaining.Round(time.Second)
+}
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		excess int64
+		want   time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 3 * time.Minute},
+		{100, 3 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.excess, 30*time.Second, 3*time.Minute); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %v, want %v", tt.excess, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryAttemptStore(t *testing.T) {
+	store := NewMemoryAttemptStore()
+
+	for want := int64(1); want <= 3; want++ {
+		if got, _ := store.Fail("a", time.Hour); got != want {
+			t.Errorf("Fail = %d, want %d", got, want)
+		}
+	}
+	if got, _ := store.Fail("b", time.Hour); got != 1 {
+		t.Errorf("Fail on another key = %d, want 1", got)
+	}
+
+	store.Fail("short", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if got, _ := store.Fail("short", time.Millisecond); got != 1 {
+		t.Errorf("Fail after the window = %d, want 1", got)
+	}
+
+	store.Lock("a", time.Minute, time.Hour)
+	if got := lockedFor(t, store, "a"); got != time.Minute {
+		t.Errorf("LockedFor = %v, want 1m", got)
+	}
+	if got := lockedFor(t, store, "b"); got != 0 {
+		t.Errorf("LockedFor an unlocked key = %v", got)
+	}
+
+	store.Reset("a")
+	if got := lockedFor(t, store, "a"); got != 0 {
+		t.Errorf("LockedFor after Reset = %v", got)
+	}
+	if got, _ := store.Fail("a", time.Hour); got != 1 {
+		t.Errorf("Fail after Reset = %d, want 1", got)
+	}
+}
+
+func TestLoginGuardEscalatesLockout(t *testing.T) {
+	guard, store := setupGuardTest(t)
+
+	want := []time.Duration{0, 0, 30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}
+	for i, duration := range want {
+		guard.RecordFailure("Alice", "10.0.0.1")
+		if got := lockedFor(t, store, accountKey("alice")); got != duration {
+			t.Errorf("after %d failures locked for %v, want %v", i+1, got, duration)
+		}
+	}
+	if err := guard.Check(" ALICE ", "10.0.0.2"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("Check of a case variant = %v, want ErrLoginLocked", err)
+	}
+	if err := guard.Check("bob", "10.0.0.2"); err != nil {
+		t.Errorf("Check of another login = %v", err)
+	}
+}
+
+func TestLoginGuardLocksIP(t *testing.T) {
+	guard, _ := setupGuardTest(t)
+
+	for _, login := range []string{"a", "b", "c", "d", "e"} {
+		guard.RecordFailure(login, "10.0.0.1")
+	}
+	if err := guard.Check("f", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("Check from the IP = %v, want ErrLoginLocked", err)
+	}
+	if err := guard.Check("f", "10.0.0.2"); err != nil {
+		t.Errorf("Check from another IP = %v", err)
+	}
+
+	guard.RecordSuccess("f")
+	if err := guard.Check("f", "10.0.0.1"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("a success reset the IP: %v", err)
+	}
+}
+
+func TestAuthenticateUserCountsFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		password     string
+		deactivate   bool
+		unverified   bool
+		wantErr      error
+		wantFailures int64
+	}{
+		{"success", "Password1", false, false, nil, 0},
+		{"wrong password", "Password0", false, false, ErrInvalidCredentials, 3},
+		{"inactive user", "Password1", true, false, ErrInvalidCredentials, 3},
+		{"unverified address", "Password1", false, true, ErrEmailNotVerified, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard, store := setupGuardTest(t)
+			app.GetConfig().EmailVerification.Required = true
+			db := app.GetDB()
+			user := testutil.CreateUser(t, db, "alice", "Password1")
+			if tt.deactivate {
+				db.Model(user).Update("is_active", false)
+			}
+			if tt.unverified {
+				db.Model(user).Update("email_verified", false)
+			}
+			service := NewUserService()
+			service.guard = guard
+
+			guard.RecordFailure("alice", "10.0.0.1")
+			guard.RecordFailure("alice", "10.0.0.1")
+			if _, err := service.AuthenticateUser("alice", tt.password, "10.0.0.1"); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AuthenticateUser = %v, want %v", err, tt.wantErr)
+			}
+
+			// One more failure reveals the count the login left behind
+			failures, _ := store.Fail(accountKey("alice"), time.Hour)
+			if failures-1 != tt.wantFailures {
+				t.Errorf("failures = %d, want %d", failures-1, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestAuthenticateUserRefusesLockedLogin(t *testing.T) {
+	guard, _ := setupGuardTest(t)
+	testutil.CreateUser(t, app.GetDB(), "alice", "Password1")
+	service := NewUserService()
+	service.guard = guard
+
+	for i := 0; i < 3; i++ {
+		service.AuthenticateUser("alice", "Password0", "10.0.0.1")
+	}
+	if _, err := service.AuthenticateUser("alice", "Password1", "10.0.0.2"); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("correct password while locked = %v, want ErrLoginLocked", err)
+	}
+}
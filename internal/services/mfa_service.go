@@ -0,0 +1,273 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/events"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrMFANotEnrolled      = errors.New("two-factor enrollment has not been started")
+	ErrInvalidMFACode      = errors.New("invalid authentication code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired login challenge")
+	ErrTooManyMFAAttempts  = errors.New("too many authentication code attempts")
+)
+
+// totpSkew is the number of 30 second steps of clock drift tolerated either way
+const totpSkew = 1
+
+// MFAEnrollment is a freshly generated TOTP secret awaiting confirmation
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// MFAService handles TOTP enrollment, recovery codes and the second login step
+type MFAService struct {
+	userRepo     repositories.UserRepository
+	recoveryRepo repositories.MFARecoveryCodeRepository
+	limiter      *windowLimiter
+}
+
+// NewMFAService creates a new MFAService
+func NewMFAService() *MFAService {
+	return &MFAService{
+		userRepo:     repositories.NewUserRepository(),
+		recoveryRepo: repositories.NewMFARecoveryCodeRepository(),
+		limiter:      newWindowLimiter(func() int { return app.GetConfig().MFA.MaxAttempts }, app.GetConfig().MFA.ChallengeTTL.Duration()),
+	}
+}
+
+// BeginEnrollment generates a new TOTP secret for the user. MFA stays off
+// until the secret is confirmed with a valid code.
+func (s *MFAService) BeginEnrollment(userID int64) (*MFAEnrollment, error) {
+	user, err := s.userRepo.Find(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFASecret = secret
+	user.MFALastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(app.GetConfig().MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables MFA once the user proves their authenticator works.
+// It returns the recovery codes, which are only ever shown this once.
+func (s *MFAService) ConfirmEnrollment(userID int64, code string) ([]string, error) {
+	user, err := s.userRepo.Find(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return nil, ErrMFANotEnrolled
+	}
+
+	limiterKey := strconv.FormatInt(user.ID, 10)
+	if !s.limiter.Allow(limiterKey) {
+		return nil, ErrTooManyMFAAttempts
+	}
+	step, ok := utils.ValidateTOTP(user.MFASecret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	s.limiter.Reset(limiterKey)
+
+	codes, err := s.issueRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.MFAEnabled = true
+	user.MFALastStep = step
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	events.Publish(events.UserUpdated, map[string]interface{}{
+		"user_id":     user.ID,
+		"mfa_enabled": true,
+	})
+	app.Info("Two-factor authentication enabled", "user_id", user.ID)
+	return codes, nil
+}
+
+// Disable turns MFA off after the user re-authenticates with their password
+// and a current TOTP or recovery code
+func (s *MFAService) Disable(userID int64, password, code string) error {
+	user, err := s.userRepo.Find(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	if err := s.verifyCode(user, code); err != nil {
+		return err
+	}
+
+	user.MFAEnabled = false
+	user.MFASecret = ""
+	user.MFALastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+	if err := s.recoveryRepo.DeleteByUser(user.ID); err != nil {
+		app.Error("Failed to delete recovery codes", "error", err, "user_id", user.ID)
+	}
+
+	events.Publish(events.UserUpdated, map[string]interface{}{
+		"user_id":     user.ID,
+		"mfa_enabled": false,
+	})
+	app.Info("Two-factor authentication disabled", "user_id", user.ID)
+	return nil
+}
+
+// Challenge issues the token that lets a user who passed the password step
+// complete login with a second factor
+func (s *MFAService) Challenge(user *models.User) (string, time.Time, error) {
+	ttl := app.GetConfig().MFA.ChallengeTTL.Duration()
+	return app.JWT.GenerateChallengeToken(user.ID, ttl)
+}
+
+// VerifyChallenge completes an MFA login with a TOTP or recovery code
+func (s *MFAService) VerifyChallenge(challengeToken, code string) (*models.User, error) {
+	claims, appErr := app.JWT.ParseChallengeToken(challengeToken)
+	if appErr != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	user, err := s.userRepo.Find(claims.UserID)
+	if err != nil || !user.IsActive || !user.MFAEnabled {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	if err := s.verifyCode(user, code); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// verifyCode accepts either a TOTP code or an unused recovery code.
+// Failed attempts are rate limited per user so codes cannot be guessed.
+func (s *MFAService) verifyCode(user *models.User, code string) error {
+	limiterKey := strconv.FormatInt(user.ID, 10)
+	if !s.limiter.Allow(limiterKey) {
+		return ErrTooManyMFAAttempts
+	}
+	if err := s.checkCode(user, code); err != nil {
+		return err
+	}
+
+	s.limiter.Reset(limiterKey)
+	return nil
+}
+
+// checkCode matches the code against the TOTP secret, then the recovery codes
+func (s *MFAService) checkCode(user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := utils.ValidateTOTP(user.MFASecret, code, time.Now(), totpSkew); ok {
+		// Each code is good for one login only
+		claimed, err := s.userRepo.ClaimMFAStep(user.ID, step)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrInvalidMFACode
+		}
+		user.MFALastStep = step
+		return nil
+	}
+
+	stored, err := s.recoveryRepo.FindUnused(user.ID, utils.SHA256(normalizeRecoveryCode(code)))
+	if err != nil {
+		return ErrInvalidMFACode
+	}
+	claimed, err := s.recoveryRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrInvalidMFACode
+	}
+
+	app.Info("Recovery code used", "user_id", user.ID)
+	return nil
+}
+
+// issueRecoveryCodes replaces the user's recovery codes with a fresh set
+func (s *MFAService) issueRecoveryCodes(userID int64) ([]string, error) {
+	count := app.GetConfig().MFA.RecoveryCodes
+	codes := make([]string, count)
+	records := make([]*models.MFARecoveryCode, count)
+
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = &models.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.SHA256(normalizeRecoveryCode(code)),
+		}
+	}
+
+	if err := s.recoveryRepo.Replace(userID, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating recovery code: %w", err)
+	}
+	code := hex.EncodeToString(buf)
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode ignores case and separators the user may have typed
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
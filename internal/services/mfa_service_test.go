@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+	"goapp/utils"
+
+	"gorm.io/gorm"
+)
+
+// totpCode returns the code of secret offset steps from now
+func totpCode(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now())+offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enrollMFA turns MFA on for user and returns the secret and recovery codes
+func enrollMFA(t *testing.T, service *MFAService, user *models.User) (string, []string) {
+	t.Helper()
+	enrollment, err := service.BeginEnrollment(user.ID)
+	if err != nil {
+		t.Fatalf("BeginEnrollment: %v", err)
+	}
+	codes, err := service.ConfirmEnrollment(user.ID, totpCode(t, enrollment.Secret, 0))
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+// challenge starts the second login step for user
+func challenge(t *testing.T, service *MFAService, user *models.User) string {
+	t.Helper()
+	token, _, err := service.Challenge(user)
+	if err != nil {
+		t.Fatalf("Challenge: %v", err)
+	}
+	return token
+}
+
+func TestConfirmEnrollment(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewMFAService()
+
+	if _, err := service.ConfirmEnrollment(user.ID, "123456"); !errors.Is(err, ErrMFANotEnrolled) {
+		t.Errorf("confirm before enrolling = %v, want ErrMFANotEnrolled", err)
+	}
+
+	enrollment, err := service.BeginEnrollment(user.ID)
+	if err != nil {
+		t.Fatalf("BeginEnrollment: %v", err)
+	}
+	if !strings.Contains(enrollment.URI, "secret="+enrollment.Secret) {
+		t.Errorf("URI %q does not carry the secret", enrollment.URI)
+	}
+	if _, err := service.ConfirmEnrollment(user.ID, totpCode(t, enrollment.Secret, 5)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("confirm with a stale code = %v, want ErrInvalidMFACode", err)
+	}
+
+	codes, err := service.ConfirmEnrollment(user.ID, totpCode(t, enrollment.Secret, 0))
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment: %v", err)
+	}
+	if len(codes) != 10 {
+		t.Errorf("got %d recovery codes, want 10", len(codes))
+	}
+	if _, err := service.BeginEnrollment(user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Errorf("enrolling again = %v, want ErrMFAAlreadyEnabled", err)
+	}
+}
+
+func TestVerifyChallengeRejectsReplayedCodes(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewMFAService()
+	secret, _ := enrollMFA(t, service, user)
+
+	// The code used to confirm enrollment is spent
+	if _, err := service.VerifyChallenge(challenge(t, service, user), totpCode(t, secret, 0)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("enrollment code = %v, want ErrInvalidMFACode", err)
+	}
+
+	next := totpCode(t, secret, 1)
+	verified, err := service.VerifyChallenge(challenge(t, service, user), next)
+	if err != nil || verified.ID != user.ID {
+		t.Fatalf("VerifyChallenge = %v, %v", verified, err)
+	}
+	if _, err := service.VerifyChallenge(challenge(t, service, user), next); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("replayed code = %v, want ErrInvalidMFACode", err)
+	}
+}
+
+func TestVerifyChallengeAcceptsEachRecoveryCodeOnce(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewMFAService()
+	_, codes := enrollMFA(t, service, user)
+
+	typed := " " + strings.ToUpper(strings.ReplaceAll(codes[0], "-", " ")) + " "
+	if _, err := service.VerifyChallenge(challenge(t, service, user), typed); err != nil {
+		t.Fatalf("recovery code as typed %q: %v", typed, err)
+	}
+	if _, err := service.VerifyChallenge(challenge(t, service, user), codes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("used recovery code = %v, want ErrInvalidMFACode", err)
+	}
+	if _, err := service.VerifyChallenge(challenge(t, service, user), codes[1]); err != nil {
+		t.Errorf("another recovery code: %v", err)
+	}
+}
+
+func TestVerifyChallengeRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, db *gorm.DB, service *MFAService, user *models.User) string
+	}{
+		{"invalid challenge", func(t *testing.T, db *gorm.DB, service *MFAService, user *models.User) string {
+			return "not-a-token"
+		}},
+		{"inactive user", func(t *testing.T, db *gorm.DB, service *MFAService, user *models.User) string {
+			db.Model(user).Update("is_active", false)
+			return challenge(t, service, user)
+		}},
+		{"mfa disabled", func(t *testing.T, db *gorm.DB, service *MFAService, user *models.User) string {
+			db.Model(user).Update("mfa_enabled", false)
+			return challenge(t, service, user)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTest(t, nil)
+			user := testutil.CreateUser(t, db, "alice", "Password1")
+			service := NewMFAService()
+			secret, _ := enrollMFA(t, service, user)
+
+			token := tt.prepare(t, db, service, user)
+			if _, err := service.VerifyChallenge(token, totpCode(t, secret, 1)); !errors.Is(err, ErrInvalidMFAChallenge) {
+				t.Errorf("VerifyChallenge = %v, want ErrInvalidMFAChallenge", err)
+			}
+		})
+	}
+}
+
+func TestVerifyChallengeLimitsAttempts(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewMFAService()
+	secret, _ := enrollMFA(t, service, user)
+	token := challenge(t, service, user)
+
+	for i := 0; i < 5; i++ {
+		if _, err := service.VerifyChallenge(token, "000000"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("attempt %d = %v, want ErrInvalidMFACode", i+1, err)
+		}
+	}
+	if _, err := service.VerifyChallenge(token, totpCode(t, secret, 1)); !errors.Is(err, ErrTooManyMFAAttempts) {
+		t.Errorf("valid code after the limit = %v, want ErrTooManyMFAAttempts", err)
+	}
+}
+
+func TestDisableMFA(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewMFAService()
+	secret, codes := enrollMFA(t, service, user)
+
+	if err := service.Disable(user.ID, "Password0", totpCode(t, secret, 1)); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("wrong password = %v, want ErrIncorrectPassword", err)
+	}
+	if err := service.Disable(user.ID, "Password1", codes[0]); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+
+	var remaining int64
+	db.Model(&models.MFARecoveryCode{}).Where("user_id = ?", user.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("%d recovery codes survived Disable", remaining)
+	}
+	if _, err := service.VerifyChallenge(challenge(t, service, user), codes[1]); !errors.Is(err, ErrInvalidMFAChallenge) {
+		t.Errorf("challenge after Disable = %v, want ErrInvalidMFAChallenge", err)
+	}
+}
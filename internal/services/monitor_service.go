@@ -148,6 +148,11 @@ func (s *MonitorService) reportMetrics() {
 		var m runtime.MemStats
 		runtime.ReadMemStats(&m)
 
+		var cacheStats app.CacheStats
+		if stats := app.CacheStatus(); stats != nil {
+			cacheStats = *stats
+		}
+
 		// Log metrics
 		app.Info("System metrics",
 			"uptime", uptime,
@@ -155,6 +160,9 @@ func (s *MonitorService) reportMetrics() {
 			"memory_used_mb", m.Alloc/1024/1024,
 			"total_requests", requestCount,
 			"error_count", errorCount,
+			"cache_hits", cacheStats.Hits,
+			"cache_misses", cacheStats.Misses,
+			"cache_evictions", cacheStats.Evictions,
 		)
 
 		// Publish metrics event
@@ -164,6 +172,7 @@ func (s *MonitorService) reportMetrics() {
 			"memory_used_mb": m.Alloc / 1024 / 1024,
 			"total_requests": requestCount,
 			"error_rate":     float64(errorCount) / float64(requestCount+1) * 100,
+			"cache":          cacheStats,
 			"timestamp":      time.Now(),
 		})
 	}
@@ -202,6 +211,8 @@ func (s *MonitorService) GetStats() map[string]interface{} {
 		"routes":             routes,
 		"error_distribution": errorDist,
 		"goroutines":         runtime.NumGoroutine(),
+		"db_replicas":        app.ReplicaStatus(),
+		"cache":              app.CacheStatus(),
 		"timestamp":          time.Now(),
 	}
 }
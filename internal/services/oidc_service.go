@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+	"goapp/internal/oidc"
+	"goapp/internal/repositories"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUnknownOIDCProvider = errors.New("unknown identity provider")
+	ErrInvalidOIDCState    = errors.New("invalid or expired login state")
+	ErrOIDCLoginFailed     = errors.New("identity provider login failed")
+	ErrOIDCNoAccount       = errors.New("no account is linked to this identity")
+	ErrOIDCEmailUnverified = errors.New("identity provider did not verify the email address")
+	ErrOIDCAccountDisabled = errors.New("account is disabled")
+)
+
+// usernameUnsafeChars matches characters not allowed in generated usernames
+var usernameUnsafeChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// oidcStateKey is the cache key of a pending login, by state
+const oidcStateKey = "oidc:state:%s"
+
+// oidcPendingLogin is a login waiting for the provider to redirect back
+type oidcPendingLogin struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+// OIDCService signs users in through external OpenID Connect providers.
+// Pending logins are kept in the application cache, so with a Redis
+// backed cache the callback may reach any instance.
+type OIDCService struct {
+	providers    map[string]*oidc.Provider
+	userRepo     repositories.UserRepository
+	identityRepo repositories.UserIdentityRepository
+	pending      app.Cache
+}
+
+// NewOIDCService creates an OIDCService for the configured providers
+func NewOIDCService() *OIDCService {
+	providers := make(map[string]*oidc.Provider)
+	for _, cfg := range app.GetConfig().OIDC.Providers {
+		providers[cfg.Name] = oidc.NewProvider(cfg)
+	}
+
+	pending := app.AppCache
+	if pending == nil {
+		pending = app.NewMemoryCache(app.GetConfig().Cache)
+	}
+
+	return &OIDCService{
+		providers:    providers,
+		userRepo:     repositories.NewUserRepository(),
+		identityRepo: repositories.NewUserIdentityRepository(),
+		pending:      pending,
+	}
+}
+
+// BeginLogin returns the provider URL to send the user to. The state, nonce
+// and PKCE verifier are kept server-side until the callback.
+func (s *OIDCService) BeginLogin(ctx context.Context, providerName string) (string, error) {
+	provider, exists := s.providers[providerName]
+	if !exists {
+		return "", ErrUnknownOIDCProvider
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(oidcPendingLogin{Provider: providerName, Verifier: verifier, Nonce: nonce})
+	if err != nil {
+		return "", err
+	}
+	if err := s.pending.Set(fmt.Sprintf(oidcStateKey, state), data, app.GetConfig().OIDC.StateTTL.Duration()); err != nil {
+		return "", fmt.Errorf("error storing login state: %w", err)
+	}
+	return authURL, nil
+}
+
+// CompleteLogin handles the provider's redirect, verifies the ID token and
+// returns the linked local user
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, state, code string) (*models.User, error) {
+	provider, exists := s.providers[providerName]
+	if !exists {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	login, err := s.takePendingLogin(state)
+	if err != nil || login.Provider != providerName {
+		return nil, ErrInvalidOIDCState
+	}
+
+	tokens, err := provider.Exchange(ctx, code, login.Verifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, login.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	return s.linkIdentity(provider, claims)
+}
+
+// takePendingLogin returns and forgets the pending login of a state. States
+// are single use; a callback replayed before the delete lands still fails
+// at the provider, which accepts each code once.
+func (s *OIDCService) takePendingLogin(state string) (*oidcPendingLogin, error) {
+	if state == "" {
+		return nil, ErrInvalidOIDCState
+	}
+	key := fmt.Sprintf(oidcStateKey, state)
+	data, err := s.pending.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.pending.Delete(key); err != nil {
+		return nil, err
+	}
+
+	var login oidcPendingLogin
+	if err := json.Unmarshal(data, &login); err != nil {
+		return nil, err
+	}
+	return &login, nil
+}
+
+// linkIdentity resolves the local user for an external identity. Known
+// identities map to their user; otherwise a verified email links to an
+// existing account or, if the provider allows it, a new one.
+func (s *OIDCService) linkIdentity(provider *oidc.Provider, claims *oidc.IDTokenClaims) (*models.User, error) {
+	now := time.Now()
+
+	identity, err := s.identityRepo.FindByProviderSubject(provider.Name(), claims.Subject)
+	if err == nil {
+		user, err := s.userRepo.Find(identity.UserID)
+		if err != nil {
+			return nil, ErrOIDCNoAccount
+		}
+		if !user.IsActive {
+			return nil, ErrOIDCAccountDisabled
+		}
+		if err := s.identityRepo.TouchLastLogin(identity.ID, now); err != nil {
+			app.Error("Failed to update identity", "error", err, "identity_id", identity.ID)
+		}
+		return user, nil
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailUnverified
+	}
+
+	user, err := s.userRepo.FindByEmail(claims.Email)
+	if err != nil {
+		if !provider.AllowSignup() {
+			return nil, ErrOIDCNoAccount
+		}
+		if user, err = s.createUser(claims); err != nil {
+			return nil, err
+		}
+	} else if !user.IsActive {
+		return nil, ErrOIDCAccountDisabled
+	}
+
+	if err := s.identityRepo.Create(&models.UserIdentity{
+		UserID:      user.ID,
+		Provider:    provider.Name(),
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	app.Info("External identity linked", "user_id", user.ID, "provider", provider.Name())
+	return user, nil
+}
+
+// createUser registers a new account for an external identity. The account
+// gets an unusable random password; a password can be set through reset.
+func (s *OIDCService) createUser(claims *oidc.IDTokenClaims) (*models.User, error) {
+	randomPassword, err := generateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	username, err := s.availableUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName, _ := strings.Cut(strings.TrimSpace(claims.Name), " ")
+	now := time.Now()
+	user := &models.User{
+		Username:      username,
+		Email:         claims.Email,
+		Password:      string(hashedPassword),
+		FirstName:     firstName,
+		LastName:      lastName,
+		IsActive:      true,
+		EmailVerified: true,
+		VerifiedAt:    &now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername derives a free username from the identity's claims
+func (s *OIDCService) availableUsername(claims *oidc.IDTokenClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameUnsafeChars.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		if _, err := s.userRepo.FindByUsername(candidate); err != nil {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", fmt.Errorf("no username available for %s", base)
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+	"goapp/internal/oidc/oidctest"
+	"goapp/internal/testutil"
+
+	"gorm.io/gorm"
+)
+
+// oidcTest runs logins against a test provider served with httptest
+type oidcTest struct {
+	t        *testing.T
+	db       *gorm.DB
+	provider *oidctest.Provider
+	service  *OIDCService
+}
+
+// newOIDCTest configures the "local" provider, and an "other" provider at
+// the same address, before the service is created
+func newOIDCTest(t *testing.T, allowSignup bool, configure func(cfg *app.Config)) *oidcTest {
+	t.Helper()
+	provider, err := oidctest.NewProvider("goapp", "goapp-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(provider)
+	t.Cleanup(server.Close)
+
+	cfg := testutil.Config()
+	for _, name := range []string{"local", "other"} {
+		cfg.OIDC.Providers = append(cfg.OIDC.Providers, app.OIDCProviderConfig{
+			Name:         name,
+			Issuer:       server.URL,
+			ClientID:     "goapp",
+			ClientSecret: "goapp-secret",
+			RedirectURL:  "http://app.test/api/v1/users/oidc/" + name + "/callback",
+			AllowSignup:  allowSignup,
+		})
+	}
+	if configure != nil {
+		configure(&cfg)
+	}
+	db := testutil.SetupDB(t, cfg)
+
+	return &oidcTest{t: t, db: db, provider: provider, service: NewOIDCService()}
+}
+
+// begin starts a login and returns its state and the provider URL
+func (o *oidcTest) begin(service *OIDCService) (string, string) {
+	o.t.Helper()
+	authURL, err := service.BeginLogin(context.Background(), "local")
+	if err != nil {
+		o.t.Fatalf("BeginLogin: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	return parsed.Query().Get("state"), authURL
+}
+
+// authorize visits the provider URL and returns the code of the redirect
+func (o *oidcTest) authorize(authURL string) string {
+	o.t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.StatusCode != http.StatusFound {
+		o.t.Fatalf("authorize returned %d to %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	return location.Query().Get("code")
+}
+
+// login runs a whole login through the service
+func (o *oidcTest) login() (*models.User, error) {
+	o.t.Helper()
+	state, authURL := o.begin(o.service)
+	return o.service.CompleteLogin(context.Background(), "local", state, o.authorize(authURL))
+}
+
+// countUsers returns the number of user rows
+func (o *oidcTest) countUsers() int64 {
+	var count int64
+	o.db.Model(&models.User{}).Count(&count)
+	return count
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+
+	user, err := o.login()
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if user.Email != "sso.user@example.com" || user.Username != "sso.user" || !user.EmailVerified || !user.IsActive {
+		t.Errorf("created user = %+v", user)
+	}
+
+	// The second login finds the linked identity
+	again, err := o.login()
+	if err != nil {
+		t.Fatalf("second login: %v", err)
+	}
+	if again.ID != user.ID || o.countUsers() != 1 {
+		t.Errorf("second login returned user %d with %d users, want user %d alone", again.ID, o.countUsers(), user.ID)
+	}
+}
+
+func TestOIDCLoginLinksExistingUser(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	existing := &models.User{Username: "existing", Email: "sso.user@example.com", Password: "hash", IsActive: true}
+	if err := o.service.userRepo.Create(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := o.login()
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if user.ID != existing.ID || o.countUsers() != 1 {
+		t.Errorf("login returned user %d with %d users, want the existing user %d", user.ID, o.countUsers(), existing.ID)
+	}
+
+	identity, err := o.service.identityRepo.FindByProviderSubject("local", "test-user-1")
+	if err != nil || identity.UserID != existing.ID {
+		t.Errorf("identity = %+v, %v", identity, err)
+	}
+}
+
+func TestOIDCLoginWithoutSignup(t *testing.T) {
+	o := newOIDCTest(t, false, nil)
+
+	if _, err := o.login(); !errors.Is(err, ErrOIDCNoAccount) {
+		t.Fatalf("login = %v, want ErrOIDCNoAccount", err)
+	}
+	if o.countUsers() != 0 {
+		t.Error("a user was created although signup is off")
+	}
+}
+
+func TestOIDCLoginRejectsUnverifiedEmail(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	o.provider.User.EmailVerified = false
+
+	if _, err := o.login(); !errors.Is(err, ErrOIDCEmailUnverified) {
+		t.Fatalf("login = %v, want ErrOIDCEmailUnverified", err)
+	}
+}
+
+func TestOIDCLoginRejectsDisabledAccount(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	existing := &models.User{Username: "existing", Email: "sso.user@example.com", Password: "hash", IsActive: true}
+	if err := o.service.userRepo.Create(existing); err != nil {
+		t.Fatal(err)
+	}
+	o.db.Model(existing).Update("is_active", false)
+
+	if _, err := o.login(); !errors.Is(err, ErrOIDCAccountDisabled) {
+		t.Fatalf("login = %v, want ErrOIDCAccountDisabled", err)
+	}
+}
+
+func TestOIDCCallbackRejectsUnknownState(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	_, authURL := o.begin(o.service)
+
+	_, err := o.service.CompleteLogin(context.Background(), "local", "forged-state", o.authorize(authURL))
+	if !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("CompleteLogin = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackStateIsSingleUse(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	state, authURL := o.begin(o.service)
+
+	if _, err := o.service.CompleteLogin(context.Background(), "local", state, o.authorize(authURL)); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	_, err := o.service.CompleteLogin(context.Background(), "local", state, o.authorize(authURL))
+	if !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("replayed CompleteLogin = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackRejectsStateOfOtherProvider(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	state, authURL := o.begin(o.service)
+
+	_, err := o.service.CompleteLogin(context.Background(), "other", state, o.authorize(authURL))
+	if !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("CompleteLogin = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackRejectsExpiredState(t *testing.T) {
+	o := newOIDCTest(t, true, func(cfg *app.Config) {
+		cfg.OIDC.StateTTL = app.Duration(50 * time.Millisecond)
+	})
+	state, authURL := o.begin(o.service)
+	code := o.authorize(authURL)
+	time.Sleep(100 * time.Millisecond)
+
+	_, err := o.service.CompleteLogin(context.Background(), "local", state, code)
+	if !errors.Is(err, ErrInvalidOIDCState) {
+		t.Fatalf("CompleteLogin = %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCCallbackRejectsVerifierMismatch(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	_, firstURL := o.begin(o.service)
+	secondState, _ := o.begin(o.service)
+
+	// The code was issued for the first login's challenge, but the second
+	// login's verifier is sent with it
+	_, err := o.service.CompleteLogin(context.Background(), "local", secondState, o.authorize(firstURL))
+	if !errors.Is(err, ErrOIDCLoginFailed) {
+		t.Fatalf("CompleteLogin = %v, want ErrOIDCLoginFailed", err)
+	}
+}
+
+func TestOIDCCallbackRejectsNonceMismatch(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	o.provider.Nonce = "replayed-nonce"
+
+	if _, err := o.login(); !errors.Is(err, ErrOIDCLoginFailed) {
+		t.Fatalf("login = %v, want ErrOIDCLoginFailed", err)
+	}
+	if o.countUsers() != 0 {
+		t.Error("a user was created from a token with the wrong nonce")
+	}
+}
+
+func TestOIDCCallbackOnAnotherInstance(t *testing.T) {
+	o := newOIDCTest(t, true, nil)
+	app.AppCache = app.NewMemoryCache(app.GetConfig().Cache)
+	t.Cleanup(func() { app.AppCache = nil })
+
+	// Two instances sharing the application cache, as with Redis
+	first, second := NewOIDCService(), NewOIDCService()
+	state, authURL := o.begin(first)
+
+	if _, err := second.CompleteLogin(context.Background(), "local", state, o.authorize(authURL)); err != nil {
+		t.Fatalf("CompleteLogin on the second instance: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/utils"
+)
+
+var (
+	ErrInvalidResetToken    = errors.New("invalid or expired password reset token")
+	ErrTooManyResetRequests = errors.New("too many password reset requests")
+)
+
+// PasswordResetService handles the forgot-password and reset-password flow
+type PasswordResetService struct {
+	userService *UserService
+	userRepo    repositories.UserRepository
+	resetRepo   repositories.PasswordResetRepository
+	limiter     *windowLimiter
+}
+
+// NewPasswordResetService creates a new PasswordResetService
+func NewPasswordResetService() *PasswordResetService {
+	return &PasswordResetService{
+		userService: NewUserService(),
+		userRepo:    repositories.NewUserRepository(),
+		resetRepo:   repositories.NewPasswordResetRepository(),
+		limiter:     newWindowLimiter(func() int { return app.GetConfig().PasswordReset.MaxRequestsPerHour }, time.Hour),
+	}
+}
+
+// RequestReset sends a reset token to the address if it belongs to an active user.
+// The result is the same whether or not the address is known.
+func (s *PasswordResetService) RequestReset(email string) error {
+	email = strings.TrimSpace(email)
+	if !s.limiter.Allow(strings.ToLower(email)) {
+		return ErrTooManyResetRequests
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil || !user.IsActive {
+		app.Info("Password reset requested for unknown address")
+		return nil
+	}
+
+	// Issue and deliver in the background so response time does not reveal
+	// whether the address exists
+	go func() {
+		if err := s.sendReset(user); err != nil {
+			app.Error("Failed to send password reset notification", "error", err, "user_id", user.ID)
+		}
+	}()
+
+	app.Info("Password reset requested", "user_id", user.ID)
+	return nil
+}
+
+// sendReset issues a new reset token and mails it to the user
+func (s *PasswordResetService) sendReset(user *models.User) error {
+	// Only the most recently issued token stays valid
+	if err := s.resetRepo.InvalidateByUser(user.ID); err != nil {
+		return err
+	}
+
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := app.GetConfig().PasswordReset.TokenTTL.Duration()
+	if err := s.resetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.SHA256(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return err
+	}
+
+	notification := &app.Notification{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to reset your password. It expires in %s.\n\n%s%s\n\nIf you did not request a reset, you can ignore this message.\n",
+			user.Username, ttl, app.GetConfig().PasswordReset.ResetURL, token),
+	}
+	if err := app.Mailer.Send(notification); err != nil {
+		return fmt.Errorf("error sending password reset email: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword consumes a reset token and sets the user's new password
+func (s *PasswordResetService) ResetPassword(token, newPassword string) error {
+	stored, err := s.resetRepo.FindByHash(utils.SHA256(token))
+	if err != nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.Find(stored.UserID)
+	if err != nil || !user.IsActive {
+		return ErrInvalidResetToken
+	}
+
+	// Validate before consuming the token so a rejected password can be retried
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+	if err := s.userService.checkPasswordReuse(user, newPassword); err != nil {
+		return err
+	}
+
+	claimed, err := s.resetRepo.MarkUsed(stored.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.userService.setPassword(user, newPassword); err != nil {
+		return err
+	}
+
+	app.Info("Password reset completed", "user_id", user.ID)
+	return nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/models"
+	"goapp/internal/testutil"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// linkToken returns the token appended to url in the notification body
+func linkToken(t *testing.T, notification *app.Notification, url string) string {
+	t.Helper()
+	_, after, found := strings.Cut(notification.Body, url)
+	if !found {
+		t.Fatalf("notification has no %s link:\n%s", url, notification.Body)
+	}
+	return strings.Fields(after)[0]
+}
+
+// requestResetToken requests a reset for user and returns the mailed token
+func requestResetToken(t *testing.T, service *PasswordResetService, outbox *testutil.Outbox, user *models.User) string {
+	t.Helper()
+	if err := service.RequestReset(user.Email); err != nil {
+		t.Fatalf("RequestReset: %v", err)
+	}
+	notification := outbox.Next(t)
+	if notification.To != user.Email {
+		t.Fatalf("reset mailed to %s, want %s", notification.To, user.Email)
+	}
+	return linkToken(t, notification, app.GetConfig().PasswordReset.ResetURL)
+}
+
+// passwordIs reports whether the stored hash of user matches password
+func passwordIs(t *testing.T, db *gorm.DB, user *models.User, password string) bool {
+	t.Helper()
+	var stored models.User
+	if err := db.First(&stored, user.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte(password)) == nil
+}
+
+func TestResetPasswordTokenIsSingleUse(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	outbox := testutil.SetupMailer(t)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewPasswordResetService()
+
+	token := requestResetToken(t, service, outbox, user)
+	if err := service.ResetPassword(token, "Password2"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if !passwordIs(t, db, user, "Password2") {
+		t.Error("password was not changed")
+	}
+
+	if err := service.ResetPassword(token, "Password3"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("second use = %v, want ErrInvalidResetToken", err)
+	}
+	if !passwordIs(t, db, user, "Password2") {
+		t.Error("second use changed the password")
+	}
+}
+
+func TestResetPasswordRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, db *gorm.DB, user *models.User, token string) string
+	}{
+		{"unknown token", func(t *testing.T, db *gorm.DB, user *models.User, token string) string {
+			return "unknown"
+		}},
+		{"expired token", func(t *testing.T, db *gorm.DB, user *models.User, token string) string {
+			db.Model(&models.PasswordResetToken{}).Where("user_id = ?", user.ID).
+				Update("expires_at", time.Now().Add(-time.Minute))
+			return token
+		}},
+		{"inactive user", func(t *testing.T, db *gorm.DB, user *models.User, token string) string {
+			db.Model(user).Update("is_active", false)
+			return token
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTest(t, nil)
+			outbox := testutil.SetupMailer(t)
+			user := testutil.CreateUser(t, db, "alice", "Password1")
+			service := NewPasswordResetService()
+
+			token := tt.prepare(t, db, user, requestResetToken(t, service, outbox, user))
+			if err := service.ResetPassword(token, "Password2"); !errors.Is(err, ErrInvalidResetToken) {
+				t.Errorf("ResetPassword = %v, want ErrInvalidResetToken", err)
+			}
+			if !passwordIs(t, db, user, "Password1") {
+				t.Error("password was changed")
+			}
+		})
+	}
+}
+
+func TestRequestResetSupersedesEarlierTokens(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	outbox := testutil.SetupMailer(t)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewPasswordResetService()
+
+	first := requestResetToken(t, service, outbox, user)
+	second := requestResetToken(t, service, outbox, user)
+	if err := service.ResetPassword(first, "Password2"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Errorf("superseded token = %v, want ErrInvalidResetToken", err)
+	}
+	if err := service.ResetPassword(second, "Password2"); err != nil {
+		t.Errorf("latest token: %v", err)
+	}
+}
+
+func TestResetPasswordKeepsTokenForRejectedPassword(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	outbox := testutil.SetupMailer(t)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewPasswordResetService()
+
+	token := requestResetToken(t, service, outbox, user)
+	if err := service.ResetPassword(token, "weak"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("weak password = %v, want ErrWeakPassword", err)
+	}
+	if err := service.ResetPassword(token, "Password1"); !errors.Is(err, ErrPasswordReused) {
+		t.Errorf("current password = %v, want ErrPasswordReused", err)
+	}
+	if err := service.ResetPassword(token, "Password2"); err != nil {
+		t.Errorf("ResetPassword after rejected attempts: %v", err)
+	}
+}
+
+func TestRequestResetLimitsRequests(t *testing.T) {
+	db := setupServiceTest(t, func(cfg *app.Config) {
+		cfg.PasswordReset.MaxRequestsPerHour = 2
+	})
+	outbox := testutil.SetupMailer(t)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	service := NewPasswordResetService()
+	// Wait for the mails to the known address before the test tears down
+	defer func() {
+		outbox.Next(t)
+		outbox.Next(t)
+	}()
+
+	for _, email := range []string{user.Email, "nobody@example.com"} {
+		for i := 0; i < 2; i++ {
+			if err := service.RequestReset(email); err != nil {
+				t.Fatalf("request %d for %s: %v", i+1, email, err)
+			}
+		}
+		if err := service.RequestReset(strings.ToUpper(email)); !errors.Is(err, ErrTooManyResetRequests) {
+			t.Errorf("third request for %s = %v, want ErrTooManyResetRequests", email, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"goapp/internal/app"
+	"goapp/internal/events"
+)
+
+const (
+	productItemKey        = "products:item:%d"
+	productListPrefix     = "products:list:"
+	productListKey        = "products:list:%d:%d"
+	productCategoryPrefix = "products:category:"
+	productCategoryKey    = "products:category:%d"
+)
+
+// productEvents are the events after which cached products are invalidated
+var productEvents = []events.EventType{
+	events.ProductCreated,
+	events.ProductUpdated,
+	events.ProductDeleted,
+	events.StockUpdated,
+}
+
+// productCache keeps products and product lists in app.AppCache. ProductService
+// invalidates the entries a write affects before returning, and product
+// events invalidate them for writes made elsewhere; concurrent misses for a
+// key share one database load.
+type productCache struct {
+	flights app.SingleFlight
+
+	// generation is bumped by every invalidation, under the write lock, so
+	// a load that raced with an invalidation does not store what it read
+	mutex      sync.RWMutex
+	generation uint64
+}
+
+var (
+	sharedProductCache     *productCache
+	sharedProductCacheOnce sync.Once
+)
+
+// defaultProductCache returns the product cache. It is shared so a single
+// subscription invalidates the entries of every ProductService.
+func defaultProductCache() *productCache {
+	sharedProductCacheOnce.Do(func() {
+		sharedProductCache = &productCache{}
+		events.SubscribeMany(productEvents, sharedProductCache.invalidate)
+	})
+	return sharedProductCache
+}
+
+// cachedLoad returns the value cached under key, or runs load and caches its
+// result for a jittered cache.default_ttl. Without a cache it just loads, as
+// do requests that read their own writes, since an entry loaded after the
+// write may have come from a replica that has not caught up.
+func cachedLoad[T any](ctx context.Context, c *productCache, key string, load func() (T, error)) (T, error) {
+	cache := app.AppCache
+	if cache == nil || app.PrimaryReads(ctx) {
+		return load()
+	}
+
+	var value T
+	if data, err := cache.Get(key); err == nil {
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	data, err := c.flights.Do(key, func() ([]byte, error) {
+		c.mutex.RLock()
+		generation := c.generation
+		c.mutex.RUnlock()
+
+		loaded, err := load()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mutex.RLock()
+		defer c.mutex.RUnlock()
+		if c.generation == generation {
+			cfg := app.GetConfig().Cache
+			cache.Set(key, data, app.JitterTTL(cfg.DefaultTTL.Duration(), cfg.TTLJitter))
+		}
+		return data, nil
+	})
+	if err != nil {
+		return value, err
+	}
+	// Every caller decodes its own copy of the shared result
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+// invalidate drops the cached product and lists an event affects. The
+// payload carries the product "id" and, when known, its "category_id"
+// and "previous_category_id".
+func (c *productCache) invalidate(e events.Event) {
+	cache := app.AppCache
+	if cache == nil {
+		return
+	}
+	payload, _ := e.Payload.(map[string]interface{})
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+
+	var keys []string
+	if id, ok := payload["id"].(int64); ok {
+		keys = append(keys, fmt.Sprintf(productItemKey, id))
+	}
+	categoryID, knownCategory := payload["category_id"].(int64)
+	if knownCategory {
+		keys = append(keys, fmt.Sprintf(productCategoryKey, categoryID))
+	}
+	if previousID, ok := payload["previous_category_id"].(int64); ok && previousID != categoryID {
+		keys = append(keys, fmt.Sprintf(productCategoryKey, previousID))
+	}
+
+	if len(keys) > 0 {
+		cache.Delete(keys...)
+	}
+	cache.DeleteByPrefix(productListPrefix)
+	if !knownCategory {
+		cache.DeleteByPrefix(productCategoryPrefix)
+	}
+	app.Debug("Product cache invalidated", "event_type", e.Type, "keys", keys)
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"goapp/internal/app"
+	"goapp/internal/events"
 	"goapp/internal/models"
 	"goapp/internal/repositories"
 )
@@ -10,23 +12,33 @@ import (
 // ProductService handles business logic for product operations
 type ProductService struct {
 	productRepo repositories.ProductRepository
+	cache       *productCache
 }
 
 // NewProductService creates a new ProductService
 func NewProductService() *ProductService {
 	return &ProductService{
 		productRepo: repositories.NewProductRepository(),
+		cache:       defaultProductCache(),
 	}
 }
 
-// GetProduct retrieves a product by ID
-func (s *ProductService) GetProduct(id int64) (*models.Product, error) {
+// GetProduct retrieves a product by ID, from the cache when possible
+func (s *ProductService) GetProduct(ctx context.Context, id int64) (*models.Product, error) {
 	app.Debug("Getting product", "id", id)
-	return s.productRepo.Find(id)
+	return cachedLoad(ctx, s.cache, fmt.Sprintf(productItemKey, id), func() (*models.Product, error) {
+		return s.productRepo.Find(ctx, id)
+	})
+}
+
+// GetProductForUpdate retrieves a product from the primary database,
+// bypassing the cache and replicas, so a change starts from the current row
+func (s *ProductService) GetProductForUpdate(ctx context.Context, id int64) (*models.Product, error) {
+	return s.productRepo.Find(app.WithPrimaryReads(ctx), id)
 }
 
 // ListProducts retrieves products with pagination
-func (s *ProductService) ListProducts(page, pageSize int) ([]*models.Product, error) {
+func (s *ProductService) ListProducts(ctx context.Context, page, pageSize int) ([]*models.Product, error) {
 	app.Debug("Listing products", "page", page, "page_size", pageSize)
 
 	// Ensure page is positive
@@ -44,17 +56,21 @@ func (s *ProductService) ListProducts(page, pageSize int) ([]*models.Product, er
 	// Calculate offset
 	offset := (page - 1) * pageSize
 
-	return s.productRepo.FindAll(pageSize, offset)
+	return cachedLoad(ctx, s.cache, fmt.Sprintf(productListKey, page, pageSize), func() ([]*models.Product, error) {
+		return s.productRepo.FindAll(ctx, pageSize, offset)
+	})
 }
 
 // ListProductsByCategory retrieves products by category
-func (s *ProductService) ListProductsByCategory(categoryID int64) ([]*models.Product, error) {
+func (s *ProductService) ListProductsByCategory(ctx context.Context, categoryID int64) ([]*models.Product, error) {
 	app.Debug("Listing products by category", "category_id", categoryID)
-	return s.productRepo.FindByCategory(categoryID)
+	return cachedLoad(ctx, s.cache, fmt.Sprintf(productCategoryKey, categoryID), func() ([]*models.Product, error) {
+		return s.productRepo.FindByCategory(ctx, categoryID)
+	})
 }
 
 // CreateProduct creates a new product
-func (s *ProductService) CreateProduct(product *models.Product) error {
+func (s *ProductService) CreateProduct(ctx context.Context, product *models.Product) error {
 	app.Debug("Creating product", "name", product.Name)
 
 	// Validate SKU uniqueness (would typically check against DB)
@@ -65,35 +81,59 @@ func (s *ProductService) CreateProduct(product *models.Product) error {
 	// Set defaults for new product
 	product.IsActive = true
 
-	return s.productRepo.Create(product)
+	if err := s.productRepo.Create(product); err != nil {
+		return err
+	}
+
+	s.changed(events.ProductCreated, map[string]interface{}{
+		"id":          product.ID,
+		"category_id": product.CategoryID,
+	})
+	return nil
 }
 
-// UpdateProduct updates an existing product
-func (s *ProductService) UpdateProduct(product *models.Product) error {
-	app.Debug("Updating product", "id", product.ID)
+// UpdateProduct writes the given columns of an existing product
+func (s *ProductService) UpdateProduct(ctx context.Context, product *models.Product, columns ...string) error {
+	app.Debug("Updating product", "id", product.ID, "columns", columns)
 
 	// Ensure product exists
-	_, err := s.productRepo.Find(product.ID)
+	existing, err := s.GetProductForUpdate(ctx, product.ID)
 	if err != nil {
 		return err
 	}
 
 	// Update the product
-	return s.productRepo.Update(product)
+	if err := s.productRepo.Update(product, columns...); err != nil {
+		return err
+	}
+
+	s.changed(events.ProductUpdated, map[string]interface{}{
+		"id":                   product.ID,
+		"category_id":          product.CategoryID,
+		"previous_category_id": existing.CategoryID,
+	})
+	return nil
 }
 
 // DeleteProduct removes a product by ID
-func (s *ProductService) DeleteProduct(id int64) error {
+func (s *ProductService) DeleteProduct(ctx context.Context, id int64) error {
 	app.Debug("Deleting product", "id", id)
-	return s.productRepo.Delete(id)
+	if err := s.productRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.changed(events.ProductDeleted, map[string]interface{}{
+		"id": id,
+	})
+	return nil
 }
 
 // UpdateProductStock updates only the stock quantity of a product
-func (s *ProductService) UpdateProductStock(id int64, quantity int) error {
+func (s *ProductService) UpdateProductStock(ctx context.Context, id int64, quantity int) error {
 	app.Debug("Updating product stock", "id", id, "quantity", quantity)
 
 	// Ensure product exists
-	product, err := s.productRepo.Find(id)
+	product, err := s.GetProductForUpdate(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -101,5 +141,21 @@ func (s *ProductService) UpdateProductStock(id int64, quantity int) error {
 	// Update stock quantity
 	product.Stock = quantity
 
-	return s.productRepo.Update(product)
+	if err := s.productRepo.Update(product, "stock"); err != nil {
+		return err
+	}
+
+	s.changed(events.StockUpdated, map[string]interface{}{
+		"id":          product.ID,
+		"category_id": product.CategoryID,
+		"stock":       quantity,
+	})
+	return nil
+}
+
+// changed invalidates the cached entries a committed write affects before
+// the write returns, so the next read sees it, then publishes the event
+func (s *ProductService) changed(eventType events.EventType, payload map[string]interface{}) {
+	s.cache.invalidate(events.Event{Type: eventType, Payload: payload})
+	events.Publish(eventType, payload)
 }
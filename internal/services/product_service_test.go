@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"goapp/internal/app"
+	"goapp/internal/events"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// setupProductTest installs a database and an in-memory app.AppCache and
+// returns a product service with one product in category 1. The service's
+// cache is not subscribed to product events, so no handler runs behind the
+// test or outlives it; the product is inserted directly for the same reason.
+func setupProductTest(t *testing.T) (*gorm.DB, *ProductService, *models.Product) {
+	t.Helper()
+	db := setupServiceTest(t, nil)
+	app.AppCache = app.NewMemoryCache(app.GetConfig().Cache)
+	t.Cleanup(func() { app.AppCache = nil })
+
+	service := &ProductService{
+		productRepo: repositories.NewProductRepository(),
+		cache:       &productCache{},
+	}
+	product := &models.Product{Name: "Widget", SKU: "W-1", Price: 9.99, Stock: 5, CategoryID: 1}
+	if err := db.Create(product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	return db, service, product
+}
+
+func TestProductWritesAreVisibleToTheNextRead(t *testing.T) {
+	_, service, product := setupProductTest(t)
+	ctx := context.Background()
+
+	// Fill the cache
+	if _, err := service.GetProduct(ctx, product.ID); err != nil {
+		t.Fatalf("GetProduct: %v", err)
+	}
+	service.ListProducts(ctx, 1, 10)
+	service.ListProductsByCategory(ctx, 1)
+
+	if err := service.UpdateProductStock(ctx, product.ID, 2); err != nil {
+		t.Fatalf("UpdateProductStock: %v", err)
+	}
+	if got, _ := service.GetProduct(ctx, product.ID); got.Stock != 2 {
+		t.Errorf("stock = %d right after UpdateProductStock, want 2", got.Stock)
+	}
+
+	moved := *product
+	moved.CategoryID = 2
+	if err := service.UpdateProduct(ctx, &moved, "category_id"); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	if old, _ := service.ListProductsByCategory(ctx, 1); len(old) != 0 {
+		t.Errorf("category 1 still lists %d products", len(old))
+	}
+	if list, _ := service.ListProducts(ctx, 1, 10); len(list) != 1 || list[0].CategoryID != 2 {
+		t.Errorf("ListProducts = %+v", list)
+	}
+
+	second := &models.Product{Name: "Gadget", SKU: "G-1", Price: 1}
+	if err := service.CreateProduct(ctx, second); err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	if list, _ := service.ListProducts(ctx, 1, 10); len(list) != 2 {
+		t.Errorf("ListProducts returned %d products after CreateProduct, want 2", len(list))
+	}
+
+	if err := service.DeleteProduct(ctx, product.ID); err != nil {
+		t.Fatalf("DeleteProduct: %v", err)
+	}
+	if _, err := service.GetProduct(ctx, product.ID); err == nil {
+		t.Error("GetProduct returned a deleted product")
+	}
+}
+
+func TestUpdateProductKeepsColumnsItDoesNotWrite(t *testing.T) {
+	_, service, product := setupProductTest(t)
+	ctx := context.Background()
+
+	stale, err := service.GetProductForUpdate(ctx, product.ID)
+	if err != nil {
+		t.Fatalf("GetProductForUpdate: %v", err)
+	}
+	if err := service.UpdateProductStock(ctx, product.ID, 0); err != nil {
+		t.Fatalf("UpdateProductStock: %v", err)
+	}
+
+	stale.Name = "Renamed"
+	if err := service.UpdateProduct(ctx, stale, "name"); err != nil {
+		t.Fatalf("UpdateProduct: %v", err)
+	}
+	got, _ := service.GetProductForUpdate(ctx, product.ID)
+	if got.Name != "Renamed" || got.Stock != 0 {
+		t.Errorf("product = %+v, want the new name and the stock of 0", got)
+	}
+}
+
+func TestProductEventsInvalidateWritesMadeElsewhere(t *testing.T) {
+	db, service, product := setupProductTest(t)
+	ctx := context.Background()
+
+	service.GetProduct(ctx, product.ID)
+	db.Model(&models.Product{}).Where("id = ?", product.ID).Update("price", 20)
+	if got, _ := service.GetProduct(ctx, product.ID); got.Price != 9.99 {
+		t.Fatalf("price = %v, want the cached 9.99", got.Price)
+	}
+
+	// What the event subscription runs for a write that bypassed the service
+	service.cache.invalidate(events.Event{
+		Type:    events.ProductUpdated,
+		Payload: map[string]interface{}{"id": product.ID},
+	})
+	if got, _ := service.GetProduct(ctx, product.ID); got.Price != 20 {
+		t.Errorf("price = %v after the event, want 20", got.Price)
+	}
+}
+
+func TestPrimaryReadsBypassTheCache(t *testing.T) {
+	db, service, product := setupProductTest(t)
+	ctx := context.Background()
+
+	service.GetProduct(ctx, product.ID)
+	db.Model(&models.Product{}).Where("id = ?", product.ID).Update("price", 20)
+
+	if got, _ := service.GetProduct(app.WithPrimaryReads(ctx), product.ID); got.Price != 20 {
+		t.Errorf("price = %v with primary reads, want 20", got.Price)
+	}
+	if got, _ := service.GetProductForUpdate(ctx, product.ID); got.Price != 20 {
+		t.Errorf("price = %v from GetProductForUpdate, want 20", got.Price)
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// windowLimiter allows a number of hits per key within a time window
+type windowLimiter struct {
+	limit   func() int
+	window  time.Duration
+	windows map[string]*limiterWindow
+	sweepAt time.Time
+	mutex   sync.Mutex
+}
+
+// limiterWindow tracks the hits of one key in the current window
+type limiterWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// newWindowLimiter creates a limiter allowing limit() hits per window. The
+// limit is read on every hit so configuration reloads apply immediately.
+func newWindowLimiter(limit func() int, window time.Duration) *windowLimiter {
+	return &windowLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*limiterWindow),
+	}
+}
+
+// Allow records a hit for the key and reports whether it is within the limit
+func (l *windowLimiter) Allow(key string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	w, exists := l.windows[key]
+	if !exists || now.After(w.resetAt) {
+		w = &limiterWindow{resetAt: now.Add(l.window)}
+		l.windows[key] = w
+	}
+
+	w.count++
+	return w.count <= l.limit()
+}
+
+// Reset forgets the hits recorded for the key
+func (l *windowLimiter) Reset(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.windows, key)
+}
+
+// sweep drops expired windows so the map does not grow without bound. It
+// runs at most once per window, keeping the cost per hit constant. The
+// caller holds the mutex.
+func (l *windowLimiter) sweep(now time.Time) {
+	if now.Before(l.sweepAt) {
+		return
+	}
+	for k, w := range l.windows {
+		if now.After(w.resetAt) {
+			delete(l.windows, k)
+		}
+	}
+	l.sweepAt = now.Add(l.window)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+	"goapp/utils"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleNameTaken     = errors.New("role name is already taken")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+// RoleService handles business logic for roles and role assignments
+type RoleService struct {
+	roleRepo       repositories.RoleRepository
+	permissionRepo repositories.PermissionRepository
+	userRoleRepo   repositories.UserRoleRepository
+	userRepo       repositories.UserRepository
+}
+
+// NewRoleService creates a new RoleService
+func NewRoleService() *RoleService {
+	return &RoleService{
+		roleRepo:       repositories.NewRoleRepository(),
+		permissionRepo: repositories.NewPermissionRepository(),
+		userRoleRepo:   repositories.NewUserRoleRepository(),
+		userRepo:       repositories.NewUserRepository(),
+	}
+}
+
+// ListRoles retrieves all roles
+func (s *RoleService) ListRoles() ([]*models.Role, error) {
+	return s.roleRepo.FindAll()
+}
+
+// GetRole retrieves a role by ID
+func (s *RoleService) GetRole(id int64) (*models.Role, error) {
+	role, err := s.roleRepo.Find(id)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole creates a role granting the given permissions
+func (s *RoleService) CreateRole(name, description string, permissions []string) (*models.Role, error) {
+	if existing, err := s.roleRepo.FindByName(name); err == nil && existing != nil {
+		return nil, ErrRoleNameTaken
+	}
+
+	resolved, err := s.resolvePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        name,
+		Description: description,
+		Permissions: resolved,
+	}
+	if err := s.roleRepo.Create(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole changes a role's description and, when given, its permissions
+func (s *RoleService) UpdateRole(id int64, description *string, permissions []string) (*models.Role, error) {
+	role, err := s.roleRepo.Find(id)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	if description != nil {
+		role.Description = *description
+	}
+	if permissions != nil {
+		resolved, err := s.resolvePermissions(permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = resolved
+	}
+
+	if err := s.roleRepo.Update(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole removes a role and unassigns it from all users
+func (s *RoleService) DeleteRole(id int64) error {
+	return s.roleRepo.Delete(id)
+}
+
+// ListPermissions returns every permission known to the application
+func (s *RoleService) ListPermissions() []string {
+	return models.AllPermissions
+}
+
+// GetUserRoles retrieves the roles assigned to a user
+func (s *RoleService) GetUserRoles(userID int64) ([]*models.Role, error) {
+	if _, err := s.userRepo.Find(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	return s.userRoleRepo.FindRolesByUser(userID)
+}
+
+// AssignRole grants a role to a user
+func (s *RoleService) AssignRole(userID, roleID int64) error {
+	if _, err := s.userRepo.Find(userID); err != nil {
+		return ErrUserNotFound
+	}
+	if _, err := s.roleRepo.Find(roleID); err != nil {
+		return ErrRoleNotFound
+	}
+	return s.userRoleRepo.Assign(userID, roleID)
+}
+
+// RevokeRole removes a role from a user
+func (s *RoleService) RevokeRole(userID, roleID int64) error {
+	return s.userRoleRepo.Revoke(userID, roleID)
+}
+
+// resolvePermissions validates permission names and loads their records
+func (s *RoleService) resolvePermissions(names []string) ([]*models.Permission, error) {
+	names = utils.RemoveDuplicate(names)
+	for _, name := range names {
+		if !utils.InArray(name, models.AllPermissions) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, name)
+		}
+	}
+	return s.permissionRepo.FindOrCreateByNames(names)
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+
+	"goapp/internal/app"
+	"goapp/internal/events"
+	"goapp/internal/models"
+	"goapp/internal/repositories"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// SessionService handles listing and revoking user sessions
+type SessionService struct {
+	sessionRepo repositories.SessionRepository
+	tokenRepo   repositories.RefreshTokenRepository
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo: repositories.NewSessionRepository(),
+		tokenRepo:   repositories.NewRefreshTokenRepository(),
+	}
+}
+
+// ListSessions returns the user's active sessions, most recently used first
+func (s *SessionService) ListSessions(userID int64) ([]*models.Session, error) {
+	return s.sessionRepo.FindActiveByUser(userID)
+}
+
+// RevokeSession signs one of the user's sessions out
+func (s *SessionService) RevokeSession(userID int64, sessionID string) error {
+	session, err := s.sessionRepo.Find(sessionID)
+	if err != nil || session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	if err := s.sessionRepo.Revoke(session.ID); err != nil {
+		return err
+	}
+	if err := s.tokenRepo.RevokeFamily(session.ID); err != nil {
+		return err
+	}
+
+	events.Publish(events.UserLoggedOut, map[string]interface{}{
+		"user_id":    userID,
+		"session_id": session.ID,
+	})
+	return nil
+}
+
+// RevokeAllSessions signs a user out everywhere and returns how many sessions were ended
+func (s *SessionService) RevokeAllSessions(userID int64) (int64, error) {
+	count, err := s.sessionRepo.RevokeByUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.tokenRepo.RevokeByUser(userID); err != nil {
+		return count, err
+	}
+
+	app.Info("All sessions revoked", "user_id", userID, "sessions", count)
+	events.Publish(events.UserLoggedOut, map[string]interface{}{
+		"user_id":  userID,
+		"sessions": count,
+	})
+	return count, nil
+}
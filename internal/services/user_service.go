@@ -1,11 +1,14 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 
+	"goapp/internal/app"
+	"goapp/internal/events"
 	"goapp/internal/models"
 	"goapp/internal/repositories"
 
@@ -13,22 +16,35 @@ import (
 )
 
 var (
-	ErrInvalidEmail    = errors.New("invalid email format")
-	ErrInvalidPassword = errors.New("password must be at least 8 characters long")
-	ErrUserNotFound    = errors.New("user not found")
-	ErrEmailTaken      = errors.New("email is already taken")
-	ErrUsernameTaken   = errors.New("username is already taken")
+	ErrInvalidEmail      = errors.New("invalid email format")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrEmailTaken        = errors.New("email is already taken")
+	ErrUsernameTaken     = errors.New("username is already taken")
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	ErrPasswordReused    = errors.New("password was used recently")
+	ErrEmailNotVerified  = errors.New("email address is not verified")
 )
 
+// dummyPasswordHash is compared against when a login does not exist
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
 // UserService handles business logic for user operations
 type UserService struct {
-	repo repositories.UserRepository
+	repo        repositories.UserRepository
+	historyRepo repositories.PasswordHistoryRepository
+	tokenRepo   repositories.RefreshTokenRepository
+	sessionRepo repositories.SessionRepository
+	guard       *LoginGuard
 }
 
 // NewUserService creates a new UserService
 func NewUserService() *UserService {
 	return &UserService{
-		repo: repositories.NewUserRepository(),
+		repo:        repositories.NewUserRepository(),
+		historyRepo: repositories.NewPasswordHistoryRepository(),
+		tokenRepo:   repositories.NewRefreshTokenRepository(),
+		sessionRepo: repositories.NewSessionRepository(),
+		guard:       NewLoginGuard(),
 	}
 }
 
@@ -39,9 +55,9 @@ func (s *UserService) CreateUser(user *models.User) error {
 		return ErrInvalidEmail
 	}
 
-	// Validate password length
-	if len(user.Password) < 8 {
-		return ErrInvalidPassword
+	// Validate password strength
+	if err := ValidatePassword(user.Password); err != nil {
+		return err
 	}
 
 	// Check if email is already taken
@@ -66,6 +82,8 @@ func (s *UserService) CreateUser(user *models.User) error {
 	// Set default values
 	user.IsActive = true
 	user.IsAdmin = false
+	user.EmailVerified = false
+	user.VerifiedAt = nil
 
 	// Create user
 	return s.repo.Create(user)
@@ -90,6 +108,10 @@ func (s *UserService) UpdateUser(user *models.User) error {
 		if err == nil && otherUser != nil && otherUser.ID != user.ID {
 			return ErrEmailTaken
 		}
+
+		// A changed address has to be verified again
+		user.EmailVerified = false
+		user.VerifiedAt = nil
 	}
 
 	// Check if new username is taken by another user
@@ -105,8 +127,8 @@ func (s *UserService) UpdateUser(user *models.User) error {
 }
 
 // GetUser retrieves a user by ID
-func (s *UserService) GetUser(id int64) (*models.User, error) {
-	user, err := s.repo.Find(id)
+func (s *UserService) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	user, err := s.repo.Lookup(ctx, id)
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
@@ -114,7 +136,7 @@ func (s *UserService) GetUser(id int64) (*models.User, error) {
 }
 
 // ListUsers retrieves a paginated list of users
-func (s *UserService) ListUsers(page, pageSize int) ([]*models.User, error) {
+func (s *UserService) ListUsers(ctx context.Context, page, pageSize int) ([]*models.User, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -123,7 +145,7 @@ func (s *UserService) ListUsers(page, pageSize int) ([]*models.User, error) {
 	}
 
 	offset := (page - 1) * pageSize
-	return s.repo.FindAll(pageSize, offset)
+	return s.repo.FindAll(ctx, pageSize, offset)
 }
 
 // DeleteUser deletes a user by ID
@@ -131,8 +153,13 @@ func (s *UserService) DeleteUser(id int64) error {
 	return s.repo.Delete(id)
 }
 
-// AuthenticateUser authenticates a user with email/username and password
-func (s *UserService) AuthenticateUser(login, password string) (*models.User, error) {
+// AuthenticateUser authenticates a user with email/username and password.
+// Unknown logins and wrong passwords both return ErrInvalidCredentials.
+func (s *UserService) AuthenticateUser(login, password, clientIP string) (*models.User, error) {
+	if err := s.guard.Check(login, clientIP); err != nil {
+		return nil, err
+	}
+
 	var user *models.User
 	var err error
 
@@ -144,18 +171,115 @@ func (s *UserService) AuthenticateUser(login, password string) (*models.User, er
 	}
 
 	if err != nil {
-		return nil, ErrUserNotFound
+		// Spend the same time as a real check so response time does not reveal the account
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		s.guard.RecordFailure(login, clientIP)
+		return nil, ErrInvalidCredentials
 	}
 
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return nil, errors.New("invalid password")
+		s.guard.RecordFailure(login, clientIP)
+		return nil, ErrInvalidCredentials
 	}
 
+	// Deactivated accounts get the same answer, and count, as a wrong password
+	if !user.IsActive {
+		s.guard.RecordFailure(login, clientIP)
+		return nil, ErrInvalidCredentials
+	}
+
+	// The password was right, so the failures stand until a login succeeds
+	if app.GetConfig().EmailVerification.Required && !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	s.guard.RecordSuccess(login)
 	return user, nil
 }
 
+// ChangePassword verifies a user's current password and replaces it
+func (s *UserService) ChangePassword(userID int64, currentPassword, newPassword string) error {
+	user, err := s.repo.Find(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return ErrIncorrectPassword
+	}
+
+	return s.setPassword(user, newPassword)
+}
+
+// setPassword validates and stores a new password, then signs the user out everywhere
+func (s *UserService) setPassword(user *models.User, newPassword string) error {
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+	if err := s.checkPasswordReuse(user, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	previousHash := user.Password
+	user.Password = string(hashedPassword)
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+
+	if err := s.historyRepo.Create(&models.PasswordHistory{
+		UserID:       user.ID,
+		PasswordHash: previousHash,
+	}); err != nil {
+		app.Error("Failed to record password history", "error", err, "user_id", user.ID)
+	}
+
+	// Existing sessions were started with the old password
+	if _, err := s.sessionRepo.RevokeByUser(user.ID); err != nil {
+		app.Error("Failed to revoke sessions after password change", "error", err, "user_id", user.ID)
+	}
+	if err := s.tokenRepo.RevokeByUser(user.ID); err != nil {
+		app.Error("Failed to revoke tokens after password change", "error", err, "user_id", user.ID)
+	}
+
+	events.Publish(events.UserPasswordChanged, map[string]interface{}{
+		"user_id": user.ID,
+	})
+	return nil
+}
+
+// checkPasswordReuse rejects the current password and the configured number of previous ones
+func (s *UserService) checkPasswordReuse(user *models.User, newPassword string) error {
+	historySize := app.GetConfig().Password.HistorySize
+	if historySize <= 0 {
+		return nil
+	}
+
+	hashes := []string{user.Password}
+	if historySize > 1 {
+		entries, err := s.historyRepo.FindRecentByUser(user.ID, historySize-1)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			hashes = append(hashes, entry.PasswordHash)
+		}
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
 // Helper function to validate email format
 func isValidEmail(email string) bool {
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
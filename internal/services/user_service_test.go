@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"goapp/internal/app"
+	"goapp/internal/testutil"
+)
+
+func TestValidatePassword(t *testing.T) {
+	setupServiceTest(t, func(cfg *app.Config) {
+		cfg.Password = app.PasswordPolicyConfig{
+			MinLength:     10,
+			RequireUpper:  true,
+			RequireLower:  true,
+			RequireDigit:  true,
+			RequireSymbol: true,
+		}
+	})
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"satisfies every rule", "Correct-Horse1", false},
+		{"counts characters, not bytes", "Ünïcödé-Pa55", false},
+		{"too short", "Sh0rt-Pw", true},
+		{"no uppercase", "correct-horse1", true},
+		{"no lowercase", "CORRECT-HORSE1", true},
+		{"no digit", "Correct-Horse", true},
+		{"no symbol", "CorrectHorse1", true},
+		{"empty", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePassword(tt.password)
+			if tt.wantErr != errors.Is(err, ErrWeakPassword) {
+				t.Errorf("ValidatePassword(%q) = %v", tt.password, err)
+			}
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		next    string
+		wantErr error
+	}{
+		{"changes the password", "Password1", "Password2", nil},
+		{"wrong current password", "Password0", "Password2", ErrIncorrectPassword},
+		{"weak new password", "Password1", "password", ErrWeakPassword},
+		{"current password again", "Password1", "Password1", ErrPasswordReused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTest(t, nil)
+			user := testutil.CreateUser(t, db, "alice", "Password1")
+			service := NewUserService()
+
+			err := service.ChangePassword(user.ID, tt.current, tt.next)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword = %v, want %v", err, tt.wantErr)
+			}
+
+			want := "Password1"
+			if tt.wantErr == nil {
+				want = tt.next
+			}
+			if err := service.ChangePassword(user.ID, want, "Another-Pass9"); err != nil {
+				t.Errorf("password is not %q afterwards: %v", want, err)
+			}
+		})
+	}
+}
+
+func TestChangePasswordRejectsRecentPasswords(t *testing.T) {
+	db := setupServiceTest(t, func(cfg *app.Config) {
+		cfg.Password.HistorySize = 3
+	})
+	user := testutil.CreateUser(t, db, "alice", "Password0")
+	service := NewUserService()
+
+	passwords := []string{"Password0", "Password1", "Password2"}
+	for i := 1; i < len(passwords); i++ {
+		if err := service.ChangePassword(user.ID, passwords[i-1], passwords[i]); err != nil {
+			t.Fatalf("ChangePassword to %s: %v", passwords[i], err)
+		}
+	}
+	for _, reused := range passwords {
+		if err := service.ChangePassword(user.ID, "Password2", reused); !errors.Is(err, ErrPasswordReused) {
+			t.Errorf("reusing %s: %v, want ErrPasswordReused", reused, err)
+		}
+	}
+
+	if err := service.ChangePassword(user.ID, "Password2", "Password3"); err != nil {
+		t.Fatalf("ChangePassword to Password3: %v", err)
+	}
+	if err := service.ChangePassword(user.ID, "Password3", "Password0"); err != nil {
+		t.Errorf("password older than the history was rejected: %v", err)
+	}
+}
+
+func TestChangePasswordEndsSessions(t *testing.T) {
+	db := setupServiceTest(t, nil)
+	user := testutil.CreateUser(t, db, "alice", "Password1")
+	auth := NewAuthService()
+
+	tokens, err := auth.IssueTokens(user, "test-agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("IssueTokens: %v", err)
+	}
+	if err := NewUserService().ChangePassword(user.ID, "Password1", "Password2"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if _, err := auth.Refresh(tokens.RefreshToken, "10.0.0.1"); err == nil {
+		t.Error("refresh token survived the password change")
+	}
+}
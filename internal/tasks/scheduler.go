@@ -0,0 +1,62 @@
+package tasks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"goapp/internal/app"
+)
+
+// StartScheduler runs the tasks in tasks.schedule at their intervals until
+// ctx is done. Only the instance elected leader runs them, so replicas do
+// not all fire at once. It does nothing without a schedule.
+func StartScheduler(ctx context.Context) {
+	schedule := app.GetConfig().Tasks.Schedule
+	if len(schedule) == 0 {
+		return
+	}
+	for name := range schedule {
+		if _, exists := tasks[name]; !exists {
+			app.Warn("Scheduled task not found", "task", name)
+		}
+	}
+
+	election := app.NewLeaderElection(app.Locks, "scheduler", app.GetConfig().Lock.TTL.Duration(), app.LeaderCallbacks{
+		OnStartedLeading: func(ctx context.Context) {
+			runSchedule(ctx, schedule)
+		},
+		OnStoppedLeading: func() {
+			app.Info("Scheduled tasks stopped on this instance")
+		},
+	})
+	go election.Run(ctx)
+}
+
+// runSchedule runs each scheduled task at its interval until ctx is done
+func runSchedule(ctx context.Context, schedule map[string]app.Duration) {
+	var wg sync.WaitGroup
+	for name, interval := range schedule {
+		if _, exists := tasks[name]; !exists {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, interval time.Duration) {
+			defer wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					RunTask(name)
+				}
+			}
+		}(name, interval.Duration())
+	}
+
+	app.Info("Running scheduled tasks on this instance")
+	wg.Wait()
+}
@@ -35,12 +35,27 @@ func RunTask(taskName string) {
 		return
 	}
 
+	// Make sure no other instance runs the task at the same time
+	lock := app.NewLock(app.Locks, "task:"+taskName, app.GetConfig().Lock.TTL.Duration())
+	acquired, err := lock.TryLock()
+	if err != nil {
+		app.Error("Task lock failed", "task", taskName, "error", err)
+		fmt.Printf("Task '%s' could not be locked: %v\n", taskName, err)
+		return
+	}
+	if !acquired {
+		app.Info("Task already running elsewhere", "task", taskName)
+		fmt.Printf("Task '%s' is already running on another instance\n", taskName)
+		return
+	}
+	defer lock.Unlock()
+
 	// Execute task
-	app.Info("Running task", "task", taskName)
+	app.Info("Running task", "task", taskName, "fencing_token", lock.Token())
 	fmt.Printf("Running task: %s\n", taskName)
 
 	startTime := time.Now()
-	err := taskFunc()
+	err = taskFunc()
 	duration := time.Since(startTime)
 
 	if err != nil {
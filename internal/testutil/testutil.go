@@ -0,0 +1,130 @@
+// Package testutil prepares the application globals for tests. It is only
+// imported from _test.go files, so none of it ends up in the binary.
+package testutil
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"goapp/internal/app"
+	"goapp/internal/migrations"
+	"goapp/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var loggerOnce sync.Once
+
+// Config returns the default configuration with an in-memory SQLite
+// database and the log discarded
+func Config() app.Config {
+	cfg := app.DefaultConfig()
+	cfg.Server.Mode = "test"
+	cfg.Log.Output = "file"
+	cfg.Log.Filename = os.DevNull
+	cfg.Log.Level = "debug"
+	cfg.Database.Driver = "sqlite"
+	cfg.Database.Path = ":memory:"
+	cfg.Database.ConnectRetries = 0
+	cfg.JWT.Secret = "test-secret"
+	return cfg
+}
+
+// SetupDB puts cfg in effect and installs an in-memory SQLite database
+// migrated to the latest version as app.DB. The database is closed, and
+// its data dropped, when the test ends.
+func SetupDB(t testing.TB, cfg app.Config) *gorm.DB {
+	t.Helper()
+
+	app.SetConfig(&cfg)
+	loggerOnce.Do(app.InitLogger)
+
+	db, err := app.OpenDatabase(cfg.Database)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if _, err := migrations.NewMigrator(db).Up(); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+
+	app.DB = db
+	t.Cleanup(func() {
+		app.DB = nil
+		app.CloseDatabase(db)
+	})
+	return db
+}
+
+// SetupJWT installs a token manager for cfg as app.JWT until the test ends
+func SetupJWT(t testing.TB, cfg app.Config) *app.TokenManager {
+	t.Helper()
+
+	manager, err := app.NewTokenManager(cfg.JWT)
+	if err != nil {
+		t.Fatalf("create token manager: %v", err)
+	}
+	previous := app.JWT
+	app.JWT = manager
+	t.Cleanup(func() { app.JWT = previous })
+	return manager
+}
+
+// CreateUser inserts an active, verified user with the given password,
+// hashed at the lowest bcrypt cost to keep tests fast
+func CreateUser(t testing.TB, db *gorm.DB, username, password string) *models.User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{
+		Username:      username,
+		Email:         username + "@example.com",
+		Password:      string(hash),
+		IsActive:      true,
+		EmailVerified: true,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// Outbox is a Notifier that keeps what it is sent for the test to read
+type Outbox struct {
+	messages chan *app.Notification
+}
+
+// Send queues the notification for Next
+func (o *Outbox) Send(notification *app.Notification) error {
+	o.messages <- notification
+	return nil
+}
+
+// Next waits for the next notification, failing the test if none arrives.
+// Services often send in the background, after the call under test returns.
+func (o *Outbox) Next(t testing.TB) *app.Notification {
+	t.Helper()
+	select {
+	case notification := <-o.messages:
+		return notification
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification was sent")
+		return nil
+	}
+}
+
+// SetupMailer installs an Outbox as app.Mailer until the test ends
+func SetupMailer(t testing.TB) *Outbox {
+	t.Helper()
+
+	outbox := &Outbox{messages: make(chan *app.Notification, 16)}
+	previous := app.Mailer
+	app.Mailer = outbox
+	t.Cleanup(func() { app.Mailer = previous })
+	return outbox
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"goapp/internal/app"
+	"goapp/internal/commands"
 	"goapp/internal/events"
+	"goapp/internal/migrations"
 	"goapp/internal/router"
 	"goapp/internal/services"
 	"goapp/internal/tasks"
@@ -12,15 +17,50 @@ import (
 	"syscall"
 )
 
-const (
-	defaultPort = 8080
-)
-
 func main() {
+	// Manage the encrypted secrets file
+	if len(os.Args) > 1 && os.Args[1] == "secrets" {
+		err := commands.RunSecrets(os.Args[2:])
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			fmt.Printf("Secrets command failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Load configuration: defaults, config file, environment, flags
+	args, err := app.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Print the effective configuration
+	if len(args) > 0 && args[0] == "config" {
+		if err := app.PrintConfig(); err != nil {
+			fmt.Printf("Failed to print configuration: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize core components
 	app.InitLogger()
 	fmt.Println("Logger initialized successfully")
 
+	// Apply or inspect schema migrations
+	if len(args) > 0 && args[0] == "migrate" {
+		err := commands.RunMigrate(args[1:])
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			fmt.Printf("Migrate command failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize event system
 	events.InitEventBus()
 	fmt.Println("Event system initialized successfully")
@@ -30,23 +70,47 @@ func main() {
 		app.InitDB()
 	})
 
+	// Apply pending migrations when enabled
+	if app.GetConfig().Database.AutoMigrate && app.GetDB() != nil {
+		if _, err := migrations.NewMigrator(app.GetDB()).Up(); err != nil {
+			fmt.Printf("Failed to apply migrations: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Migrations applied successfully")
+	}
+
 	// Try to initialize Redis (but continue if it fails)
 	tryInitialize("Redis", func() {
 		app.InitRedis()
 	})
 
+	// Initialize the application cache, in memory if Redis is unavailable
+	app.InitCache()
+	fmt.Println("Cache initialized successfully")
+
+	// Initialize distributed locks, in memory if Redis is unavailable
+	app.InitLocker()
+	fmt.Println("Locks initialized successfully")
+
+	// Initialize access token signing
+	app.InitJWT()
+	fmt.Println("JWT initialized successfully")
+
+	// Initialize outgoing notifications
+	app.InitNotifier()
+	fmt.Println("Notifier initialized successfully")
+
 	// Initialize validator
 	app.InitValidator()
 	fmt.Println("Validator initialized successfully")
 
 	// Handle command-line tasks
-	args := os.Args
-	if len(args) > 1 && args[1] == "task" {
-		if len(args) < 3 {
+	if len(args) > 0 && args[0] == "task" {
+		if len(args) < 2 {
 			fmt.Println("Please specify a task name, e.g.: go run main.go task cleanup")
 			return
 		}
-		taskName := args[2]
+		taskName := args[1]
 		tasks.RunTask(taskName)
 		return
 	}
@@ -56,17 +120,24 @@ func main() {
 
 	// Emit system start event
 	events.Publish(events.SystemStarted, map[string]interface{}{
-		"port": defaultPort,
-		"mode": app.ConfigData.Server.Mode,
+		"port": app.GetConfig().Server.Port,
+		"mode": app.GetConfig().Server.Mode,
 	})
 
 	// Setup graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// Reload configuration on SIGHUP and config file changes
+	stopWatching := app.WatchConfig()
+
+	// Run scheduled tasks while this instance is the elected leader
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	tasks.StartScheduler(schedulerCtx)
+
 	// Start the web server
 	r := router.SetupRouter()
-	port := defaultPort
+	port := app.GetConfig().Server.Port
 
 	// Start server in a goroutine
 	go func() {
@@ -79,12 +150,19 @@ func main() {
 	// Wait for shutdown signal
 	<-quit
 	fmt.Println("\n🛑 Shutting down server...")
+	stopWatching()
+	stopScheduler()
 
 	// Emit system shutdown event
 	events.Publish(events.SystemShutdown, map[string]interface{}{
-		"time": fmt.Sprintf("%v", app.ConfigData.Server.Mode),
+		"time": fmt.Sprintf("%v", app.GetConfig().Server.Mode),
 	})
 
+	// Close database connections and stop the replica health checks
+	if err := app.CloseDB(); err != nil {
+		app.Error("Failed to close database", "error", err)
+	}
+
 	// Print final stats
 	stats := monitor.GetStats()
 	fmt.Printf("📊 Final Stats:\n")